@@ -1,21 +1,47 @@
 package app
 
 import (
+	"context"
+	"sync"
+	"time"
+
 	"github.com/sirupsen/logrus"
+	googlegrpc "google.golang.org/grpc"
 
 	config "gw-currency-wallet/internal/config"
 	"gw-currency-wallet/internal/delivery/rest"
+	"gw-currency-wallet/internal/delivery/rpc"
+	"gw-currency-wallet/internal/health"
 	"gw-currency-wallet/internal/infrastructure/grpc"
+	"gw-currency-wallet/internal/infrastructure/kafka"
+	"gw-currency-wallet/internal/infrastructure/mail"
+	"gw-currency-wallet/internal/outbox"
 	"gw-currency-wallet/internal/server"
 	"gw-currency-wallet/internal/service"
 	"gw-currency-wallet/internal/storage"
 	"gw-currency-wallet/internal/storage/models/validate"
+	"gw-currency-wallet/internal/stream"
+	"gw-currency-wallet/internal/tracing"
 	"gw-currency-wallet/internal/utils"
+	"gw-currency-wallet/internal/webhook"
 	"gw-currency-wallet/pkg/db"
 	"gw-currency-wallet/pkg/redis_client"
 )
 
 func StartApplication(cfg *config.Config, logger *logrus.Logger) error {
+	// Трассировка настраивается до подключений, чтобы их инструментирование взяло нужный TracerProvider
+	shutdownTracing, err := tracing.Setup(context.Background(), &cfg.Tracing)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := shutdownTracing(ctx); err != nil {
+			logger.Errorf("❌ Failed to flush traces: %v", err)
+		}
+	}()
+
 	// Подключение к базе данных
 	dbConn, err := db.ConnectPostgres(cfg.Database.Dsn, logger)
 	if err != nil {
@@ -29,14 +55,79 @@ func StartApplication(cfg *config.Config, logger *logrus.Logger) error {
 		panic(err)
 	}
 
-	validator := validate.NewValidator()                                    // Общий валидатор входных данных
-	exClient := grpc.NewUserServiceClient(cfg.ExchangeService.Addr, logger) // grpc клиент для связи с gw-exchanger
-	jwtManager := utils.NewJWTManager(cfg)                                  // Генерация и парсинг JWT
+	validator := validate.NewValidator()                                // Общий валидатор входных данных
+	exClient := grpc.NewUserServiceClient(&cfg.ExchangeService, logger) // grpc клиент для связи с gw-exchanger
+	jwtManager, err := utils.NewJWTManager(cfg)                         // Генерация и парсинг JWT
+	if err != nil {
+		return err
+	}
+	mailer, err := mail.NewMailer(&cfg.Mail, logger) // Письма подтверждения email и сброса пароля
+	if err != nil {
+		return err
+	}
 	repo := storage.NewStorage(dbConn, logger)
-	services := service.NewService(repo, logger, jwtManager, exClient, cache)
-	handlers := rest.NewHandler(services, logger, &cfg.Auth, validator)
+	services := service.NewService(repo, logger, jwtManager, exClient, cache, &cfg.Exchange, &cfg.Auth, &cfg.Webhooks, mailer)
+	hub := stream.NewHub(cache, services.WalletService, services.ExchangeService, &cfg.Stream, logger)
+	handlers := rest.NewHandler(services, logger, &cfg.Auth, validator, hub, &cfg.Stream)
+
+	// Фоновые обработчики останавливаются вместе с сервером
+	ctx, cancel := context.WithCancel(context.Background())
+	var workers sync.WaitGroup
+	defer func() {
+		cancel()
+		workers.Wait()
+	}()
+
+	// Изменения баланса и курсов от всех реплик для потока /stream
+	workers.Add(1)
+	go func() {
+		defer workers.Done()
+		hub.Run(ctx)
+	}()
+
+	// Публикация событий из outbox в Kafka
+	if cfg.Outbox.Enabled {
+		producer, err := kafka.NewSyncProducer(&cfg.Outbox)
+		if err != nil {
+			return err
+		}
+
+		relay := outbox.NewRelay(repo, kafka.NewPublisher(producer, cfg.Outbox.Topic), &cfg.Outbox, logger)
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			defer producer.Close()
+			relay.Run(ctx)
+		}()
+	}
+
+	// Доставка webhooks подписчикам
+	if cfg.Webhooks.Enabled {
+		dispatcher := webhook.NewDispatcher(repo, &cfg.Webhooks, logger)
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			dispatcher.Run(ctx)
+		}()
+	}
+
+	// Проверки зависимостей для /readyz
+	checker := health.NewChecker(cfg.Server.HealthTimeout)
+	checker.Add("postgres", dbConn.Ping)
+	checker.Add("redis", func(ctx context.Context) error { return cache.Ping(ctx).Err() })
+	checker.Add("exchanger", exClient.Ping)
+	checker.AddInfo("exchanger_breaker", exClient.BreakerState)
+
+	// gRPC API кошелька для внутренних сервисов
+	var grpcServer *googlegrpc.Server
+	if cfg.WalletGRPC.Enabled {
+		if grpcServer, err = rpc.NewServer(&cfg.WalletGRPC, services, validator, cache, logger); err != nil {
+			return err
+		}
+	}
 
 	// Настройка и запуск сервера
-	server.SetupAndRunServer(&cfg.Server, handlers.InitRoutes(logger, jwtManager, validator), logger)
+	router := handlers.InitRoutes(logger, jwtManager, services, validator, cache, cfg, checker)
+	server.SetupAndRunServer(&cfg.Server, router, grpcServer, cfg.WalletGRPC.Addr, checker, logger, hub.Close)
 	return nil
 }
@@ -3,6 +3,7 @@ package configs
 import (
 	"fmt"
 	"log"
+	"net"
 	"strings"
 	"time"
 
@@ -17,6 +18,9 @@ type ServerConfig struct {
 	ReadTimeout    time.Duration `mapstructure:"read_timeout"`
 	WriteTimeout   time.Duration `mapstructure:"write_timeout"`
 	MaxHeaderBytes int           `mapstructure:"max_header_bytes"`
+	DrainDelay     time.Duration `mapstructure:"drain_delay"`     // Сколько /readyz отвечает 503 до остановки приёма запросов
+	HealthTimeout  time.Duration `mapstructure:"health_timeout"`  // Таймаут проверок зависимостей в /readyz
+	TrustedProxies []string      `mapstructure:"trusted_proxies"` // Прокси, чьим X-Forwarded-For можно верить; пусто — IP берётся из соединения
 }
 
 // LoggerConfig Конфигурация логирования
@@ -34,10 +38,71 @@ type PostgresConfig struct {
 	MigratePath string `mapstructure:"migrate_path"`
 }
 
-// AuthConfig Конфигурация Auth
+// SigningKey Ключ подписи JWT (RSA или Ed25519 в PEM). Алгоритм определяется по типу ключа.
+// Ключ без private_key_file используется только для проверки ранее выданных токенов
+type SigningKey struct {
+	KID            string `mapstructure:"kid"`
+	PrivateKeyFile string `mapstructure:"private_key_file"`
+	PublicKeyFile  string `mapstructure:"public_key_file"`
+}
+
+// AuthConfig Конфигурация Auth. Если signing_keys не заданы, токены подписываются HS256 на secret_key
 type AuthConfig struct {
-	SecretKey string        `mapstructure:"secret_key"`
-	TokenTTl  time.Duration `mapstructure:"token_ttl"`
+	SecretKey       string        `mapstructure:"secret_key"`
+	TokenTTl        time.Duration `mapstructure:"token_ttl"`
+	RefreshTokenTTL time.Duration `mapstructure:"refresh_token_ttl"`
+	Issuer          string        `mapstructure:"issuer"`
+	Audience        string        `mapstructure:"audience"`
+	ActiveKID       string        `mapstructure:"active_kid"`
+	SigningKeys     []SigningKey  `mapstructure:"signing_keys"`
+	AllowHS256      bool          `mapstructure:"allow_hs256"` // Без signing_keys подписывать HS256 на secret_key — только для разработки
+	Lockout         LockoutConfig `mapstructure:"lockout"`
+	MFA             MFAConfig     `mapstructure:"mfa"`
+	Email           EmailConfig   `mapstructure:"email"`
+}
+
+// EmailConfig Подтверждение email и сброс пароля по ссылкам из писем
+type EmailConfig struct {
+	VerificationTTL time.Duration `mapstructure:"verification_ttl"` // Сколько действует ссылка подтверждения email
+	ResetTTL        time.Duration `mapstructure:"reset_ttl"`        // Сколько действует ссылка сброса пароля
+	LinkBaseURL     string        `mapstructure:"link_base_url"`    // Адрес фронтенда для ссылок /verify-email и /reset-password; пусто — в письме только токен
+}
+
+// MFAConfig Двухфакторная аутентификация по TOTP
+type MFAConfig struct {
+	Issuer             string             `mapstructure:"issuer"`              // Название сервиса в приложении-аутентификаторе
+	EncryptionKey      string             `mapstructure:"encryption_key"`      // Ключ шифрования секретов TOTP в базе; пусто — secret_key
+	ChallengeTTL       time.Duration      `mapstructure:"challenge_ttl"`       // Сколько действует mfa_token между шагами входа
+	MaxAttempts        int                `mapstructure:"max_attempts"`        // Неверных кодов на один mfa_token
+	RecoveryCodes      int                `mapstructure:"recovery_codes"`      // Сколько кодов восстановления выдавать
+	WithdrawThresholds map[string]float64 `mapstructure:"withdraw_thresholds"` // Валюта -> сумма, с которой вывод требует код 2FA
+}
+
+// LockoutConfig Защита входа от перебора паролей. MaxFailures 0 — аккаунты не блокируются, IPMaxFailures 0 — IP не блокируются
+type LockoutConfig struct {
+	MaxFailures   int           `mapstructure:"max_failures"`    // Неудачных входов в аккаунт подряд до блокировки
+	FailureWindow time.Duration `mapstructure:"failure_window"`  // Счётчик сбрасывается, если столько не было неудачных входов
+	BaseDuration  time.Duration `mapstructure:"base_duration"`   // Первая блокировка, каждая следующая подряд вдвое дольше
+	MaxDuration   time.Duration `mapstructure:"max_duration"`    // Предел длительности блокировки
+	IPMaxFailures int           `mapstructure:"ip_max_failures"` // Неудачных входов с одного IP за ip_window до блокировки IP
+	IPWindow      time.Duration `mapstructure:"ip_window"`
+}
+
+// MailConfig Отправка писем. Driver smtp — через SMTP-сервер, log — письма пишутся в лог и, если задан dir, в файлы .eml
+type MailConfig struct {
+	Driver  string        `mapstructure:"driver"`
+	From    string        `mapstructure:"from"`
+	Timeout time.Duration `mapstructure:"timeout"`
+	Dir     string        `mapstructure:"dir"`
+	SMTP    SMTPConfig    `mapstructure:"smtp"`
+}
+
+// SMTPConfig SMTP-сервер. На порту 465 TLS включается сразу, на остальных — через STARTTLS, если сервер его поддерживает
+type SMTPConfig struct {
+	Host     string `mapstructure:"host"`
+	Port     int    `mapstructure:"port"`
+	Username string `mapstructure:"username"` // Пусто — без авторизации
+	Password string `mapstructure:"password"`
 }
 
 type RedisConfig struct {
@@ -46,19 +111,155 @@ type RedisConfig struct {
 	DB       int    `mapstructure:"db"`
 }
 
-// ExchangeService адрес grpc микросервиса gw-exchanger
+// IdempotencyConfig Настройки хранения ответов по Idempotency-Key
+type IdempotencyConfig struct {
+	TTL time.Duration `mapstructure:"ttl"`
+}
+
+// ExchangeService адрес grpc микросервиса gw-exchanger и устойчивость вызовов к нему
 type ExchangeService struct {
-	Addr string `mapstructure:"addr"`
+	Addr             string            `mapstructure:"addr"`
+	Timeout          time.Duration     `mapstructure:"timeout"`           // Дедлайн одной попытки вызова
+	MaxRetries       int               `mapstructure:"max_retries"`       // Повторы при Unavailable, ResourceExhausted и DeadlineExceeded
+	RetryBackoff     time.Duration     `mapstructure:"retry_backoff"`     // Пауза перед первым повтором, дальше удваивается
+	RetryMaxBackoff  time.Duration     `mapstructure:"retry_max_backoff"` // Максимальная пауза между повторами
+	BreakerThreshold int               `mapstructure:"breaker_threshold"` // Подряд неудачных вызовов до размыкания цепи
+	BreakerCooldown  time.Duration     `mapstructure:"breaker_cooldown"`  // Сколько вызовы отклоняются сразу после размыкания
+	TLS              ExchangeTLSConfig `mapstructure:"tls"`
+}
+
+// ExchangeTLSConfig TLS до gw-exchanger. С CertFile и KeyFile — взаимный TLS.
+// Файлы перечитываются при изменении, перезапуск не нужен
+type ExchangeTLSConfig struct {
+	Enabled    bool   `mapstructure:"enabled"`
+	CAFile     string `mapstructure:"ca_file"`     // CA для проверки сертификата gw-exchanger; пусто — системные CA
+	CertFile   string `mapstructure:"cert_file"`   // Клиентский сертификат для mTLS
+	KeyFile    string `mapstructure:"key_file"`    // Ключ клиентского сертификата
+	ServerName string `mapstructure:"server_name"` // Имя в сертификате сервера, если отличается от адреса
+}
+
+// FeeRule Правило комиссии за обмен. FixedFee и MinFee указываются в валюте зачисления
+type FeeRule struct {
+	SpreadPercent float64 `mapstructure:"spread_percent"`
+	FixedFee      float64 `mapstructure:"fixed_fee"`
+	MinFee        float64 `mapstructure:"min_fee"`
+}
+
+// FeeSchedule Правило по умолчанию и переопределения для валютных пар (ключ вида usd_rub)
+type FeeSchedule struct {
+	FeeRule `mapstructure:",squash"`
+	Pairs   map[string]FeeRule `mapstructure:"pairs"`
+}
+
+// FeeConfig Комиссии за обмен: общее расписание и расписания для уровней пользователей
+type FeeConfig struct {
+	FeeSchedule `mapstructure:",squash"`
+	Tiers       map[string]FeeSchedule `mapstructure:"tiers"`
+}
+
+// ExchangeConfig Настройки обмена валют
+type ExchangeConfig struct {
+	QuoteTTL       time.Duration `mapstructure:"quote_ttl"`
+	RatesTTL       time.Duration `mapstructure:"rates_ttl"`       // Курсы свежее этого срока отдаются из кэша без обращения к gw-exchanger
+	RatesRetention time.Duration `mapstructure:"rates_retention"` // Сколько последние полученные курсы хранятся на случай недоступности gw-exchanger
+	MaxRateAge     time.Duration `mapstructure:"max_rate_age"`    // Обмен по курсу старше этого срока отклоняется
+	Fees           FeeConfig     `mapstructure:"fees"`
+}
+
+// OutboxConfig Публикация доменных событий из outbox в Kafka.
+// События пишутся в outbox всегда, enabled включает только их отправку
+type OutboxConfig struct {
+	Enabled      bool          `mapstructure:"enabled"`
+	Brokers      []string      `mapstructure:"brokers"`
+	Topic        string        `mapstructure:"topic"`
+	PollInterval time.Duration `mapstructure:"poll_interval"`
+	BatchSize    int           `mapstructure:"batch_size"`
+	MaxRetries   int           `mapstructure:"max_retries"`
+	MaxBackoff   time.Duration `mapstructure:"max_backoff"`
+}
+
+// WebhookConfig Доставка webhooks: повторы с экспоненциальной паузой, после max_attempts доставка переходит в dead.
+// Адреса внутренней сети запрещены, пока не включён AllowPrivateNetworks
+type WebhookConfig struct {
+	Enabled              bool          `mapstructure:"enabled"`
+	Timeout              time.Duration `mapstructure:"timeout"`
+	PollInterval         time.Duration `mapstructure:"poll_interval"`
+	BatchSize            int           `mapstructure:"batch_size"`
+	MaxAttempts          int           `mapstructure:"max_attempts"`
+	BaseBackoff          time.Duration `mapstructure:"base_backoff"`
+	MaxBackoff           time.Duration `mapstructure:"max_backoff"`
+	AllowPrivateNetworks bool          `mapstructure:"allow_private_networks"`
+}
+
+// TracingConfig Трассировка OpenTelemetry. Exporter: otlp (gRPC на endpoint), stdout, file или пусто — трассировка выключена
+type TracingConfig struct {
+	Exporter    string  `mapstructure:"exporter"`
+	Endpoint    string  `mapstructure:"endpoint"`
+	Insecure    bool    `mapstructure:"insecure"`
+	OutputFile  string  `mapstructure:"output_file"`
+	ServiceName string  `mapstructure:"service_name"`
+	SampleRatio float64 `mapstructure:"sample_ratio"`
+}
+
+// WalletGRPCConfig gRPC API кошелька для внутренних сервисов. Без cert_file соединение не шифруется
+type WalletGRPCConfig struct {
+	Enabled        bool               `mapstructure:"enabled"`
+	Addr           string             `mapstructure:"addr"`
+	CertFile       string             `mapstructure:"cert_file"`
+	KeyFile        string             `mapstructure:"key_file"`
+	ClientCAFile   string             `mapstructure:"client_ca_file"` // CA клиентских сертификатов, включает mTLS
+	Clients        []GRPCClientConfig `mapstructure:"clients"`
+	IdempotencyTTL time.Duration      `mapstructure:"idempotency_ttl"`
+}
+
+// GRPCClientConfig Учётные данные сервиса-клиента. В конфиге хранится SHA-256 токена в hex,
+// сам токен клиент передаёт в метаданных authorization: Bearer <token>
+type GRPCClientConfig struct {
+	Name      string   `mapstructure:"name"`
+	TokenHash string   `mapstructure:"token_hash"`
+	Methods   []string `mapstructure:"methods"` // Разрешённые методы (GetBalance, Deposit...); "*" — все, пусто — ни одного
+}
+
+// StreamConfig поток событий /stream: изменения баланса и курсов рассылаются между репликами через Redis pub/sub
+type StreamConfig struct {
+	HeartbeatInterval time.Duration `mapstructure:"heartbeat_interval"` // Период heartbeat, чтобы прокси не закрывали простаивающее соединение
+	RatesInterval     time.Duration `mapstructure:"rates_interval"`     // Как часто обновлять курсы, пока есть подключённые клиенты
+	BufferSize        int           `mapstructure:"buffer_size"`        // Событий в очереди клиента; отстающий клиент отключается
+}
+
+// RateLimitConfig ограничения частоты запросов по группам маршрутов.
+// /auth/* и /users/me ограничиваются по IP клиента, остальные группы — по user_id. Limit 0 — группа не ограничивается
+type RateLimitConfig struct {
+	Auth     RateLimitRule `mapstructure:"auth"`
+	Wallet   RateLimitRule `mapstructure:"wallet"`
+	Exchange RateLimitRule `mapstructure:"exchange"`
+	Webhooks RateLimitRule `mapstructure:"webhooks"`
+	Admin    RateLimitRule `mapstructure:"admin"`
+}
+
+// RateLimitRule не больше Limit запросов за любые Window подряд (скользящее окно)
+type RateLimitRule struct {
+	Limit  int           `mapstructure:"limit"`
+	Window time.Duration `mapstructure:"window"`
 }
 
 // Config Полная конфигурация
 type Config struct {
-	Server          ServerConfig    `mapstructure:"server"`
-	Logging         LoggerConfig    `mapstructure:"logging"`
-	Database        PostgresConfig  `mapstructure:"database"`
-	Auth            AuthConfig      `mapstructure:"auth"`
-	Redis           RedisConfig     `mapstructure:"redis"`
-	ExchangeService ExchangeService `mapstructure:"exchange_service_grpc"`
+	Server          ServerConfig      `mapstructure:"server"`
+	Logging         LoggerConfig      `mapstructure:"logging"`
+	Database        PostgresConfig    `mapstructure:"database"`
+	Auth            AuthConfig        `mapstructure:"auth"`
+	Redis           RedisConfig       `mapstructure:"redis"`
+	ExchangeService ExchangeService   `mapstructure:"exchange_service_grpc"`
+	Idempotency     IdempotencyConfig `mapstructure:"idempotency"`
+	Exchange        ExchangeConfig    `mapstructure:"exchange"`
+	Outbox          OutboxConfig      `mapstructure:"outbox"`
+	Webhooks        WebhookConfig     `mapstructure:"webhooks"`
+	Tracing         TracingConfig     `mapstructure:"tracing"`
+	WalletGRPC      WalletGRPCConfig  `mapstructure:"wallet_grpc"`
+	Stream          StreamConfig      `mapstructure:"stream"`
+	RateLimit       RateLimitConfig   `mapstructure:"rate_limit"`
+	Mail            MailConfig        `mapstructure:"mail"`
 }
 
 // LoadConfig загружает конфигурацию из файлов и переменных окружения
@@ -96,6 +297,224 @@ func LoadConfig(path string) (*Config, error) {
 	if config.Server.WriteTimeout <= 0 {
 		config.Server.WriteTimeout = 10 * time.Second
 	}
+	if config.Server.DrainDelay < 0 {
+		config.Server.DrainDelay = 0
+	}
+	if config.Server.HealthTimeout <= 0 {
+		config.Server.HealthTimeout = 2 * time.Second
+	}
+	for _, proxy := range config.Server.TrustedProxies {
+		if net.ParseIP(proxy) == nil {
+			if _, _, err := net.ParseCIDR(proxy); err != nil {
+				return nil, fmt.Errorf("server.trusted_proxies: invalid IP or CIDR %q", proxy)
+			}
+		}
+	}
+	if config.Auth.TokenTTl <= 0 {
+		config.Auth.TokenTTl = 15 * time.Minute
+	}
+	if config.Auth.RefreshTokenTTL <= 0 {
+		config.Auth.RefreshTokenTTL = 30 * 24 * time.Hour
+	}
+	if len(config.Auth.SigningKeys) > 0 && config.Auth.ActiveKID == "" {
+		return nil, fmt.Errorf("auth.active_kid is required when auth.signing_keys are set")
+	}
+	if config.Auth.MFA.Issuer == "" {
+		config.Auth.MFA.Issuer = "gw-currency-wallet"
+	}
+	if config.Auth.MFA.EncryptionKey == "" {
+		config.Auth.MFA.EncryptionKey = config.Auth.SecretKey
+	}
+	if config.Auth.MFA.EncryptionKey == "" {
+		return nil, fmt.Errorf("auth.mfa.encryption_key is required when auth.secret_key is empty")
+	}
+	if config.Auth.MFA.ChallengeTTL <= 0 {
+		config.Auth.MFA.ChallengeTTL = 5 * time.Minute
+	}
+	if config.Auth.MFA.MaxAttempts <= 0 {
+		config.Auth.MFA.MaxAttempts = 5
+	}
+	if config.Auth.MFA.RecoveryCodes <= 0 {
+		config.Auth.MFA.RecoveryCodes = 10
+	}
+	// Ключи map viper приводит к нижнему регистру, коды валют храним в верхнем
+	thresholds := make(map[string]float64, len(config.Auth.MFA.WithdrawThresholds))
+	for currency, amount := range config.Auth.MFA.WithdrawThresholds {
+		thresholds[strings.ToUpper(currency)] = amount
+	}
+	config.Auth.MFA.WithdrawThresholds = thresholds
+	if config.Auth.Email.VerificationTTL <= 0 {
+		config.Auth.Email.VerificationTTL = 24 * time.Hour
+	}
+	if config.Auth.Email.ResetTTL <= 0 {
+		config.Auth.Email.ResetTTL = time.Hour
+	}
+	config.Auth.Email.LinkBaseURL = strings.TrimRight(config.Auth.Email.LinkBaseURL, "/")
+	if config.Auth.Lockout.MaxFailures < 0 || config.Auth.Lockout.IPMaxFailures < 0 {
+		return nil, fmt.Errorf("auth.lockout: max_failures and ip_max_failures must not be negative")
+	}
+	if config.Auth.Lockout.FailureWindow <= 0 {
+		config.Auth.Lockout.FailureWindow = 15 * time.Minute
+	}
+	if config.Auth.Lockout.BaseDuration <= 0 {
+		config.Auth.Lockout.BaseDuration = time.Minute
+	}
+	if config.Auth.Lockout.MaxDuration <= 0 {
+		config.Auth.Lockout.MaxDuration = time.Hour
+	}
+	if config.Auth.Lockout.MaxDuration < config.Auth.Lockout.BaseDuration {
+		config.Auth.Lockout.MaxDuration = config.Auth.Lockout.BaseDuration
+	}
+	if config.Auth.Lockout.IPWindow <= 0 {
+		config.Auth.Lockout.IPWindow = 15 * time.Minute
+	}
+	if config.Idempotency.TTL <= 0 {
+		config.Idempotency.TTL = 24 * time.Hour
+	}
+	if (config.ExchangeService.TLS.CertFile == "") != (config.ExchangeService.TLS.KeyFile == "") {
+		return nil, fmt.Errorf("exchange_service_grpc.tls: cert_file and key_file must be set together")
+	}
+	if config.ExchangeService.Timeout <= 0 {
+		config.ExchangeService.Timeout = 2 * time.Second
+	}
+	if config.ExchangeService.MaxRetries < 0 {
+		config.ExchangeService.MaxRetries = 0
+	}
+	if config.ExchangeService.RetryBackoff <= 0 {
+		config.ExchangeService.RetryBackoff = 100 * time.Millisecond
+	}
+	if config.ExchangeService.RetryMaxBackoff < config.ExchangeService.RetryBackoff {
+		config.ExchangeService.RetryMaxBackoff = time.Second
+	}
+	if config.ExchangeService.BreakerThreshold <= 0 {
+		config.ExchangeService.BreakerThreshold = 5
+	}
+	if config.ExchangeService.BreakerCooldown <= 0 {
+		config.ExchangeService.BreakerCooldown = 30 * time.Second
+	}
+	if config.Exchange.QuoteTTL <= 0 {
+		config.Exchange.QuoteTTL = 30 * time.Second
+	}
+	if config.Exchange.RatesTTL <= 0 {
+		config.Exchange.RatesTTL = 5 * time.Minute
+	}
+	if config.Exchange.RatesRetention < config.Exchange.RatesTTL {
+		config.Exchange.RatesRetention = 24 * time.Hour
+	}
+	if config.Exchange.MaxRateAge <= 0 {
+		config.Exchange.MaxRateAge = 15 * time.Minute
+	}
+	if config.Outbox.Enabled && (len(config.Outbox.Brokers) == 0 || config.Outbox.Topic == "") {
+		return nil, fmt.Errorf("outbox.brokers and outbox.topic are required when outbox is enabled")
+	}
+	if config.Outbox.PollInterval <= 0 {
+		config.Outbox.PollInterval = time.Second
+	}
+	if config.Outbox.BatchSize <= 0 {
+		config.Outbox.BatchSize = 100
+	}
+	if config.Outbox.MaxRetries <= 0 {
+		config.Outbox.MaxRetries = 5
+	}
+	if config.Outbox.MaxBackoff <= 0 {
+		config.Outbox.MaxBackoff = 30 * time.Second
+	}
+	if config.Webhooks.Timeout <= 0 {
+		config.Webhooks.Timeout = 5 * time.Second
+	}
+	if config.Webhooks.PollInterval <= 0 {
+		config.Webhooks.PollInterval = time.Second
+	}
+	if config.Webhooks.BatchSize <= 0 {
+		config.Webhooks.BatchSize = 50
+	}
+	if config.Webhooks.MaxAttempts <= 0 {
+		config.Webhooks.MaxAttempts = 8
+	}
+	if config.Webhooks.BaseBackoff <= 0 {
+		config.Webhooks.BaseBackoff = 10 * time.Second
+	}
+	if config.Webhooks.MaxBackoff <= 0 {
+		config.Webhooks.MaxBackoff = time.Hour
+	}
+	switch config.Tracing.Exporter {
+	case "", "otlp", "stdout":
+	case "file":
+		if config.Tracing.OutputFile == "" {
+			return nil, fmt.Errorf("tracing.output_file is required for file exporter")
+		}
+	default:
+		return nil, fmt.Errorf("unknown tracing exporter: %s", config.Tracing.Exporter)
+	}
+	if config.Tracing.ServiceName == "" {
+		config.Tracing.ServiceName = "gw-currency-wallet"
+	}
+	if config.Tracing.SampleRatio <= 0 || config.Tracing.SampleRatio > 1 {
+		config.Tracing.SampleRatio = 1
+	}
+	if config.WalletGRPC.Enabled {
+		if config.WalletGRPC.Addr == "" {
+			config.WalletGRPC.Addr = ":9090"
+		}
+		if (config.WalletGRPC.CertFile == "") != (config.WalletGRPC.KeyFile == "") {
+			return nil, fmt.Errorf("wallet_grpc: cert_file and key_file must be set together")
+		}
+		if config.WalletGRPC.ClientCAFile != "" && config.WalletGRPC.CertFile == "" {
+			return nil, fmt.Errorf("wallet_grpc: client_ca_file requires cert_file and key_file")
+		}
+		if len(config.WalletGRPC.Clients) == 0 {
+			return nil, fmt.Errorf("wallet_grpc: at least one client is required")
+		}
+		for _, client := range config.WalletGRPC.Clients {
+			if client.Name == "" || len(client.TokenHash) != 64 {
+				return nil, fmt.Errorf("wallet_grpc: client %q must have a name and a hex SHA-256 token_hash", client.Name)
+			}
+		}
+		if config.WalletGRPC.IdempotencyTTL <= 0 {
+			config.WalletGRPC.IdempotencyTTL = config.Idempotency.TTL
+		}
+	}
+	if config.Stream.HeartbeatInterval <= 0 {
+		config.Stream.HeartbeatInterval = 15 * time.Second
+	}
+	if config.Stream.RatesInterval <= 0 {
+		config.Stream.RatesInterval = config.Exchange.RatesTTL
+	}
+	if config.Stream.BufferSize <= 0 {
+		config.Stream.BufferSize = 16
+	}
+	switch config.Mail.Driver {
+	case "":
+		config.Mail.Driver = "log"
+	case "log":
+	case "smtp":
+		if config.Mail.SMTP.Host == "" {
+			return nil, fmt.Errorf("mail.smtp.host is required for smtp driver")
+		}
+		if config.Mail.SMTP.Port <= 0 {
+			config.Mail.SMTP.Port = 587
+		}
+	default:
+		return nil, fmt.Errorf("unknown mail driver: %s", config.Mail.Driver)
+	}
+	if config.Mail.From == "" {
+		config.Mail.From = "no-reply@gw-currency-wallet.local"
+	}
+	if config.Mail.Timeout <= 0 {
+		config.Mail.Timeout = 10 * time.Second
+	}
+	rateLimits := map[string]RateLimitRule{
+		"auth":     config.RateLimit.Auth,
+		"wallet":   config.RateLimit.Wallet,
+		"exchange": config.RateLimit.Exchange,
+		"webhooks": config.RateLimit.Webhooks,
+		"admin":    config.RateLimit.Admin,
+	}
+	for group, rule := range rateLimits {
+		if rule.Limit > 0 && rule.Window <= 0 {
+			return nil, fmt.Errorf("rate_limit.%s: window is required when limit is set", group)
+		}
+	}
 
 	return &config, nil
 }
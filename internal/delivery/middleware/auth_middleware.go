@@ -1,6 +1,7 @@
 package middleware
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"strings"
@@ -8,11 +9,18 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 
+	"gw-currency-wallet/internal/errs"
+	"gw-currency-wallet/internal/storage/models"
 	"gw-currency-wallet/internal/utils"
 )
 
-// AuthMiddleware проверяет JWT токен
-func AuthMiddleware(jwtManager *utils.JWTManager) gin.HandlerFunc {
+// TokenRevocationChecker проверяет, отозван ли access-токен до истечения срока
+type TokenRevocationChecker interface {
+	IsTokenRevoked(c context.Context, claims *models.Claims) (bool, error)
+}
+
+// AuthMiddleware проверяет JWT токен, его отсутствие в denylist и что он выпущен после отзыва всех сессий пользователя
+func AuthMiddleware(jwtManager *utils.JWTManager, revocation TokenRevocationChecker) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
@@ -37,8 +45,39 @@ func AuthMiddleware(jwtManager *utils.JWTManager) gin.HandlerFunc {
 			return
 		}
 
-		// Сохраняем user_id в контексте запроса
+		// Токены без jti нельзя отозвать, поэтому не принимаем их
+		if claims.ID == "" {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "token has no id"})
+			c.Abort()
+			return
+		}
+
+		revoked, err := revocation.IsTokenRevoked(c, claims)
+		if err != nil {
+			c.Error(err)
+			c.Abort()
+			return
+		}
+		if revoked {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": errs.ErrTokenRevoked.Error()})
+			c.Abort()
+			return
+		}
+
+		// Сохраняем user_id и claims в контексте запроса
 		c.Set("user_id", claims.UserID)
+		c.Set("claims", claims)
+		c.Next()
+	}
+}
+
+// TokenFromQuery переносит токен из параметра запроса в заголовок Authorization для AuthMiddleware.
+// Нужен клиентам, которые не умеют передавать заголовки, например EventSource в браузере
+func TokenFromQuery(param string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if token := c.Query(param); token != "" && c.GetHeader("Authorization") == "" {
+			c.Request.Header.Set("Authorization", "Bearer "+token)
+		}
 		c.Next()
 	}
 }
@@ -59,3 +98,12 @@ func GetUserUUID(c *gin.Context) (uuid.UUID, error) {
 
 	return userUUID, nil
 }
+
+// GetClaims отдает claims токена, сохранённые AuthMiddleware
+func GetClaims(c *gin.Context) (*models.Claims, error) {
+	claims, exists := c.Get("claims")
+	if !exists {
+		return nil, fmt.Errorf("claims are missing in context")
+	}
+	return claims.(*models.Claims), nil
+}
@@ -4,7 +4,9 @@ import (
 	"errors"
 	"net/http"
 	"runtime/debug"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/sirupsen/logrus"
@@ -46,9 +48,81 @@ func ErrorHandler(logger *logrus.Logger) gin.HandlerFunc {
 			case errors.Is(err, errs.ErrUserNotFound) || errors.Is(err, errs.ErrInvalidPassword):
 				statusCode = http.StatusUnauthorized
 				message = errs.ErrInvalidCredentials.Error()
+			case errors.Is(err, errs.ErrWrongPassword):
+				statusCode = http.StatusBadRequest
+				message = "Current password is incorrect"
+				fieldErrors = map[string]string{"current_password": "is incorrect"}
+			case errors.Is(err, errs.ErrLoginLocked):
+				statusCode = http.StatusTooManyRequests
+				message = "Too many failed login attempts, try again later"
+				var locked *errs.LoginLockedError
+				if errors.As(err, &locked) {
+					c.Header(RetryAfterHeader, strconv.Itoa(ceilSeconds(time.Until(locked.Until))))
+				}
+			case errors.Is(err, errs.ErrMFALocked):
+				statusCode = http.StatusTooManyRequests
+				message = "Too many invalid two-factor codes, try again later"
+				var locked *errs.MFALockedError
+				if errors.As(err, &locked) {
+					c.Header(RetryAfterHeader, strconv.Itoa(ceilSeconds(time.Until(locked.Until))))
+				}
+			case errors.Is(err, errs.ErrMFARequired):
+				statusCode = http.StatusForbidden
+				message = "Two-factor code required"
+			case errors.Is(err, errs.ErrInvalidMFACode):
+				statusCode = http.StatusUnauthorized
+				message = "Invalid two-factor code"
+			case errors.Is(err, errs.ErrInvalidMFAChallenge):
+				statusCode = http.StatusUnauthorized
+				message = "Invalid or expired MFA challenge"
+			case errors.Is(err, errs.ErrMFAAlreadyEnabled):
+				statusCode = http.StatusConflict
+				message = "Two-factor authentication is already enabled"
+			case errors.Is(err, errs.ErrMFANotEnrolled):
+				statusCode = http.StatusConflict
+				message = "Two-factor authentication is not enrolled"
+			case errors.Is(err, errs.ErrInvalidEmailToken):
+				statusCode = http.StatusBadRequest
+				message = "Invalid or expired token"
+			case errors.Is(err, errs.ErrEmailNotVerified):
+				statusCode = http.StatusForbidden
+				message = "Email is not verified"
+			case errors.Is(err, errs.ErrEmailAlreadyVerified):
+				statusCode = http.StatusConflict
+				message = "Email is already verified"
+			case errors.Is(err, errs.ErrInvalidRefresh):
+				statusCode = http.StatusUnauthorized
+				message = "Invalid or expired refresh token"
+			case errors.Is(err, errs.ErrForbidden):
+				statusCode = http.StatusForbidden
+				message = "Insufficient permissions"
+			case errors.Is(err, errs.ErrSelfAdjustment):
+				statusCode = http.StatusForbidden
+				message = "Cannot adjust your own balance"
+			case errors.Is(err, errs.ErrAccountFrozen):
+				statusCode = http.StatusForbidden
+				message = "Account is frozen"
+			case errors.Is(err, errs.ErrRecipientFrozen):
+				statusCode = http.StatusConflict
+				message = "Recipient account is frozen"
+			case errors.Is(err, errs.ErrAccountNotFound):
+				statusCode = http.StatusNotFound
+				message = "Account not found"
 			case errors.Is(err, errs.ErrWalletNotFound):
 				statusCode = http.StatusNotFound
 				message = "Wallet not found"
+			case errors.Is(err, errs.ErrSelfTransfer):
+				statusCode = http.StatusBadRequest
+				message = "Cannot transfer to yourself"
+			case errors.Is(err, errs.ErrRecipientNotFound):
+				statusCode = http.StatusNotFound
+				message = "Recipient not found"
+			case errors.Is(err, errs.ErrInvalidCursor):
+				statusCode = http.StatusBadRequest
+				message = "Invalid pagination cursor"
+			case errors.Is(err, errs.ErrInvalidDateRange):
+				statusCode = http.StatusBadRequest
+				message = "Invalid date range, 'from' must be before 'to'"
 			case errors.Is(err, errs.ErrInsufficientFunds):
 				statusCode = http.StatusBadRequest
 				message = "Insufficient funds"
@@ -61,12 +135,55 @@ func ErrorHandler(logger *logrus.Logger) gin.HandlerFunc {
 			case errors.Is(err, errs.ErrUnsupportedCurrency):
 				statusCode = http.StatusBadRequest
 				message = "Unsupported currency"
+			case errors.Is(err, errs.ErrQuoteExpired):
+				statusCode = http.StatusGone
+				message = "Exchange quote expired or not found"
+			case errors.Is(err, errs.ErrQuoteAlreadyUsed):
+				statusCode = http.StatusConflict
+				message = "Exchange quote already used"
+			case errors.Is(err, errs.ErrQuoteMismatch):
+				statusCode = http.StatusBadRequest
+				message = "Exchange request does not match quote"
+			case errors.Is(err, errs.ErrAmountTooSmall):
+				statusCode = http.StatusBadRequest
+				message = "Amount is too small to cover exchange fee"
+			case errors.Is(err, errs.ErrRateTooOld):
+				statusCode = http.StatusServiceUnavailable
+				message = "Exchange rates are temporarily unavailable"
+			case errors.Is(err, errs.ErrInvalidWebhookId):
+				statusCode = http.StatusBadRequest
+				message = "Invalid webhook ID"
+			case errors.Is(err, errs.ErrWebhookNotFound):
+				statusCode = http.StatusNotFound
+				message = "Webhook not found"
+			case errors.Is(err, errs.ErrWebhookURLNotAllowed):
+				statusCode = http.StatusBadRequest
+				message = "Webhook URL must resolve to a public address"
+			case errors.Is(err, errs.ErrWebhookDeliveryNotFound):
+				statusCode = http.StatusNotFound
+				message = "Webhook delivery not found"
+			case errors.Is(err, errs.ErrInvalidIdempotencyKey):
+				statusCode = http.StatusBadRequest
+				message = "Invalid Idempotency-Key header"
+			case errors.Is(err, errs.ErrIdempotencyKeyReused):
+				statusCode = http.StatusUnprocessableEntity
+				message = "Idempotency-Key was already used with a different request"
+			case errors.Is(err, errs.ErrIdempotencyRequestInProgress):
+				statusCode = http.StatusConflict
+				message = "Request with this Idempotency-Key is still in progress"
+			case errors.Is(err, errs.ErrTooManyRequests):
+				statusCode = http.StatusTooManyRequests
+				message = "Too many requests, retry later"
 			case isGRPCError(err):
 				// Проверяем, если ошибка gRPC имеет код NotFound
 				st, ok := status.FromError(err)
 				if ok && st.Code() == codes.NotFound {
 					statusCode = http.StatusNotFound
 					message = st.Message() // Используем описание из gRPC ошибки
+				} else if ok && (st.Code() == codes.Unavailable || st.Code() == codes.DeadlineExceeded) {
+					// gw-exchanger недоступен или разомкнут circuit breaker
+					statusCode = http.StatusServiceUnavailable
+					message = "Exchange service is unavailable"
 				} else {
 					// Для других типов ошибок, связанных с gRPC
 					statusCode = http.StatusBadRequest
@@ -75,7 +192,7 @@ func ErrorHandler(logger *logrus.Logger) gin.HandlerFunc {
 			default:
 				statusCode = http.StatusInternalServerError
 				message = "Internal server error"
-				logger.WithFields(logrus.Fields{
+				logger.WithContext(c.Request.Context()).WithFields(logrus.Fields{
 					"method":      c.Request.Method + " " + c.Request.URL.Path,
 					"error":       err.Error(),
 					"stack_trace": string(debug.Stack()),
@@ -0,0 +1,153 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+
+	"gw-currency-wallet/internal/errs"
+)
+
+const (
+	IdempotencyKeyHeader = "Idempotency-Key"
+
+	idempotencyMaxKeyLength = 255
+	// Пока запрос выполняется, ключ блокируется на короткое время,
+	// чтобы упавший посреди обработки инстанс не держал его весь TTL
+	idempotencyLockTTL = time.Minute
+)
+
+// idempotencyRecord сохранённый в Redis результат запроса. Status == 0 — запрос ещё выполняется
+type idempotencyRecord struct {
+	Fingerprint string `json:"fingerprint"`
+	Status      int    `json:"status"`
+	ContentType string `json:"content_type,omitempty"`
+	Body        []byte `json:"body,omitempty"`
+}
+
+// responseRecorder дублирует тело ответа в буфер, чтобы сохранить его для повторов
+type responseRecorder struct {
+	gin.ResponseWriter
+	body *bytes.Buffer
+}
+
+func (r *responseRecorder) Write(b []byte) (int, error) {
+	r.body.Write(b)
+	return r.ResponseWriter.Write(b)
+}
+
+func (r *responseRecorder) WriteString(s string) (int, error) {
+	r.body.WriteString(s)
+	return r.ResponseWriter.WriteString(s)
+}
+
+// IdempotencyMiddleware обрабатывает заголовок Idempotency-Key: повтор запроса с тем же ключом
+// получает сохранённый ответ, а повтор с другим телом — 422
+func IdempotencyMiddleware(cache *redis.Client, ttl time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(IdempotencyKeyHeader)
+		if key == "" {
+			c.Next()
+			return
+		}
+		if len(key) > idempotencyMaxKeyLength {
+			c.Error(errs.ErrInvalidIdempotencyKey)
+			c.Abort()
+			return
+		}
+
+		// Читаем тело и возвращаем его обратно для следующих middleware
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			c.Error(err)
+			c.Abort()
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		// Результат сохраняем даже если клиент уже отключился
+		ctx := context.WithoutCancel(c.Request.Context())
+		cacheKey := "idempotency:" + c.GetString("user_id") + ":" + key
+		fingerprint := requestFingerprint(c.Request.Method, c.Request.URL.Path, body)
+
+		pending, _ := json.Marshal(idempotencyRecord{Fingerprint: fingerprint})
+		acquired, err := cache.SetNX(ctx, cacheKey, pending, idempotencyLockTTL).Result()
+		if err != nil {
+			c.Error(err)
+			c.Abort()
+			return
+		}
+
+		if !acquired {
+			replayIdempotentResponse(c, cache, cacheKey, fingerprint)
+			return
+		}
+
+		recorder := &responseRecorder{ResponseWriter: c.Writer, body: &bytes.Buffer{}}
+		c.Writer = recorder
+		c.Next()
+
+		// Ошибки не кэшируем: операция не выполнена, клиент может повторить запрос с тем же ключом
+		if len(c.Errors) > 0 || recorder.Status() >= http.StatusInternalServerError {
+			cache.Del(ctx, cacheKey)
+			return
+		}
+
+		record, _ := json.Marshal(idempotencyRecord{
+			Fingerprint: fingerprint,
+			Status:      recorder.Status(),
+			ContentType: recorder.Header().Get("Content-Type"),
+			Body:        recorder.body.Bytes(),
+		})
+		cache.Set(ctx, cacheKey, record, ttl)
+	}
+}
+
+// replayIdempotentResponse отдаёт сохранённый ответ для уже использованного ключа
+func replayIdempotentResponse(c *gin.Context, cache *redis.Client, cacheKey, fingerprint string) {
+	defer c.Abort()
+
+	data, err := cache.Get(c, cacheKey).Bytes()
+	if errors.Is(err, redis.Nil) {
+		// Ключ истёк между SETNX и GET — первый запрос ещё не завершён
+		c.Error(errs.ErrIdempotencyRequestInProgress)
+		return
+	}
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	var record idempotencyRecord
+	if err := json.Unmarshal(data, &record); err != nil {
+		c.Error(err)
+		return
+	}
+
+	switch {
+	case record.Fingerprint != fingerprint:
+		c.Error(errs.ErrIdempotencyKeyReused)
+	case record.Status == 0:
+		c.Error(errs.ErrIdempotencyRequestInProgress)
+	default:
+		c.Header("Idempotent-Replayed", "true")
+		c.Data(record.Status, record.ContentType, record.Body)
+	}
+}
+
+// requestFingerprint считает отпечаток запроса по методу, пути и телу
+func requestFingerprint(method, path string, body []byte) string {
+	hash := sha256.New()
+	hash.Write([]byte(method + " " + path + "\n"))
+	hash.Write(body)
+	return hex.EncodeToString(hash.Sum(nil))
+}
@@ -0,0 +1,114 @@
+package middleware
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+
+	config "gw-currency-wallet/internal/config"
+	"gw-currency-wallet/internal/errs"
+	"gw-currency-wallet/internal/metrics"
+)
+
+// Заголовки ограничения частоты (draft-ietf-httpapi-ratelimit-headers)
+const (
+	RateLimitLimitHeader     = "RateLimit-Limit"
+	RateLimitRemainingHeader = "RateLimit-Remaining"
+	RateLimitResetHeader     = "RateLimit-Reset"
+	RetryAfterHeader         = "Retry-After"
+)
+
+// slidingWindowScript атомарно убирает из окна старые запросы и, если лимит не исчерпан, учитывает текущий.
+// Возвращает: 1/0 — разрешён ли запрос, число запросов в окне, время самого старого запроса в окне (мс)
+var slidingWindowScript = redis.NewScript(`
+local now = tonumber(ARGV[1])
+local window = tonumber(ARGV[2])
+local limit = tonumber(ARGV[3])
+
+redis.call('ZREMRANGEBYSCORE', KEYS[1], '-inf', now - window)
+local count = redis.call('ZCARD', KEYS[1])
+local allowed = 0
+if count < limit then
+	redis.call('ZADD', KEYS[1], now, ARGV[4])
+	count = count + 1
+	allowed = 1
+end
+redis.call('PEXPIRE', KEYS[1], window)
+
+local oldest = redis.call('ZRANGE', KEYS[1], 0, 0, 'WITHSCORES')
+return {allowed, count, tonumber(oldest[2])}
+`)
+
+// RateLimitKey выбирает, по чему считать запросы. Пустой ключ — запрос не ограничивается
+type RateLimitKey func(c *gin.Context) string
+
+// RateLimitByIP ключ по IP клиента; за прокси нужен server.trusted_proxies
+func RateLimitByIP(c *gin.Context) string {
+	return "ip:" + c.ClientIP()
+}
+
+// RateLimitByUser ключ по user_id, который сохраняет AuthMiddleware
+func RateLimitByUser(c *gin.Context) string {
+	if userID := c.GetString("user_id"); userID != "" {
+		return "user:" + userID
+	}
+	return ""
+}
+
+// RateLimitMiddleware пропускает не больше rule.Limit запросов за скользящее окно rule.Window
+// на ключ в группе маршрутов, остальным отвечает 429 с Retry-After.
+// При недоступности Redis запросы пропускаются, чтобы лимиты не останавливали сервис
+func RateLimitMiddleware(cache *redis.Client, logger *logrus.Logger, group string, rule config.RateLimitRule, key RateLimitKey) gin.HandlerFunc {
+	if rule.Limit <= 0 {
+		return func(c *gin.Context) { c.Next() }
+	}
+	window := rule.Window.Milliseconds()
+
+	return func(c *gin.Context) {
+		id := key(c)
+		if id == "" {
+			c.Next()
+			return
+		}
+
+		now := time.Now().UnixMilli()
+		result, err := slidingWindowScript.Run(c, cache,
+			[]string{"ratelimit:" + group + ":" + id},
+			now, window, rule.Limit, strconv.FormatInt(now, 10)+"-"+uuid.NewString(),
+		).Int64Slice()
+		if err != nil {
+			logger.WithContext(c.Request.Context()).Warnf("⚠️ Rate limit check failed, request allowed: %v", err)
+			c.Next()
+			return
+		}
+		allowed, count, oldest := result[0] == 1, result[1], result[2]
+
+		// Через reset освободится место в окне
+		reset := time.Duration(oldest+window-now) * time.Millisecond
+		c.Header(RateLimitLimitHeader, strconv.Itoa(rule.Limit))
+		c.Header(RateLimitRemainingHeader, strconv.FormatInt(int64(rule.Limit)-count, 10))
+		c.Header(RateLimitResetHeader, strconv.Itoa(ceilSeconds(reset)))
+
+		if !allowed {
+			metrics.ObserveRateLimited(group)
+			c.Header(RetryAfterHeader, strconv.Itoa(ceilSeconds(reset)))
+			c.Error(errs.ErrTooManyRequests)
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// ceilSeconds округляет вверх до целых секунд, но не меньше 1
+func ceilSeconds(d time.Duration) int {
+	seconds := int((d + time.Second - 1) / time.Second)
+	if seconds < 1 {
+		return 1
+	}
+	return seconds
+}
@@ -0,0 +1,38 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"gw-currency-wallet/internal/errs"
+	"gw-currency-wallet/internal/storage/models"
+)
+
+// RequireRole пропускает только пользователей с одной из указанных ролей.
+// Должен стоять после AuthMiddleware. Токены без роли считаются токенами обычного пользователя
+func RequireRole(roles ...string) gin.HandlerFunc {
+	allowed := make(map[string]struct{}, len(roles))
+	for _, role := range roles {
+		allowed[role] = struct{}{}
+	}
+
+	return func(c *gin.Context) {
+		claims, err := GetClaims(c)
+		if err != nil {
+			c.Error(err)
+			c.Abort()
+			return
+		}
+
+		role := claims.Role
+		if role == "" {
+			role = models.RoleUser
+		}
+
+		if _, ok := allowed[role]; !ok {
+			c.Error(errs.ErrForbidden)
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
@@ -17,46 +17,70 @@ func ValidationMiddleware[T any](v *validate.Validator) gin.HandlerFunc {
 
 		// Парсим JSON в структуру
 		if err := c.ShouldBindJSON(&input); err != nil {
-			c.AbortWithStatusJSON(http.StatusBadRequest, ValidationErrorResponse{
-				Error: struct {
-					Code    int               `json:"code"`
-					Message string            `json:"message"`
-					Fields  map[string]string `json:"fields,omitempty"`
-				}{
-					Code:    http.StatusBadRequest,
-					Message: "Invalid request format",
-				},
-			})
+			abortInvalidFormat(c)
 			return
 		}
 
-		// Валидируем структуру
-		if err := v.ValidateStruct(input); err != nil {
-			validationErrors := make(map[string]string)
-			for _, fieldErr := range err.(validator.ValidationErrors) {
-				validationErrors[fieldErr.Field()] = validationErrorMessage(fieldErr)
-			}
+		validateInput(c, v, input)
+	}
+}
 
-			c.AbortWithStatusJSON(http.StatusBadRequest, ValidationErrorResponse{
-				Error: struct {
-					Code    int               `json:"code"`
-					Message string            `json:"message"`
-					Fields  map[string]string `json:"fields,omitempty"`
-				}{
-					Code:    http.StatusBadRequest,
-					Message: "Validation failed",
-					Fields:  validationErrors,
-				},
-			})
+// QueryValidationMiddleware проверяет параметры строки запроса перед выполнением хендлера
+func QueryValidationMiddleware[T any](v *validate.Validator) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var input T
+
+		// Парсим query-параметры в структуру
+		if err := c.ShouldBindQuery(&input); err != nil {
+			abortInvalidFormat(c)
 			return
 		}
 
-		// Передаём данные дальше
-		c.Set("validatedInput", input)
-		c.Next()
+		validateInput(c, v, input)
 	}
 }
 
+// validateInput валидирует структуру и передаёт её дальше под ключом validatedInput
+func validateInput(c *gin.Context, v *validate.Validator, input interface{}) {
+	if err := v.ValidateStruct(input); err != nil {
+		validationErrors := make(map[string]string)
+		for _, fieldErr := range err.(validator.ValidationErrors) {
+			validationErrors[fieldErr.Field()] = validationErrorMessage(fieldErr)
+		}
+
+		c.AbortWithStatusJSON(http.StatusBadRequest, ValidationErrorResponse{
+			Error: struct {
+				Code    int               `json:"code"`
+				Message string            `json:"message"`
+				Fields  map[string]string `json:"fields,omitempty"`
+			}{
+				Code:    http.StatusBadRequest,
+				Message: "Validation failed",
+				Fields:  validationErrors,
+			},
+		})
+		return
+	}
+
+	// Передаём данные дальше
+	c.Set("validatedInput", input)
+	c.Next()
+}
+
+// abortInvalidFormat прерывает запрос, если тело или параметры не удалось разобрать
+func abortInvalidFormat(c *gin.Context) {
+	c.AbortWithStatusJSON(http.StatusBadRequest, ValidationErrorResponse{
+		Error: struct {
+			Code    int               `json:"code"`
+			Message string            `json:"message"`
+			Fields  map[string]string `json:"fields,omitempty"`
+		}{
+			Code:    http.StatusBadRequest,
+			Message: "Invalid request format",
+		},
+	})
+}
+
 // validationErrorMessage формирует читаемое сообщение ошибки
 func validationErrorMessage(fe validator.FieldError) string {
 	switch fe.Tag() {
@@ -70,6 +94,8 @@ func validationErrorMessage(fe validator.FieldError) string {
 		return "must be a valid email address"
 	case "len":
 		return fmt.Sprintf("must be exactly %s characters", fe.Param())
+	case "oneof":
+		return fmt.Sprintf("must be one of: %s", fe.Param())
 	default:
 		return "is invalid"
 	}
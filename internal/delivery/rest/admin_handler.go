@@ -0,0 +1,331 @@
+package rest
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+
+	"gw-currency-wallet/internal/delivery/middleware"
+	"gw-currency-wallet/internal/errs"
+	"gw-currency-wallet/internal/service"
+	"gw-currency-wallet/internal/storage/models"
+)
+
+type Admin struct {
+	svc *service.Service
+}
+
+func NewAdminHandler(svc *service.Service) *Admin {
+	return &Admin{svc: svc}
+}
+
+// SearchUsers godoc
+// @Summary Поиск пользователей
+// @Description Ищет пользователей по id, username или email. Доступно ролям support и admin
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param q query string true "id, часть username или email"
+// @Success 200 {object} models.UsersResponse
+// @Failure 400 {object} middleware.ValidationErrorResponse
+// @Failure 403 {object} middleware.ValidationErrorResponse
+// @Failure 429 {object} middleware.ValidationErrorResponse
+// @Failure 500 {object} middleware.ValidationErrorResponse
+// @Router /admin/users [get]
+func (h *Admin) SearchUsers(c *gin.Context) {
+	input, exists := c.Get("validatedInput")
+	if !exists {
+		c.Error(errs.ErrValidationNotWorking)
+		return
+	}
+
+	query := input.(models.UserSearchQuery)
+
+	users, err := h.svc.AdminService.SearchUsers(c, query.Query)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.UsersResponse{Users: users})
+}
+
+// GetUser godoc
+// @Summary Информация о пользователе
+// @Description Возвращает роль, уровень и статус заморозки пользователя. Доступно ролям support и admin
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "ID пользователя"
+// @Success 200 {object} models.AdminUser
+// @Failure 400 {object} middleware.ValidationErrorResponse
+// @Failure 403 {object} middleware.ValidationErrorResponse
+// @Failure 404 {object} middleware.ValidationErrorResponse
+// @Failure 429 {object} middleware.ValidationErrorResponse
+// @Failure 500 {object} middleware.ValidationErrorResponse
+// @Router /admin/users/{id} [get]
+func (h *Admin) GetUser(c *gin.Context) {
+	userID, err := userIDParam(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	user, err := h.svc.AdminService.GetUser(c, userID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, user)
+}
+
+// GetUserWallet godoc
+// @Summary Баланс кошелька пользователя
+// @Description Возвращает балансы пользователя во всех валютах. Доступно ролям support и admin
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "ID пользователя"
+// @Success 200 {object} models.GetBalanceResponse
+// @Failure 400 {object} middleware.ValidationErrorResponse
+// @Failure 403 {object} middleware.ValidationErrorResponse
+// @Failure 404 {object} middleware.ValidationErrorResponse
+// @Failure 429 {object} middleware.ValidationErrorResponse
+// @Failure 500 {object} middleware.ValidationErrorResponse
+// @Router /admin/users/{id}/wallet [get]
+func (h *Admin) GetUserWallet(c *gin.Context) {
+	userID, err := userIDParam(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	balance, err := h.svc.WalletService.GetBalance(c, userID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.GetBalanceResponse{Balance: balance})
+}
+
+// GetUserTransactions godoc
+// @Summary История операций пользователя
+// @Description Возвращает историю операций пользователя с фильтрами и пагинацией, как GET /wallet/transactions.
+// @Description Доступно ролям support и admin
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "ID пользователя"
+// @Param currency query string false "Код валюты"
+// @Param type query string false "Тип операции" Enums(deposit, withdraw, exchange, transfer, adjustment)
+// @Param from query string false "Начало периода (RFC3339)"
+// @Param to query string false "Конец периода (RFC3339)"
+// @Param limit query int false "Размер страницы (1-100)"
+// @Param cursor query string false "Курсор следующей страницы"
+// @Success 200 {object} models.TransactionsResponse
+// @Failure 400 {object} middleware.ValidationErrorResponse
+// @Failure 403 {object} middleware.ValidationErrorResponse
+// @Failure 429 {object} middleware.ValidationErrorResponse
+// @Failure 500 {object} middleware.ValidationErrorResponse
+// @Router /admin/users/{id}/transactions [get]
+func (h *Admin) GetUserTransactions(c *gin.Context) {
+	userID, err := userIDParam(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	input, exists := c.Get("validatedInput")
+	if !exists {
+		c.Error(errs.ErrValidationNotWorking)
+		return
+	}
+
+	response, err := h.svc.WalletService.GetTransactions(c, userID, input.(models.TransactionsQuery))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// FreezeUser godoc
+// @Summary Заморозить аккаунт
+// @Description Запрещает пользователю пополнения, списания, обмены и переводы. Действие пишется в журнал аудита. Доступно роли admin
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "ID пользователя"
+// @Param input body models.FreezeRequest true "Причина заморозки"
+// @Success 200 {object} models.AdminUser
+// @Failure 400 {object} middleware.ValidationErrorResponse
+// @Failure 403 {object} middleware.ValidationErrorResponse
+// @Failure 404 {object} middleware.ValidationErrorResponse
+// @Failure 429 {object} middleware.ValidationErrorResponse
+// @Failure 500 {object} middleware.ValidationErrorResponse
+// @Router /admin/users/{id}/freeze [post]
+func (h *Admin) FreezeUser(c *gin.Context) {
+	actorID, err := middleware.GetUserUUID(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	userID, err := userIDParam(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	input, exists := c.Get("validatedInput")
+	if !exists {
+		c.Error(errs.ErrValidationNotWorking)
+		return
+	}
+
+	user, err := h.svc.AdminService.FreezeUser(c, actorID, userID, input.(models.FreezeRequest).Reason)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, user)
+}
+
+// UnfreezeUser godoc
+// @Summary Разморозить аккаунт
+// @Description Снимает заморозку с аккаунта. Действие пишется в журнал аудита. Доступно роли admin
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "ID пользователя"
+// @Success 200 {object} models.AdminUser
+// @Failure 400 {object} middleware.ValidationErrorResponse
+// @Failure 403 {object} middleware.ValidationErrorResponse
+// @Failure 404 {object} middleware.ValidationErrorResponse
+// @Failure 429 {object} middleware.ValidationErrorResponse
+// @Failure 500 {object} middleware.ValidationErrorResponse
+// @Router /admin/users/{id}/unfreeze [post]
+func (h *Admin) UnfreezeUser(c *gin.Context) {
+	actorID, err := middleware.GetUserUUID(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	userID, err := userIDParam(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	user, err := h.svc.AdminService.UnfreezeUser(c, actorID, userID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, user)
+}
+
+// UnlockUser godoc
+// @Summary Снять блокировку входа
+// @Description Снимает блокировку входа после неудачных попыток и сбрасывает счётчики, следующая блокировка снова будет минимальной.
+// @Description Блокировку по IP не снимает. Действие пишется в журнал аудита. Доступно роли admin
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "ID пользователя"
+// @Success 200 {object} models.AdminUser
+// @Failure 400 {object} middleware.ValidationErrorResponse
+// @Failure 403 {object} middleware.ValidationErrorResponse
+// @Failure 404 {object} middleware.ValidationErrorResponse
+// @Failure 429 {object} middleware.ValidationErrorResponse
+// @Failure 500 {object} middleware.ValidationErrorResponse
+// @Router /admin/users/{id}/unlock [post]
+func (h *Admin) UnlockUser(c *gin.Context) {
+	actorID, err := middleware.GetUserUUID(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	userID, err := userIDParam(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	user, err := h.svc.AdminService.UnlockUser(c, actorID, userID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, user)
+}
+
+// AdjustBalance godoc
+// @Summary Ручная корректировка баланса
+// @Description Зачисляет (amount > 0) или списывает (amount < 0) средства. Операция попадает в историю пользователя
+// @Description с типом adjustment и в журнал аудита. Доступно роли admin; собственный баланс корректировать нельзя
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "ID пользователя"
+// @Param input body models.AdjustmentRequest true "Данные корректировки"
+// @Param Idempotency-Key header string false "Ключ идемпотентности для безопасного повтора запроса"
+// @Success 200 {object} models.WalletOperationsResponse
+// @Failure 400 {object} middleware.ValidationErrorResponse
+// @Failure 403 {object} middleware.ValidationErrorResponse
+// @Failure 404 {object} middleware.ValidationErrorResponse
+// @Failure 429 {object} middleware.ValidationErrorResponse
+// @Failure 500 {object} middleware.ValidationErrorResponse
+// @Router /admin/users/{id}/adjustments [post]
+func (h *Admin) AdjustBalance(c *gin.Context) {
+	actorID, err := middleware.GetUserUUID(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	userID, err := userIDParam(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	input, exists := c.Get("validatedInput")
+	if !exists {
+		c.Error(errs.ErrValidationNotWorking)
+		return
+	}
+
+	userInput := input.(models.AdjustmentRequest)
+	amountDecimal := decimal.NewFromFloat(userInput.Amount)
+
+	balance, err := h.svc.AdminService.AdjustBalance(c, actorID, userID, userInput.Currency, amountDecimal, userInput.Reason)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	successResponse := models.WalletOperationsResponse{
+		Message: "Balance adjusted successfully",
+		Balance: balance,
+	}
+
+	c.JSON(http.StatusOK, successResponse)
+}
+
+// userIDParam извлекает ID пользователя из пути запроса
+func userIDParam(c *gin.Context) (uuid.UUID, error) {
+	return uuidParam(c, "id", errs.ErrInvalidUserId)
+}
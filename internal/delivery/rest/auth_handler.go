@@ -7,6 +7,7 @@ import (
 	"github.com/sirupsen/logrus"
 
 	config "gw-currency-wallet/internal/config"
+	"gw-currency-wallet/internal/delivery/middleware"
 	"gw-currency-wallet/internal/errs"
 	"gw-currency-wallet/internal/service"
 	"gw-currency-wallet/internal/storage/models"
@@ -43,6 +44,7 @@ func NewAuthHandler(
 // @Param input body models.UserRegister true "Данные для регистрации пользователя"
 // @Success 200 {object} models.RegisterSuccessResponse
 // @Failure 400 {object} middleware.ValidationErrorResponse
+// @Failure 429 {object} middleware.ValidationErrorResponse
 // @Failure 500 {object} middleware.ValidationErrorResponse
 // @Router /auth/register [post]
 func (h *Auth) Register(c *gin.Context) {
@@ -69,7 +71,9 @@ func (h *Auth) Register(c *gin.Context) {
 
 // Login godoc
 // @Summary Вход пользователя в систему
-// @Description Авторизует пользователя и возвращает токен
+// @Description Авторизует пользователя и возвращает короткоживущий access-токен и refresh-токен.
+// @Description Если включена 2FA, вместо токенов возвращает mfa_required и mfa_token для /auth/login/mfa.
+// @Description После auth.lockout.max_failures неверных паролей подряд вход временно блокируется: 429 с заголовком Retry-After
 // @Tags auth
 // @Accept json
 // @Produce json
@@ -77,6 +81,7 @@ func (h *Auth) Register(c *gin.Context) {
 // @Success 200 {object} models.LoginSuccessResponse
 // @Failure 400 {object} middleware.ValidationErrorResponse
 // @Failure 401 {object} middleware.ValidationErrorResponse
+// @Failure 429 {object} middleware.ValidationErrorResponse
 // @Failure 500 {object} middleware.ValidationErrorResponse
 // @Router /auth/login [post]
 func (h *Auth) Login(c *gin.Context) {
@@ -88,15 +93,92 @@ func (h *Auth) Login(c *gin.Context) {
 
 	userInput := input.(models.UserLogin)
 
-	token, err := h.svc.AuthService.Login(c, &userInput)
+	tokens, err := h.svc.AuthService.Login(c, &userInput, c.ClientIP())
 	if err != nil {
 		c.Error(err)
 		return
 	}
 
-	successResponse := models.LoginSuccessResponse{
-		Token: token,
+	c.JSON(http.StatusOK, tokens)
+}
+
+// Refresh godoc
+// @Summary Обновление токенов
+// @Description Выдаёт новую пару токенов по refresh-токену. Использованный refresh-токен отзывается,
+// @Description повторное его использование отзывает все токены этого входа
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param input body models.RefreshRequest true "Refresh-токен"
+// @Success 200 {object} models.LoginSuccessResponse
+// @Failure 400 {object} middleware.ValidationErrorResponse
+// @Failure 401 {object} middleware.ValidationErrorResponse
+// @Failure 429 {object} middleware.ValidationErrorResponse
+// @Failure 500 {object} middleware.ValidationErrorResponse
+// @Router /auth/refresh [post]
+func (h *Auth) Refresh(c *gin.Context) {
+	input, exists := c.Get("validatedInput")
+	if !exists {
+		c.Error(errs.ErrValidationNotWorking)
+		return
+	}
+
+	userInput := input.(models.RefreshRequest)
+
+	tokens, err := h.svc.AuthService.Refresh(c, userInput.RefreshToken)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, tokens)
+}
+
+// Logout godoc
+// @Summary Выход из системы
+// @Description Отзывает refresh-токен и текущий access-токен
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param input body models.LogoutRequest true "Refresh-токен текущего входа"
+// @Success 200 {object} models.LogoutSuccessResponse
+// @Failure 400 {object} middleware.ValidationErrorResponse
+// @Failure 401 {object} middleware.ValidationErrorResponse
+// @Failure 429 {object} middleware.ValidationErrorResponse
+// @Failure 500 {object} middleware.ValidationErrorResponse
+// @Router /auth/logout [post]
+func (h *Auth) Logout(c *gin.Context) {
+	claims, err := middleware.GetClaims(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	input, exists := c.Get("validatedInput")
+	if !exists {
+		c.Error(errs.ErrValidationNotWorking)
+		return
+	}
+
+	userInput := input.(models.LogoutRequest)
+
+	if err := h.svc.AuthService.Logout(c, claims, userInput.RefreshToken); err != nil {
+		c.Error(err)
+		return
+	}
+
+	successResponse := models.LogoutSuccessResponse{
+		Message: "Logged out successfully",
 	}
 
 	c.JSON(http.StatusOK, successResponse)
 }
+
+// JWKS отдаёт JWK Set с публичными ключами, которыми можно проверить access-токены.
+// Маршрут находится вне /api/v1, поэтому не описан в Swagger
+func (h *Auth) JWKS(c *gin.Context) {
+	// Ключи меняются только при ротации, клиентам можно их кэшировать
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, h.svc.AuthService.JWKS())
+}
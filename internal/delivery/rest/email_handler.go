@@ -0,0 +1,122 @@
+package rest
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"gw-currency-wallet/internal/delivery/middleware"
+	"gw-currency-wallet/internal/errs"
+	"gw-currency-wallet/internal/storage/models"
+)
+
+// VerifyEmail godoc
+// @Summary Подтверждение email
+// @Description Подтверждает email по одноразовому токену из письма. Ссылка действует auth.email.verification_ttl.
+// @Description До подтверждения пополнение, вывод, обмен и переводы отклоняются с 403
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param input body models.VerifyEmailRequest true "Токен из письма"
+// @Success 200 {object} models.EmailResponse
+// @Failure 400 {object} middleware.ValidationErrorResponse
+// @Failure 429 {object} middleware.ValidationErrorResponse
+// @Failure 500 {object} middleware.ValidationErrorResponse
+// @Router /auth/verify-email [post]
+func (h *Auth) VerifyEmail(c *gin.Context) {
+	input, exists := c.Get("validatedInput")
+	if !exists {
+		c.Error(errs.ErrValidationNotWorking)
+		return
+	}
+
+	if err := h.svc.AuthService.VerifyEmail(c, input.(models.VerifyEmailRequest).Token); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.EmailResponse{Message: "Email verified"})
+}
+
+// ResendVerification godoc
+// @Summary Повторное письмо подтверждения email
+// @Description Отправляет новое письмо со ссылкой подтверждения; ссылка из прежнего письма перестаёт действовать
+// @Tags auth
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} models.EmailResponse
+// @Failure 401 {object} middleware.ValidationErrorResponse
+// @Failure 409 {object} middleware.ValidationErrorResponse
+// @Failure 429 {object} middleware.ValidationErrorResponse
+// @Failure 500 {object} middleware.ValidationErrorResponse
+// @Router /auth/verify-email/resend [post]
+func (h *Auth) ResendVerification(c *gin.Context) {
+	userID, err := middleware.GetUserUUID(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	if err := h.svc.AuthService.ResendVerification(c, userID); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.EmailResponse{Message: "Verification email sent"})
+}
+
+// ForgotPassword godoc
+// @Summary Запрос сброса пароля
+// @Description Отправляет на email ссылку сброса пароля, действующую auth.email.reset_ttl.
+// @Description Ответ одинаковый для зарегистрированных и незарегистрированных адресов
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param input body models.ForgotPasswordRequest true "Email аккаунта"
+// @Success 200 {object} models.EmailResponse
+// @Failure 400 {object} middleware.ValidationErrorResponse
+// @Failure 429 {object} middleware.ValidationErrorResponse
+// @Failure 500 {object} middleware.ValidationErrorResponse
+// @Router /auth/password/forgot [post]
+func (h *Auth) ForgotPassword(c *gin.Context) {
+	input, exists := c.Get("validatedInput")
+	if !exists {
+		c.Error(errs.ErrValidationNotWorking)
+		return
+	}
+
+	if err := h.svc.AuthService.ForgotPassword(c, input.(models.ForgotPasswordRequest).Email); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.EmailResponse{Message: "If the email is registered, a password reset link has been sent"})
+}
+
+// ResetPassword godoc
+// @Summary Сброс пароля
+// @Description Задаёт новый пароль по одноразовому токену из письма. Все refresh-токены пользователя отзываются,
+// @Description блокировка входа после неудачных попыток снимается
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param input body models.ResetPasswordRequest true "Токен из письма и новый пароль"
+// @Success 200 {object} models.EmailResponse
+// @Failure 400 {object} middleware.ValidationErrorResponse
+// @Failure 429 {object} middleware.ValidationErrorResponse
+// @Failure 500 {object} middleware.ValidationErrorResponse
+// @Router /auth/password/reset [post]
+func (h *Auth) ResetPassword(c *gin.Context) {
+	input, exists := c.Get("validatedInput")
+	if !exists {
+		c.Error(errs.ErrValidationNotWorking)
+		return
+	}
+
+	if err := h.svc.AuthService.ResetPassword(c, input.(models.ResetPasswordRequest)); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.EmailResponse{Message: "Password has been reset"})
+}
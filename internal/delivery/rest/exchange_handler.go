@@ -4,6 +4,7 @@ import (
 	"net/http"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 	"github.com/shopspring/decimal"
 
 	"gw-currency-wallet/internal/delivery/middleware"
@@ -27,12 +28,14 @@ func NewExchangeHandler(svc *service.Service, validate *validate.Validator) *Exc
 
 // GetExchangeRates godoc
 // @Summary Получить текущие курсы валют
-// @Description Возвращает список текущих курсов обмена валют
+// @Description Возвращает список текущих курсов обмена валют и время их получения (as_of).
+// @Description Если gw-exchanger недоступен, отдаются последние известные курсы с признаком stale: true
 // @Tags exchange
 // @Accept json
 // @Produce json
 // @Security BearerAuth
 // @Success 200 {object} models.ExchangeRatesResponse
+// @Failure 429 {object} middleware.ValidationErrorResponse
 // @Failure 500 {object} middleware.ValidationErrorResponse
 // @Router /exchange/rates [get]
 func (h *ExchangeHandler) GetExchangeRates(c *gin.Context) {
@@ -43,7 +46,9 @@ func (h *ExchangeHandler) GetExchangeRates(c *gin.Context) {
 	}
 
 	successResponse := models.ExchangeRatesResponse{
-		Rates: rates,
+		Rates: rates.Rates,
+		AsOf:  rates.AsOf,
+		Stale: rates.Stale,
 	}
 
 	c.JSON(http.StatusOK, successResponse)
@@ -51,15 +56,24 @@ func (h *ExchangeHandler) GetExchangeRates(c *gin.Context) {
 
 // ExchangeCurrency godoc
 // @Summary Обмен валют
-// @Description Обмен валюты с использованием заданного количества и курсов валют
+// @Description Обмен валюты с использованием заданного количества и курсов валют.
+// @Description Из суммы зачисления удерживается комиссия по тарифу пользователя.
+// @Description Если передан quote_id, обмен выполняется по зафиксированному курсу из POST /exchange/quote.
+// @Description До подтверждения email отклоняется с 403
 // @Tags exchange
 // @Accept json
 // @Produce json
 // @Security BearerAuth
 // @Param input body models.ExchangeRequest true "Данные для обмена валюты"
+// @Param Idempotency-Key header string false "Ключ идемпотентности для безопасного повтора запроса"
 // @Success 200 {object} models.ExchangeCurrencyResponse
 // @Failure 400 {object} middleware.ValidationErrorResponse
+// @Failure 403 {object} middleware.ValidationErrorResponse
+// @Failure 409 {object} middleware.ValidationErrorResponse
+// @Failure 410 {object} middleware.ValidationErrorResponse
+// @Failure 429 {object} middleware.ValidationErrorResponse
 // @Failure 500 {object} middleware.ValidationErrorResponse
+// @Failure 503 {object} middleware.ValidationErrorResponse
 // @Router /exchange [post]
 func (h *ExchangeHandler) ExchangeCurrency(c *gin.Context) {
 	userID, err := middleware.GetUserUUID(c)
@@ -81,36 +95,82 @@ func (h *ExchangeHandler) ExchangeCurrency(c *gin.Context) {
 		return
 	}
 
-	// Преобразуем float64 в decimal.Decimal
-	amountDecimal := decimal.NewFromFloat(userInput.Amount)
-	amountDecimal = amountDecimal.Round(2)
+	var quote models.ExchangeQuote
+
+	if userInput.QuoteID != "" {
+		// Обмен по зафиксированному ранее курсу и комиссии
+		quote, err = h.svc.UseQuote(c, userID, uuid.MustParse(userInput.QuoteID), userInput)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+	} else {
+		// Рассчитываем обмен по текущему курсу с учётом комиссии; сумму округляет сервис по точности валюты
+		quote, err = h.svc.PriceExchange(c, userID, userInput.FromCurrency, userInput.ToCurrency, decimal.NewFromFloat(userInput.Amount))
+		if err != nil {
+			c.Error(err)
+			return
+		}
+	}
 
-	rateStr, err := h.svc.GetRate(c, userInput.FromCurrency, userInput.ToCurrency)
+	newBalance, err := h.svc.ExchangeService.ExchangeCurrency(
+		c, userID, quote.FromCurrency, quote.ToCurrency, quote.FromAmount, quote.ToAmount, quote.Fee,
+	)
 	if err != nil {
+		// Котировка расходуется только успешным обменом
+		if userInput.QuoteID != "" {
+			h.svc.ReleaseQuote(c, userID, quote.QuoteID)
+		}
 		c.Error(err)
 		return
 	}
 
-	// Преобразуем курс из строки в decimal.Decimal
-	rate, err := decimal.NewFromString(rateStr)
+	successResponse := models.ExchangeCurrencyResponse{
+		Message:         "Exchange successful",
+		Rate:            quote.Rate,
+		ExchangedAmount: quote.ToAmount,
+		Fee:             quote.Fee,
+		FeeCurrency:     quote.FeeCurrency,
+		NewBalance:      newBalance,
+	}
+
+	c.JSON(http.StatusOK, successResponse)
+}
+
+// CreateQuote godoc
+// @Summary Зафиксировать курс обмена
+// @Description Возвращает котировку с курсом, суммами обмена и комиссией. Котировка действует ограниченное время и может быть использована один раз
+// @Tags exchange
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param input body models.QuoteRequest true "Данные для расчёта обмена"
+// @Success 200 {object} models.ExchangeQuote
+// @Failure 400 {object} middleware.ValidationErrorResponse
+// @Failure 429 {object} middleware.ValidationErrorResponse
+// @Failure 500 {object} middleware.ValidationErrorResponse
+// @Failure 503 {object} middleware.ValidationErrorResponse
+// @Router /exchange/quote [post]
+func (h *ExchangeHandler) CreateQuote(c *gin.Context) {
+	userID, err := middleware.GetUserUUID(c)
 	if err != nil {
 		c.Error(err)
 		return
 	}
 
-	exchangedAmount := amountDecimal.Mul(rate)
+	input, exists := c.Get("validatedInput")
+	if !exists {
+		c.Error(errs.ErrValidationNotWorking)
+		return
+	}
+
+	userInput := input.(models.QuoteRequest)
 
-	newBalance, err := h.svc.ExchangeService.ExchangeCurrency(c, userID, userInput.FromCurrency, userInput.ToCurrency, amountDecimal, exchangedAmount)
+	quote, err := h.svc.CreateQuote(c, userID, userInput.FromCurrency, userInput.ToCurrency, decimal.NewFromFloat(userInput.Amount))
 	if err != nil {
 		c.Error(err)
 		return
 	}
 
-	successResponse := models.ExchangeCurrencyResponse{
-		Message:         "Exchange successful",
-		ExchangedAmount: exchangedAmount,
-		NewBalance:      newBalance,
-	}
-
-	c.JSON(http.StatusOK, successResponse)
+	c.JSON(http.StatusOK, quote)
 }
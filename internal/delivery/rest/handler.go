@@ -2,6 +2,8 @@ package rest
 
 import (
 	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/redis/go-redis/v9"
 	"github.com/sirupsen/logrus"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
@@ -9,32 +11,78 @@ import (
 	"gw-currency-wallet/docs"
 	config "gw-currency-wallet/internal/config"
 	"gw-currency-wallet/internal/delivery/middleware"
+	"gw-currency-wallet/internal/health"
+	"gw-currency-wallet/internal/metrics"
 	"gw-currency-wallet/internal/service"
 	"gw-currency-wallet/internal/storage/models"
 	"gw-currency-wallet/internal/storage/models/validate"
+	"gw-currency-wallet/internal/stream"
 	"gw-currency-wallet/internal/utils"
 )
 
 type AuthHandler interface {
 	Register(c *gin.Context)
 	Login(c *gin.Context)
+	Refresh(c *gin.Context)
+	Logout(c *gin.Context)
+	JWKS(c *gin.Context)
+	LoginMFA(c *gin.Context)
+	EnrollMFA(c *gin.Context)
+	ConfirmMFA(c *gin.Context)
+	DisableMFA(c *gin.Context)
+	VerifyEmail(c *gin.Context)
+	ResendVerification(c *gin.Context)
+	ForgotPassword(c *gin.Context)
+	ResetPassword(c *gin.Context)
+	GetProfile(c *gin.Context)
+	UpdateProfile(c *gin.Context)
+	ChangePassword(c *gin.Context)
 }
 
 type Exchange interface {
 	GetExchangeRates(c *gin.Context)
 	ExchangeCurrency(c *gin.Context)
+	CreateQuote(c *gin.Context)
 }
 
 type WalletHandler interface {
 	GetBalance(c *gin.Context)
 	Deposit(c *gin.Context)
 	Withdraw(c *gin.Context)
+	Transfer(c *gin.Context)
+	GetTransactions(c *gin.Context)
+}
+
+type AdminHandler interface {
+	SearchUsers(c *gin.Context)
+	GetUser(c *gin.Context)
+	GetUserWallet(c *gin.Context)
+	GetUserTransactions(c *gin.Context)
+	FreezeUser(c *gin.Context)
+	UnfreezeUser(c *gin.Context)
+	UnlockUser(c *gin.Context)
+	AdjustBalance(c *gin.Context)
+}
+
+type WebhookHandler interface {
+	CreateWebhook(c *gin.Context)
+	GetWebhooks(c *gin.Context)
+	DeleteWebhook(c *gin.Context)
+	GetDeliveries(c *gin.Context)
+	ReplayDelivery(c *gin.Context)
+}
+
+type StreamHandler interface {
+	Stream(c *gin.Context)
 }
 
 type Handler struct {
 	AuthHandler
 	Exchange
 	WalletHandler
+	AdminHandler
+	WebhookHandler
+	StreamHandler
 }
 
 func NewHandler(
@@ -42,47 +90,147 @@ func NewHandler(
 	logger *logrus.Logger,
 	cfg *config.AuthConfig,
 	validate *validate.Validator,
+	hub *stream.Hub,
+	streamCfg *config.StreamConfig,
 ) *Handler {
 	return &Handler{
-		AuthHandler:   NewAuthHandler(svc, logger, cfg, validate),
-		Exchange:      NewExchangeHandler(svc, validate),
-		WalletHandler: NewWalletHandler(svc, validate),
+		AuthHandler:    NewAuthHandler(svc, logger, cfg, validate),
+		Exchange:       NewExchangeHandler(svc, validate),
+		WalletHandler:  NewWalletHandler(svc, validate),
+		AdminHandler:   NewAdminHandler(svc),
+		WebhookHandler: NewWebhookHandler(svc),
+		StreamHandler:  NewStreamHandler(svc, hub, streamCfg, logger),
 	}
 }
 
-func (h *Handler) InitRoutes(logger *logrus.Logger, jwtManager *utils.JWTManager, v *validate.Validator) *gin.Engine {
+func (h *Handler) InitRoutes(
+	logger *logrus.Logger,
+	jwtManager *utils.JWTManager,
+	revocation middleware.TokenRevocationChecker,
+	v *validate.Validator,
+	cache *redis.Client,
+	cfg *config.Config,
+	checker *health.Checker,
+) *gin.Engine {
 	router := gin.New()
+	// Без доверенных прокси X-Forwarded-For игнорируется, иначе клиент мог бы обойти лимиты по IP
+	if err := router.SetTrustedProxies(cfg.Server.TrustedProxies); err != nil {
+		logger.Fatalf("Invalid server.trusted_proxies: %v", err)
+	}
+
+	// Пробы Kubernetes регистрируются до middleware, чтобы не засорять трассы и метрики
+	probes := NewHealthHandler(checker)
+	router.GET("/healthz", probes.Liveness)
+	router.GET("/readyz", probes.Readiness)
+
+	// Контекст запроса (со span трассировки) доступен через *gin.Context, который передаётся в сервисы
+	router.ContextWithFallback = true
+
+	// Трассировка и метрики снаружи обработчика ошибок, чтобы учитывать итоговый статус ответа
+	router.Use(middleware.TracingMiddleware())
+	router.Use(metrics.HTTPMiddleware())
 
 	// Обработчик ошибок и паник
 	router.Use(middleware.ErrorHandler(logger))
 	router.Use(middleware.RecoverMiddleware(logger))
 
+	// Метрики Prometheus
+	router.GET("/metrics", gin.WrapH(promhttp.Handler()))
+
 	docs.SwaggerInfo.BasePath = "/api/v1"
 	router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 
+	// Публичные ключи для проверки токенов другими сервисами
+	router.GET("/.well-known/jwks.json", h.AuthHandler.JWKS)
+
 	apiV1 := router.Group("/api/v1")
 
+	// Ограничения частоты: /auth/* по IP, остальные группы по пользователю
+	limits := &cfg.RateLimit
+	authLimit := middleware.RateLimitMiddleware(cache, logger, "auth", limits.Auth, middleware.RateLimitByIP)
+
 	// Группа маршрутов без авторизации
-	auth := apiV1.Group("/auth")
+	auth := apiV1.Group("/auth", authLimit)
 	{
 		auth.POST("/register", middleware.ValidationMiddleware[models.UserRegister](v), h.AuthHandler.Register)
 		auth.POST("/login", middleware.ValidationMiddleware[models.UserLogin](v), h.AuthHandler.Login)
+		auth.POST("/login/mfa", middleware.ValidationMiddleware[models.MFALoginRequest](v), h.AuthHandler.LoginMFA)
+		auth.POST("/refresh", middleware.ValidationMiddleware[models.RefreshRequest](v), h.AuthHandler.Refresh)
+		auth.POST("/verify-email", middleware.ValidationMiddleware[models.VerifyEmailRequest](v), h.AuthHandler.VerifyEmail)
+		auth.POST("/password/forgot", middleware.ValidationMiddleware[models.ForgotPasswordRequest](v), h.AuthHandler.ForgotPassword)
+		auth.POST("/password/reset", middleware.ValidationMiddleware[models.ResetPasswordRequest](v), h.AuthHandler.ResetPassword)
 	}
 
 	// Группа маршрутов с авторизацией
 	protected := apiV1.Group("")
-	protected.Use(middleware.AuthMiddleware(jwtManager))
+	protected.Use(middleware.AuthMiddleware(jwtManager, revocation))
+	protected.POST("/auth/logout", authLimit, middleware.ValidationMiddleware[models.LogoutRequest](v), h.AuthHandler.Logout)
+	protected.POST("/auth/verify-email/resend", authLimit, h.AuthHandler.ResendVerification)
+
+	// Подключение и отключение 2FA
+	mfa := protected.Group("/auth/mfa", authLimit)
+	{
+		mfa.POST("/enroll", h.AuthHandler.EnrollMFA)
+		mfa.POST("/confirm", middleware.ValidationMiddleware[models.MFACodeRequest](v), h.AuthHandler.ConfirmMFA)
+		mfa.POST("/disable", middleware.ValidationMiddleware[models.MFACodeRequest](v), h.AuthHandler.DisableMFA)
+	}
+
+	// Профиль текущего пользователя; лимит /auth/*, так как здесь проверяется пароль
+	users := protected.Group("/users/me", authLimit)
+	{
+		users.GET("", h.AuthHandler.GetProfile)
+		users.PATCH("", middleware.ValidationMiddleware[models.UpdateProfileRequest](v), h.AuthHandler.UpdateProfile)
+		users.POST("/password", middleware.ValidationMiddleware[models.ChangePasswordRequest](v), h.AuthHandler.ChangePassword)
+	}
+
+	// Поток событий принимает токен и из параметра запроса, поэтому подключается отдельно от protected
+	apiV1.GET("/stream",
+		middleware.TokenFromQuery("access_token"),
+		middleware.AuthMiddleware(jwtManager, revocation),
+		h.StreamHandler.Stream,
+	)
+
+	// Повторы денежных операций с тем же Idempotency-Key не выполняются дважды
+	idempotency := middleware.IdempotencyMiddleware(cache, cfg.Idempotency.TTL)
 	{
-		wallet := protected.Group("/wallet")
+		wallet := protected.Group("/wallet", middleware.RateLimitMiddleware(cache, logger, "wallet", limits.Wallet, middleware.RateLimitByUser))
 		{
 			wallet.GET("/balance", h.WalletHandler.GetBalance)
-			wallet.POST("/deposit", middleware.ValidationMiddleware[models.WalletTransaction](v), h.WalletHandler.Deposit)
-			wallet.POST("/withdraw", middleware.ValidationMiddleware[models.WalletTransaction](v), h.WalletHandler.Withdraw)
+			wallet.GET("/transactions", middleware.QueryValidationMiddleware[models.TransactionsQuery](v), h.WalletHandler.GetTransactions)
+			wallet.POST("/deposit", idempotency, middleware.ValidationMiddleware[models.WalletTransaction](v), h.WalletHandler.Deposit)
+			wallet.POST("/withdraw", idempotency, middleware.ValidationMiddleware[models.WalletTransaction](v), h.WalletHandler.Withdraw)
+			wallet.POST("/transfer", idempotency, middleware.ValidationMiddleware[models.TransferRequest](v), h.WalletHandler.Transfer)
 		}
-		exchange := protected.Group("/exchange")
+		exchange := protected.Group("/exchange", middleware.RateLimitMiddleware(cache, logger, "exchange", limits.Exchange, middleware.RateLimitByUser))
 		{
 			exchange.GET("/rates", h.Exchange.GetExchangeRates)
-			exchange.POST("/", middleware.ValidationMiddleware[models.ExchangeRequest](v), h.Exchange.ExchangeCurrency)
+			exchange.POST("/quote", middleware.ValidationMiddleware[models.QuoteRequest](v), h.Exchange.CreateQuote)
+			exchange.POST("/", idempotency, middleware.ValidationMiddleware[models.ExchangeRequest](v), h.Exchange.ExchangeCurrency)
+		}
+
+		webhooks := protected.Group("/webhooks", middleware.RateLimitMiddleware(cache, logger, "webhooks", limits.Webhooks, middleware.RateLimitByUser))
+		{
+			webhooks.POST("", middleware.ValidationMiddleware[models.WebhookRequest](v), h.WebhookHandler.CreateWebhook)
+			webhooks.GET("", h.WebhookHandler.GetWebhooks)
+			webhooks.DELETE("/:id", h.WebhookHandler.DeleteWebhook)
+			webhooks.GET("/:id/deliveries", middleware.QueryValidationMiddleware[models.WebhookDeliveriesQuery](v), h.WebhookHandler.GetDeliveries)
+			webhooks.POST("/:id/deliveries/:delivery_id/replay", h.WebhookHandler.ReplayDelivery)
+		}
+
+		// Просмотр доступен поддержке, изменения — только администраторам
+		admin := protected.Group("/admin")
+		admin.Use(middleware.RequireRole(models.RoleSupport, models.RoleAdmin))
+		admin.Use(middleware.RateLimitMiddleware(cache, logger, "admin", limits.Admin, middleware.RateLimitByUser))
+		adminOnly := middleware.RequireRole(models.RoleAdmin)
+		{
+			admin.GET("/users", middleware.QueryValidationMiddleware[models.UserSearchQuery](v), h.AdminHandler.SearchUsers)
+			admin.GET("/users/:id", h.AdminHandler.GetUser)
+			admin.GET("/users/:id/wallet", h.AdminHandler.GetUserWallet)
+			admin.GET("/users/:id/transactions", middleware.QueryValidationMiddleware[models.TransactionsQuery](v), h.AdminHandler.GetUserTransactions)
+			admin.POST("/users/:id/freeze", adminOnly, middleware.ValidationMiddleware[models.FreezeRequest](v), h.AdminHandler.FreezeUser)
+			admin.POST("/users/:id/unfreeze", adminOnly, h.AdminHandler.UnfreezeUser)
+			admin.POST("/users/:id/unlock", adminOnly, h.AdminHandler.UnlockUser)
+			admin.POST("/users/:id/adjustments", adminOnly, idempotency, middleware.ValidationMiddleware[models.AdjustmentRequest](v), h.AdminHandler.AdjustBalance)
 		}
 	}
 
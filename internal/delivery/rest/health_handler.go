@@ -0,0 +1,36 @@
+package rest
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"gw-currency-wallet/internal/health"
+	"gw-currency-wallet/internal/storage/models"
+)
+
+type Health struct {
+	checker *health.Checker
+}
+
+func NewHealthHandler(checker *health.Checker) *Health {
+	return &Health{checker: checker}
+}
+
+// Liveness отвечает, пока процесс способен обрабатывать запросы; зависимости не проверяет,
+// чтобы их недоступность не приводила к перезапуску пода.
+// Маршрут находится вне /api/v1, поэтому не описан в Swagger
+func (h *Health) Liveness(c *gin.Context) {
+	c.JSON(http.StatusOK, models.HealthResponse{Status: models.HealthStatusOK})
+}
+
+// Readiness проверяет PostgreSQL, Redis и соединение с gw-exchanger.
+// Возвращает 503, если зависимость недоступна или сервер останавливается
+func (h *Health) Readiness(c *gin.Context) {
+	response, ready := h.checker.Ready(c.Request.Context())
+	if !ready {
+		c.JSON(http.StatusServiceUnavailable, response)
+		return
+	}
+	c.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,147 @@
+package rest
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"gw-currency-wallet/internal/delivery/middleware"
+	"gw-currency-wallet/internal/errs"
+	"gw-currency-wallet/internal/storage/models"
+)
+
+// LoginMFA godoc
+// @Summary Второй шаг входа с 2FA
+// @Description Обменивает mfa_token из ответа /auth/login и код из приложения-аутентификатора (или код восстановления) на токены.
+// @Description mfa_token одноразовый, действует auth.mfa.challenge_ttl и перестаёт действовать после auth.mfa.max_attempts неверных кодов.
+// @Description Неверные коды считаются неудачными входами и приводят к блокировке, как неверный пароль
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param input body models.MFALoginRequest true "mfa_token и код"
+// @Success 200 {object} models.LoginSuccessResponse
+// @Failure 400 {object} middleware.ValidationErrorResponse
+// @Failure 401 {object} middleware.ValidationErrorResponse
+// @Failure 429 {object} middleware.ValidationErrorResponse
+// @Failure 500 {object} middleware.ValidationErrorResponse
+// @Router /auth/login/mfa [post]
+func (h *Auth) LoginMFA(c *gin.Context) {
+	input, exists := c.Get("validatedInput")
+	if !exists {
+		c.Error(errs.ErrValidationNotWorking)
+		return
+	}
+
+	userInput := input.(models.MFALoginRequest)
+
+	tokens, err := h.svc.AuthService.LoginMFA(c, &userInput, c.ClientIP())
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, tokens)
+}
+
+// EnrollMFA godoc
+// @Summary Подключение 2FA
+// @Description Выдаёт новый секрет TOTP и ссылку otpauth:// для QR-кода. 2FA включится после подтверждения кодом в /auth/mfa/confirm,
+// @Description до этого повторный вызов заменяет секрет
+// @Tags auth
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} models.MFAEnrollResponse
+// @Failure 401 {object} middleware.ValidationErrorResponse
+// @Failure 409 {object} middleware.ValidationErrorResponse
+// @Failure 429 {object} middleware.ValidationErrorResponse
+// @Failure 500 {object} middleware.ValidationErrorResponse
+// @Router /auth/mfa/enroll [post]
+func (h *Auth) EnrollMFA(c *gin.Context) {
+	userID, err := middleware.GetUserUUID(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	enrollment, err := h.svc.AuthService.EnrollMFA(c, userID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, enrollment)
+}
+
+// ConfirmMFA godoc
+// @Summary Подтверждение 2FA
+// @Description Включает 2FA по первому коду из приложения и возвращает одноразовые коды восстановления.
+// @Description Коды показываются один раз, в базе хранятся только их хэши
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param input body models.MFACodeRequest true "Код из приложения-аутентификатора"
+// @Success 200 {object} models.MFARecoveryCodesResponse
+// @Failure 400 {object} middleware.ValidationErrorResponse
+// @Failure 401 {object} middleware.ValidationErrorResponse
+// @Failure 409 {object} middleware.ValidationErrorResponse
+// @Failure 429 {object} middleware.ValidationErrorResponse
+// @Failure 500 {object} middleware.ValidationErrorResponse
+// @Router /auth/mfa/confirm [post]
+func (h *Auth) ConfirmMFA(c *gin.Context) {
+	userID, err := middleware.GetUserUUID(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	input, exists := c.Get("validatedInput")
+	if !exists {
+		c.Error(errs.ErrValidationNotWorking)
+		return
+	}
+
+	codes, err := h.svc.AuthService.ConfirmMFA(c, userID, input.(models.MFACodeRequest).Code)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, codes)
+}
+
+// DisableMFA godoc
+// @Summary Отключение 2FA
+// @Description Отключает 2FA по коду из приложения или коду восстановления. Секрет и коды восстановления удаляются
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param input body models.MFACodeRequest true "Код из приложения или код восстановления"
+// @Success 200 {object} models.MFADisableResponse
+// @Failure 400 {object} middleware.ValidationErrorResponse
+// @Failure 401 {object} middleware.ValidationErrorResponse
+// @Failure 409 {object} middleware.ValidationErrorResponse
+// @Failure 429 {object} middleware.ValidationErrorResponse
+// @Failure 500 {object} middleware.ValidationErrorResponse
+// @Router /auth/mfa/disable [post]
+func (h *Auth) DisableMFA(c *gin.Context) {
+	userID, err := middleware.GetUserUUID(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	input, exists := c.Get("validatedInput")
+	if !exists {
+		c.Error(errs.ErrValidationNotWorking)
+		return
+	}
+
+	if err := h.svc.AuthService.DisableMFA(c, userID, input.(models.MFACodeRequest).Code); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.MFADisableResponse{Message: "Two-factor authentication disabled"})
+}
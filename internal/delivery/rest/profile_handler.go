@@ -0,0 +1,111 @@
+package rest
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"gw-currency-wallet/internal/delivery/middleware"
+	"gw-currency-wallet/internal/errs"
+	"gw-currency-wallet/internal/storage/models"
+)
+
+// GetProfile godoc
+// @Summary Профиль текущего пользователя
+// @Tags users
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} models.UserProfile
+// @Failure 401 {object} middleware.ValidationErrorResponse
+// @Failure 429 {object} middleware.ValidationErrorResponse
+// @Failure 500 {object} middleware.ValidationErrorResponse
+// @Router /users/me [get]
+func (h *Auth) GetProfile(c *gin.Context) {
+	userID, err := middleware.GetUserUUID(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	profile, err := h.svc.AuthService.GetProfile(c, userID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, profile)
+}
+
+// UpdateProfile godoc
+// @Summary Изменение профиля
+// @Description Меняет email. Смена email требует текущий пароль; новый адрес нужно подтвердить заново,
+// @Description до подтверждения денежные операции недоступны. На прежний адрес отправляется уведомление
+// @Tags users
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param input body models.UpdateProfileRequest true "Новые значения полей"
+// @Success 200 {object} models.UserProfile
+// @Failure 400 {object} middleware.ValidationErrorResponse
+// @Failure 401 {object} middleware.ValidationErrorResponse
+// @Failure 429 {object} middleware.ValidationErrorResponse
+// @Failure 500 {object} middleware.ValidationErrorResponse
+// @Router /users/me [patch]
+func (h *Auth) UpdateProfile(c *gin.Context) {
+	userID, err := middleware.GetUserUUID(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	input, exists := c.Get("validatedInput")
+	if !exists {
+		c.Error(errs.ErrValidationNotWorking)
+		return
+	}
+
+	profile, err := h.svc.AuthService.UpdateProfile(c, userID, input.(models.UpdateProfileRequest))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, profile)
+}
+
+// ChangePassword godoc
+// @Summary Смена пароля
+// @Description Меняет пароль по текущему паролю. Все сессии завершаются: refresh-токены отзываются, текущий access-токен
+// @Description вносится в denylist, в ответе — токены новой сессии. Неверный текущий пароль считается неудачным входом
+// @Tags users
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param input body models.ChangePasswordRequest true "Текущий и новый пароль"
+// @Success 200 {object} models.LoginSuccessResponse
+// @Failure 400 {object} middleware.ValidationErrorResponse
+// @Failure 401 {object} middleware.ValidationErrorResponse
+// @Failure 429 {object} middleware.ValidationErrorResponse
+// @Failure 500 {object} middleware.ValidationErrorResponse
+// @Router /users/me/password [post]
+func (h *Auth) ChangePassword(c *gin.Context) {
+	claims, err := middleware.GetClaims(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	input, exists := c.Get("validatedInput")
+	if !exists {
+		c.Error(errs.ErrValidationNotWorking)
+		return
+	}
+
+	tokens, err := h.svc.AuthService.ChangePassword(c, claims, input.(models.ChangePasswordRequest))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, tokens)
+}
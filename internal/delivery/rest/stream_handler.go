@@ -0,0 +1,99 @@
+package rest
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+
+	config "gw-currency-wallet/internal/config"
+	"gw-currency-wallet/internal/delivery/middleware"
+	"gw-currency-wallet/internal/service"
+	"gw-currency-wallet/internal/storage/models"
+	"gw-currency-wallet/internal/stream"
+)
+
+type Stream struct {
+	svc    *service.Service
+	hub    *stream.Hub
+	cfg    *config.StreamConfig
+	logger *logrus.Logger
+}
+
+func NewStreamHandler(svc *service.Service, hub *stream.Hub, cfg *config.StreamConfig, logger *logrus.Logger) *Stream {
+	return &Stream{
+		svc:    svc,
+		hub:    hub,
+		cfg:    cfg,
+		logger: logger,
+	}
+}
+
+// Stream godoc
+// @Summary Поток изменений баланса и курсов
+// @Description Server-Sent Events. Сразу после подключения приходят текущие balance и rates, дальше — их изменения.
+// @Description События: balance (как в /wallet/balance), rates (как в /exchange/rates), heartbeat (раз в stream.heartbeat_interval).
+// @Description EventSource в браузере не передаёт заголовки, поэтому токен можно указать в параметре access_token
+// @Tags stream
+// @Produce text/event-stream
+// @Security BearerAuth
+// @Param access_token query string false "Access-токен, если нельзя передать заголовок Authorization"
+// @Success 200 {string} string "Поток событий"
+// @Failure 401 {object} middleware.ValidationErrorResponse
+// @Failure 500 {object} middleware.ValidationErrorResponse
+// @Router /stream [get]
+func (s *Stream) Stream(c *gin.Context) {
+	userID, err := middleware.GetUserUUID(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	// Подписываемся до чтения текущего состояния, чтобы не пропустить изменения между ними
+	sub := s.hub.Subscribe(userID)
+	defer s.hub.Unsubscribe(sub)
+
+	balance, err := s.svc.WalletService.GetBalance(c, userID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	// Поток живёт дольше server.write_timeout. Если дедлайн снять нельзя,
+	// соединение закроется по таймауту и EventSource переподключится
+	_ = http.NewResponseController(c.Writer).SetWriteDeadline(time.Time{})
+
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no") // Отключает буферизацию в nginx
+	c.Status(http.StatusOK)
+
+	c.SSEvent(models.StreamEventBalance, models.GetBalanceResponse{Balance: balance})
+	// Без курсов поток всё равно полезен, они придут со следующим обновлением
+	if rates, err := s.svc.ExchangeService.GetRates(c); err == nil {
+		c.SSEvent(models.StreamEventRates, models.ExchangeRatesResponse{Rates: rates.Rates, AsOf: rates.AsOf, Stale: rates.Stale})
+	} else {
+		s.logger.WithContext(c).Warnf("⚠️ Stream started without rates: %v", err)
+	}
+	c.Writer.Flush()
+
+	heartbeat := time.NewTicker(s.cfg.HeartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case event, ok := <-sub.Events():
+			// Хаб закрыл подписку: сервер останавливается или клиент не успевал читать
+			if !ok {
+				return
+			}
+			c.SSEvent(event.Name, event.Data)
+		case now := <-heartbeat.C:
+			c.SSEvent(models.StreamEventHeartbeat, models.StreamHeartbeat{Time: now.Unix()})
+		}
+		c.Writer.Flush()
+	}
+}
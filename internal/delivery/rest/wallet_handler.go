@@ -13,6 +13,9 @@ import (
 	"gw-currency-wallet/internal/storage/models/validate"
 )
 
+// MFACodeHeader код из приложения-аутентификатора для вывода и перевода крупных сумм
+const MFACodeHeader = "X-MFA-Code"
+
 type Wallet struct {
 	svc      *service.Service
 	validate *validate.Validator
@@ -35,6 +38,7 @@ func NewWalletHandler(svc *service.Service, validate *validate.Validator) *Walle
 // @Success 200 {object} models.GetBalanceResponse
 // @Failure 400 {object} middleware.ValidationErrorResponse
 // @Failure 401 {object} middleware.ValidationErrorResponse
+// @Failure 429 {object} middleware.ValidationErrorResponse
 // @Failure 500 {object} middleware.ValidationErrorResponse
 // @Router /wallet/balance [get]
 func (w *Wallet) GetBalance(c *gin.Context) {
@@ -58,15 +62,18 @@ func (w *Wallet) GetBalance(c *gin.Context) {
 
 // Deposit godoc
 // @Summary Пополнить баланс
-// @Description Пополняет баланс пользователя на указанную сумму в указанной валюте
+// @Description Пополняет баланс пользователя на указанную сумму в указанной валюте. До подтверждения email отклоняется с 403
 // @Tags wallet
 // @Accept json
 // @Produce json
 // @Security BearerAuth
 // @Param input body models.WalletTransaction true "Данные для пополнения"
+// @Param Idempotency-Key header string false "Ключ идемпотентности для безопасного повтора запроса"
 // @Success 200 {object} models.WalletOperationsResponse
 // @Failure 400 {object} middleware.ValidationErrorResponse
 // @Failure 401 {object} middleware.ValidationErrorResponse
+// @Failure 403 {object} middleware.ValidationErrorResponse
+// @Failure 429 {object} middleware.ValidationErrorResponse
 // @Failure 500 {object} middleware.ValidationErrorResponse
 // @Router /wallet/deposit [post]
 func (w *Wallet) Deposit(c *gin.Context) {
@@ -99,15 +106,21 @@ func (w *Wallet) Deposit(c *gin.Context) {
 
 // Withdraw godoc
 // @Summary Снять средства
-// @Description Списывает указанную сумму в указанной валюте с баланса пользователя
+// @Description Списывает указанную сумму в указанной валюте с баланса пользователя.
+// @Description Если у пользователя включена 2FA, вывод от auth.mfa.withdraw_thresholds требует свежий код в заголовке X-MFA-Code.
+// @Description До подтверждения email отклоняется с 403
 // @Tags wallet
 // @Accept json
 // @Produce json
 // @Security BearerAuth
 // @Param input body models.WalletTransaction true "Данные для снятия средств"
+// @Param Idempotency-Key header string false "Ключ идемпотентности для безопасного повтора запроса"
+// @Param X-MFA-Code header string false "Код из приложения-аутентификатора для вывода крупной суммы"
 // @Success 200 {object} models.WalletOperationsResponse
 // @Failure 400 {object} middleware.ValidationErrorResponse
 // @Failure 401 {object} middleware.ValidationErrorResponse
+// @Failure 403 {object} middleware.ValidationErrorResponse
+// @Failure 429 {object} middleware.ValidationErrorResponse
 // @Failure 500 {object} middleware.ValidationErrorResponse
 // @Router /wallet/withdraw [post]
 func (w *Wallet) Withdraw(c *gin.Context) {
@@ -125,7 +138,14 @@ func (w *Wallet) Withdraw(c *gin.Context) {
 
 	userInput := input.(models.WalletTransaction)
 
-	balance, err := w.svc.WalletService.Withdraw(c, userID, userInput.Currency, decimal.NewFromFloat(userInput.Amount))
+	amount := decimal.NewFromFloat(userInput.Amount)
+
+	if err := w.svc.AuthService.VerifyWithdrawal(c, userID, userInput.Currency, amount, c.GetHeader(MFACodeHeader)); err != nil {
+		c.Error(err)
+		return
+	}
+
+	balance, err := w.svc.WalletService.Withdraw(c, userID, userInput.Currency, amount)
 	if err != nil {
 		c.Error(err)
 		return
@@ -138,3 +158,102 @@ func (w *Wallet) Withdraw(c *gin.Context) {
 
 	c.JSON(http.StatusOK, successResponse)
 }
+
+// Transfer godoc
+// @Summary Перевод другому пользователю
+// @Description Переводит указанную сумму в указанной валюте пользователю с заданным username или email.
+// @Description Перевод уводит средства так же, как вывод, поэтому от auth.mfa.withdraw_thresholds у пользователя с 2FA требует код в X-MFA-Code.
+// @Description До подтверждения email отправителя отклоняется с 403
+// @Tags wallet
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param input body models.TransferRequest true "Данные для перевода"
+// @Param Idempotency-Key header string false "Ключ идемпотентности для безопасного повтора запроса"
+// @Param X-MFA-Code header string false "Код из приложения-аутентификатора для перевода крупной суммы"
+// @Success 200 {object} models.WalletOperationsResponse
+// @Failure 400 {object} middleware.ValidationErrorResponse
+// @Failure 401 {object} middleware.ValidationErrorResponse
+// @Failure 403 {object} middleware.ValidationErrorResponse
+// @Failure 404 {object} middleware.ValidationErrorResponse
+// @Failure 429 {object} middleware.ValidationErrorResponse
+// @Failure 500 {object} middleware.ValidationErrorResponse
+// @Router /wallet/transfer [post]
+func (w *Wallet) Transfer(c *gin.Context) {
+	userID, err := middleware.GetUserUUID(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	input, exists := c.Get("validatedInput")
+	if !exists {
+		c.Error(errs.ErrValidationNotWorking)
+		return
+	}
+
+	userInput := input.(models.TransferRequest)
+
+	amount := decimal.NewFromFloat(userInput.Amount)
+
+	if err := w.svc.AuthService.VerifyWithdrawal(c, userID, userInput.Currency, amount, c.GetHeader(MFACodeHeader)); err != nil {
+		c.Error(err)
+		return
+	}
+
+	balance, err := w.svc.WalletService.Transfer(c, userID, userInput.Recipient, userInput.Currency, amount)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	successResponse := models.WalletOperationsResponse{
+		Message: "Transfer successful",
+		Balance: balance,
+	}
+
+	c.JSON(http.StatusOK, successResponse)
+}
+
+// GetTransactions godoc
+// @Summary История операций
+// @Description Возвращает операции пользователя (новые первыми) с курсорной пагинацией и фильтрами
+// @Tags wallet
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param currency query string false "Валюта операции, например USD"
+// @Param type query string false "Тип операции" Enums(deposit, withdraw, exchange, transfer, adjustment)
+// @Param from query string false "Начало периода (RFC3339), включительно"
+// @Param to query string false "Конец периода (RFC3339), не включительно"
+// @Param limit query int false "Размер страницы (1-100, по умолчанию 20)"
+// @Param cursor query string false "Курсор следующей страницы из next_cursor"
+// @Success 200 {object} models.TransactionsResponse
+// @Failure 400 {object} middleware.ValidationErrorResponse
+// @Failure 401 {object} middleware.ValidationErrorResponse
+// @Failure 429 {object} middleware.ValidationErrorResponse
+// @Failure 500 {object} middleware.ValidationErrorResponse
+// @Router /wallet/transactions [get]
+func (w *Wallet) GetTransactions(c *gin.Context) {
+	userID, err := middleware.GetUserUUID(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	input, exists := c.Get("validatedInput")
+	if !exists {
+		c.Error(errs.ErrValidationNotWorking)
+		return
+	}
+
+	query := input.(models.TransactionsQuery)
+
+	response, err := w.svc.WalletService.GetTransactions(c, userID, query)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
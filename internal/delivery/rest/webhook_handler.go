@@ -0,0 +1,213 @@
+package rest
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"gw-currency-wallet/internal/delivery/middleware"
+	"gw-currency-wallet/internal/errs"
+	"gw-currency-wallet/internal/service"
+	"gw-currency-wallet/internal/storage/models"
+)
+
+type Webhook struct {
+	svc *service.Service
+}
+
+func NewWebhookHandler(svc *service.Service) *Webhook {
+	return &Webhook{svc: svc}
+}
+
+// CreateWebhook godoc
+// @Summary Создать подписку на события
+// @Description Регистрирует URL для доставки событий кошелька. Секрет для проверки подписи X-Webhook-Signature
+// @Description возвращается только в этом ответе. URL, разрешающийся в адрес внутренней сети, отклоняется с 400
+// @Tags webhooks
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param input body models.WebhookRequest true "URL и типы событий"
+// @Success 201 {object} models.WebhookSubscription
+// @Failure 400 {object} middleware.ValidationErrorResponse
+// @Failure 401 {object} middleware.ValidationErrorResponse
+// @Failure 429 {object} middleware.ValidationErrorResponse
+// @Failure 500 {object} middleware.ValidationErrorResponse
+// @Router /webhooks [post]
+func (h *Webhook) CreateWebhook(c *gin.Context) {
+	userID, err := middleware.GetUserUUID(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	input, exists := c.Get("validatedInput")
+	if !exists {
+		c.Error(errs.ErrValidationNotWorking)
+		return
+	}
+
+	sub, err := h.svc.WebhookService.CreateWebhook(c, userID, input.(models.WebhookRequest))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, sub)
+}
+
+// GetWebhooks godoc
+// @Summary Список подписок
+// @Description Возвращает подписки пользователя без секретов
+// @Tags webhooks
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} models.WebhooksResponse
+// @Failure 401 {object} middleware.ValidationErrorResponse
+// @Failure 429 {object} middleware.ValidationErrorResponse
+// @Failure 500 {object} middleware.ValidationErrorResponse
+// @Router /webhooks [get]
+func (h *Webhook) GetWebhooks(c *gin.Context) {
+	userID, err := middleware.GetUserUUID(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	subs, err := h.svc.WebhookService.GetWebhooks(c, userID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.WebhooksResponse{Webhooks: subs})
+}
+
+// DeleteWebhook godoc
+// @Summary Удалить подписку
+// @Description Удаляет подписку вместе с историей доставок
+// @Tags webhooks
+// @Security BearerAuth
+// @Param id path string true "ID подписки"
+// @Success 204
+// @Failure 400 {object} middleware.ValidationErrorResponse
+// @Failure 401 {object} middleware.ValidationErrorResponse
+// @Failure 404 {object} middleware.ValidationErrorResponse
+// @Failure 429 {object} middleware.ValidationErrorResponse
+// @Failure 500 {object} middleware.ValidationErrorResponse
+// @Router /webhooks/{id} [delete]
+func (h *Webhook) DeleteWebhook(c *gin.Context) {
+	userID, err := middleware.GetUserUUID(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	webhookID, err := uuidParam(c, "id", errs.ErrInvalidWebhookId)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	if err := h.svc.WebhookService.DeleteWebhook(c, userID, webhookID); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// GetDeliveries godoc
+// @Summary История доставок
+// @Description Возвращает последние 50 доставок по подписке
+// @Tags webhooks
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "ID подписки"
+// @Param status query string false "Статус доставки" Enums(pending, delivered, dead)
+// @Success 200 {object} models.WebhookDeliveriesResponse
+// @Failure 400 {object} middleware.ValidationErrorResponse
+// @Failure 401 {object} middleware.ValidationErrorResponse
+// @Failure 404 {object} middleware.ValidationErrorResponse
+// @Failure 429 {object} middleware.ValidationErrorResponse
+// @Failure 500 {object} middleware.ValidationErrorResponse
+// @Router /webhooks/{id}/deliveries [get]
+func (h *Webhook) GetDeliveries(c *gin.Context) {
+	userID, err := middleware.GetUserUUID(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	webhookID, err := uuidParam(c, "id", errs.ErrInvalidWebhookId)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	input, exists := c.Get("validatedInput")
+	if !exists {
+		c.Error(errs.ErrValidationNotWorking)
+		return
+	}
+
+	deliveries, err := h.svc.WebhookService.GetDeliveries(c, userID, webhookID, input.(models.WebhookDeliveriesQuery).Status)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.WebhookDeliveriesResponse{Deliveries: deliveries})
+}
+
+// ReplayDelivery godoc
+// @Summary Повторить доставку
+// @Description Возвращает доставку в очередь с обнулённым счётчиком попыток, в том числе из статуса dead
+// @Tags webhooks
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "ID подписки"
+// @Param delivery_id path string true "ID доставки"
+// @Success 202 {object} models.WebhookDelivery
+// @Failure 400 {object} middleware.ValidationErrorResponse
+// @Failure 401 {object} middleware.ValidationErrorResponse
+// @Failure 404 {object} middleware.ValidationErrorResponse
+// @Failure 429 {object} middleware.ValidationErrorResponse
+// @Failure 500 {object} middleware.ValidationErrorResponse
+// @Router /webhooks/{id}/deliveries/{delivery_id}/replay [post]
+func (h *Webhook) ReplayDelivery(c *gin.Context) {
+	userID, err := middleware.GetUserUUID(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	webhookID, err := uuidParam(c, "id", errs.ErrInvalidWebhookId)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	deliveryID, err := uuidParam(c, "delivery_id", errs.ErrWebhookDeliveryNotFound)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	delivery, err := h.svc.WebhookService.ReplayDelivery(c, userID, webhookID, deliveryID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, delivery)
+}
+
+// uuidParam извлекает UUID из пути запроса, при ошибке разбора возвращает invalidErr
+func uuidParam(c *gin.Context, name string, invalidErr error) (uuid.UUID, error) {
+	id, err := uuid.Parse(c.Param(name))
+	if err != nil {
+		return uuid.Nil, invalidErr
+	}
+	return id, nil
+}
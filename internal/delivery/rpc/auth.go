@@ -0,0 +1,90 @@
+package rpc
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"path"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	config "gw-currency-wallet/internal/config"
+)
+
+type callerKey struct{}
+
+// allMethods разрешает клиенту все методы
+const allMethods = "*"
+
+// serviceClient сервис-клиент из конфига с разобранным хэшем токена
+type serviceClient struct {
+	name      string
+	tokenHash []byte
+	methods   map[string]bool
+}
+
+// CallerFromContext имя сервиса, выполнившего вызов
+func CallerFromContext(c context.Context) string {
+	name, _ := c.Value(callerKey{}).(string)
+	return name
+}
+
+// AuthInterceptor проверяет токен сервиса из метаданных authorization: Bearer <token>
+// и то, что сервису разрешён вызываемый метод. Без списка methods клиенту запрещены все методы,
+// доступ ко всем даёт только явный "*"
+func AuthInterceptor(clients []config.GRPCClientConfig) (grpc.UnaryServerInterceptor, error) {
+	known := make([]serviceClient, 0, len(clients))
+	for _, client := range clients {
+		hash, err := hex.DecodeString(client.TokenHash)
+		if err != nil || len(hash) != sha256.Size {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid token_hash for client %q", client.Name)
+		}
+		methods := make(map[string]bool, len(client.Methods))
+		for _, method := range client.Methods {
+			methods[method] = true
+		}
+		known = append(known, serviceClient{name: client.Name, tokenHash: hash, methods: methods})
+	}
+
+	return func(c context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		client, ok := authenticate(c, known)
+		if !ok {
+			return nil, status.Error(codes.Unauthenticated, "invalid service credentials")
+		}
+
+		method := path.Base(info.FullMethod)
+		if !client.methods[allMethods] && !client.methods[method] {
+			return nil, status.Errorf(codes.PermissionDenied, "client %q is not allowed to call %s", client.name, method)
+		}
+
+		return handler(context.WithValue(c, callerKey{}, client.name), req)
+	}, nil
+}
+
+// authenticate сравнивает хэш токена со всеми клиентами за постоянное время
+func authenticate(c context.Context, clients []serviceClient) (serviceClient, bool) {
+	md, _ := metadata.FromIncomingContext(c)
+	values := md.Get("authorization")
+	if len(values) == 0 {
+		return serviceClient{}, false
+	}
+	token, found := strings.CutPrefix(values[0], "Bearer ")
+	if !found || token == "" {
+		return serviceClient{}, false
+	}
+
+	hash := sha256.Sum256([]byte(token))
+	var matched serviceClient
+	ok := false
+	for _, client := range clients {
+		if subtle.ConstantTimeCompare(hash[:], client.tokenHash) == 1 {
+			matched, ok = client, true
+		}
+	}
+	return matched, ok
+}
@@ -0,0 +1,49 @@
+package rpc
+
+import (
+	"context"
+	"errors"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"gw-currency-wallet/internal/errs"
+)
+
+// toStatus переводит ошибки сервисов в коды gRPC так же, как ErrorHandler переводит их в HTTP-статусы
+func (s *WalletServer) toStatus(c context.Context, err error) error {
+	var validationErrs validator.ValidationErrors
+	switch {
+	case errors.As(err, &validationErrs):
+		return status.Error(codes.InvalidArgument, validationErrs.Error())
+	case errors.Is(err, errs.ErrInvalidUserId),
+		errors.Is(err, errs.ErrInvalidAmount),
+		errors.Is(err, errs.ErrUnsupportedCurrency),
+		errors.Is(err, errs.ErrAmountTooSmall),
+		errors.Is(err, errs.ErrInvalidCursor),
+		errors.Is(err, errs.ErrInvalidDateRange):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, errs.ErrWalletNotFound),
+		errors.Is(err, errs.ErrUserNotFound),
+		errors.Is(err, errs.ErrAccountNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, errs.ErrInsufficientFunds),
+		errors.Is(err, errs.ErrAccountFrozen),
+		errors.Is(err, errs.ErrEmailNotVerified):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case errors.Is(err, errs.ErrRateTooOld):
+		return status.Error(codes.Unavailable, err.Error())
+	}
+
+	// Ошибки gw-exchanger передаём как есть
+	if st, ok := status.FromError(err); ok {
+		return st.Err()
+	}
+
+	s.logger.WithContext(c).WithFields(logrus.Fields{
+		"error": err.Error(),
+	}).Error("❌ Unhandled gRPC error")
+	return status.Error(codes.Internal, "internal error")
+}
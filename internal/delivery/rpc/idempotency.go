@@ -0,0 +1,148 @@
+package rpc
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+const (
+	IdempotencyKeyMetadata = "idempotency-key"
+
+	idempotencyMaxKeyLength = 255
+	// Пока вызов выполняется, ключ блокируется на короткое время,
+	// чтобы упавший посреди обработки инстанс не держал его весь TTL
+	idempotencyLockTTL = time.Minute
+)
+
+// idempotencyRecord сохранённый в Redis ответ. Пустой Response — вызов ещё выполняется
+type idempotencyRecord struct {
+	Fingerprint string `json:"fingerprint"`
+	Response    []byte `json:"response,omitempty"`
+}
+
+// IdempotencyInterceptor повторяет для methods поведение IdempotencyMiddleware REST API:
+// вызов с уже использованным idempotency-key получает сохранённый ответ, с другим запросом — InvalidArgument.
+// Ключи разделены по сервисам-клиентам
+func IdempotencyInterceptor(cache *redis.Client, ttl time.Duration, methods ...string) grpc.UnaryServerInterceptor {
+	guarded := make(map[string]bool, len(methods))
+	for _, method := range methods {
+		guarded[method] = true
+	}
+
+	return func(c context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		md, _ := metadata.FromIncomingContext(c)
+		keys := md.Get(IdempotencyKeyMetadata)
+		if !guarded[info.FullMethod] || len(keys) == 0 {
+			return handler(c, req)
+		}
+		key := keys[0]
+		if key == "" || len(key) > idempotencyMaxKeyLength {
+			return nil, status.Error(codes.InvalidArgument, "invalid idempotency key")
+		}
+
+		fingerprint, err := callFingerprint(info.FullMethod, req)
+		if err != nil {
+			return nil, status.Error(codes.Internal, "failed to fingerprint request")
+		}
+
+		// Результат сохраняем даже если клиент уже отключился
+		ctx := context.WithoutCancel(c)
+		cacheKey := "idempotency:grpc:" + CallerFromContext(c) + ":" + key
+
+		pending, _ := json.Marshal(idempotencyRecord{Fingerprint: fingerprint})
+		acquired, err := cache.SetNX(ctx, cacheKey, pending, idempotencyLockTTL).Result()
+		if err != nil {
+			return nil, status.Error(codes.Unavailable, "idempotency storage is unavailable")
+		}
+		if !acquired {
+			return replayIdempotentCall(ctx, cache, cacheKey, fingerprint)
+		}
+
+		resp, err := handler(c, req)
+		if err != nil {
+			// Ошибки не кэшируем: операция не выполнена, клиент может повторить вызов с тем же ключом
+			cache.Del(ctx, cacheKey)
+			return nil, err
+		}
+
+		message, ok := resp.(proto.Message)
+		if !ok {
+			return resp, nil
+		}
+		packed, err := anypb.New(message)
+		if err == nil {
+			var data []byte
+			if data, err = proto.Marshal(packed); err == nil {
+				record, _ := json.Marshal(idempotencyRecord{Fingerprint: fingerprint, Response: data})
+				err = cache.Set(ctx, cacheKey, record, ttl).Err()
+			}
+		}
+		if err != nil {
+			cache.Del(ctx, cacheKey)
+		}
+		return resp, nil
+	}
+}
+
+// replayIdempotentCall отдаёт сохранённый ответ для уже использованного ключа
+func replayIdempotentCall(c context.Context, cache *redis.Client, cacheKey, fingerprint string) (interface{}, error) {
+	data, err := cache.Get(c, cacheKey).Bytes()
+	if errors.Is(err, redis.Nil) {
+		// Ключ истёк между SETNX и GET — первый вызов ещё не завершён
+		return nil, status.Error(codes.Aborted, "request with this idempotency key is in progress")
+	}
+	if err != nil {
+		return nil, status.Error(codes.Unavailable, "idempotency storage is unavailable")
+	}
+
+	var record idempotencyRecord
+	if err := json.Unmarshal(data, &record); err != nil {
+		return nil, status.Error(codes.Internal, "corrupted idempotency record")
+	}
+
+	switch {
+	case record.Fingerprint != fingerprint:
+		return nil, status.Error(codes.InvalidArgument, "idempotency key was already used with a different request")
+	case len(record.Response) == 0:
+		return nil, status.Error(codes.Aborted, "request with this idempotency key is in progress")
+	}
+
+	var packed anypb.Any
+	if err := proto.Unmarshal(record.Response, &packed); err != nil {
+		return nil, status.Error(codes.Internal, "corrupted idempotency record")
+	}
+	resp, err := packed.UnmarshalNew()
+	if err != nil {
+		return nil, status.Error(codes.Internal, "corrupted idempotency record")
+	}
+	grpc.SetHeader(c, metadata.Pairs("idempotent-replayed", "true"))
+	return resp, nil
+}
+
+// callFingerprint отпечаток вызова по методу и детерминированной сериализации запроса
+func callFingerprint(method string, req interface{}) (string, error) {
+	message, ok := req.(proto.Message)
+	if !ok {
+		return "", errors.New("request is not a proto message")
+	}
+	data, err := proto.MarshalOptions{Deterministic: true}.Marshal(message)
+	if err != nil {
+		return "", err
+	}
+	hash := sha256.New()
+	hash.Write([]byte(method + "\n"))
+	hash.Write(data)
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
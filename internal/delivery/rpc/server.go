@@ -0,0 +1,108 @@
+package rpc
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"runtime/debug"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/status"
+
+	config "gw-currency-wallet/internal/config"
+	"gw-currency-wallet/internal/service"
+	"gw-currency-wallet/internal/storage/models/validate"
+	walletpb "gw-currency-wallet/pkg/api/wallet"
+)
+
+// NewServer собирает gRPC-сервер кошелька: TLS, трассировку, перехват паник,
+// авторизацию сервисов и идемпотентность денежных операций
+func NewServer(
+	cfg *config.WalletGRPCConfig,
+	svc *service.Service,
+	v *validate.Validator,
+	cache *redis.Client,
+	logger *logrus.Logger,
+) (*grpc.Server, error) {
+	auth, err := AuthInterceptor(cfg.Clients)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := []grpc.ServerOption{
+		grpc.StatsHandler(otelgrpc.NewServerHandler()),
+		grpc.ChainUnaryInterceptor(
+			RecoveryInterceptor(logger),
+			auth,
+			IdempotencyInterceptor(cache, cfg.IdempotencyTTL,
+				walletpb.WalletService_Deposit_FullMethodName,
+				walletpb.WalletService_Withdraw_FullMethodName,
+				walletpb.WalletService_Exchange_FullMethodName,
+			),
+		),
+	}
+
+	if cfg.CertFile != "" {
+		creds, err := serverCredentials(cfg)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, grpc.Creds(creds))
+	} else {
+		logger.Warn("⚠️ Wallet gRPC API is not encrypted, set wallet_grpc.cert_file in production")
+	}
+
+	server := grpc.NewServer(opts...)
+	walletpb.RegisterWalletServiceServer(server, NewWalletServer(svc, v, logger))
+	return server, nil
+}
+
+// RecoveryInterceptor превращает панику обработчика в codes.Internal
+func RecoveryInterceptor(logger *logrus.Logger) grpc.UnaryServerInterceptor {
+	return func(c context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp interface{}, err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				logger.WithContext(c).WithFields(logrus.Fields{
+					"method":      info.FullMethod,
+					"panic":       fmt.Sprintf("%v", r),
+					"stack_trace": string(debug.Stack()),
+				}).Error("🔥 Panic recovered")
+				err = status.Error(codes.Internal, "internal error")
+			}
+		}()
+		return handler(c, req)
+	}
+}
+
+// serverCredentials TLS сервера; с client_ca_file клиенты обязаны предъявить сертификат от этого CA
+func serverCredentials(cfg *config.WalletGRPCConfig) (credentials.TransportCredentials, error) {
+	cert, err := tls.LoadX509KeyPair(cfg.CertFile, cfg.KeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load wallet gRPC certificate: %w", err)
+	}
+	tlsCfg := &tls.Config{
+		MinVersion:   tls.VersionTLS12,
+		Certificates: []tls.Certificate{cert},
+	}
+
+	if cfg.ClientCAFile != "" {
+		pem, err := os.ReadFile(cfg.ClientCAFile)
+		if err != nil {
+			return nil, err
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in %s", cfg.ClientCAFile)
+		}
+		tlsCfg.ClientCAs = pool
+		tlsCfg.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+	return credentials.NewTLS(tlsCfg), nil
+}
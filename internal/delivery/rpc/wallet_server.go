@@ -0,0 +1,222 @@
+package rpc
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/sirupsen/logrus"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"gw-currency-wallet/internal/errs"
+	"gw-currency-wallet/internal/service"
+	"gw-currency-wallet/internal/storage/models"
+	"gw-currency-wallet/internal/storage/models/validate"
+	walletpb "gw-currency-wallet/pkg/api/wallet"
+)
+
+// WalletServer реализация gRPC API кошелька поверх тех же сервисов, что и REST API
+type WalletServer struct {
+	walletpb.UnimplementedWalletServiceServer
+
+	svc      *service.Service
+	validate *validate.Validator
+	logger   *logrus.Logger
+}
+
+func NewWalletServer(svc *service.Service, validate *validate.Validator, logger *logrus.Logger) *WalletServer {
+	return &WalletServer{
+		svc:      svc,
+		validate: validate,
+		logger:   logger,
+	}
+}
+
+// operationInput проверка полей операции теми же правилами, что и в REST API
+type operationInput struct {
+	Currency string `validate:"required,len=3,alpha"`
+}
+
+type exchangeInput struct {
+	FromCurrency string `validate:"required,len=3,alpha"`
+	ToCurrency   string `validate:"required,len=3,alpha"`
+}
+
+func (s *WalletServer) GetBalance(c context.Context, req *walletpb.GetBalanceRequest) (*walletpb.BalanceResponse, error) {
+	userID, err := parseUserID(req.GetUserId())
+	if err != nil {
+		return nil, s.toStatus(c, err)
+	}
+
+	balance, err := s.svc.WalletService.GetBalance(c, userID)
+	if err != nil {
+		return nil, s.toStatus(c, err)
+	}
+	return &walletpb.BalanceResponse{Balance: balanceToProto(balance)}, nil
+}
+
+func (s *WalletServer) Deposit(c context.Context, req *walletpb.DepositRequest) (*walletpb.BalanceResponse, error) {
+	userID, amount, err := s.parseOperation(req.GetUserId(), req.GetCurrency(), req.GetAmount())
+	if err != nil {
+		return nil, s.toStatus(c, err)
+	}
+
+	balance, err := s.svc.WalletService.Deposit(c, userID, req.GetCurrency(), amount)
+	if err != nil {
+		return nil, s.toStatus(c, err)
+	}
+	return &walletpb.BalanceResponse{Balance: balanceToProto(balance)}, nil
+}
+
+func (s *WalletServer) Withdraw(c context.Context, req *walletpb.WithdrawRequest) (*walletpb.BalanceResponse, error) {
+	userID, amount, err := s.parseOperation(req.GetUserId(), req.GetCurrency(), req.GetAmount())
+	if err != nil {
+		return nil, s.toStatus(c, err)
+	}
+
+	balance, err := s.svc.WalletService.Withdraw(c, userID, req.GetCurrency(), amount)
+	if err != nil {
+		return nil, s.toStatus(c, err)
+	}
+	return &walletpb.BalanceResponse{Balance: balanceToProto(balance)}, nil
+}
+
+// Exchange рассчитывает обмен по текущему курсу с комиссией и проводит его, как POST /exchange без quote_id
+func (s *WalletServer) Exchange(c context.Context, req *walletpb.ExchangeRequest) (*walletpb.ExchangeResponse, error) {
+	userID, err := parseUserID(req.GetUserId())
+	if err != nil {
+		return nil, s.toStatus(c, err)
+	}
+	if err := s.validate.ValidateStruct(&exchangeInput{FromCurrency: req.GetFromCurrency(), ToCurrency: req.GetToCurrency()}); err != nil {
+		return nil, s.toStatus(c, err)
+	}
+	amount, err := parseAmount(req.GetAmount())
+	if err != nil {
+		return nil, s.toStatus(c, err)
+	}
+
+	quote, err := s.svc.PriceExchange(c, userID, req.GetFromCurrency(), req.GetToCurrency(), amount)
+	if err != nil {
+		return nil, s.toStatus(c, err)
+	}
+
+	balance, err := s.svc.ExchangeService.ExchangeCurrency(
+		c, userID, quote.FromCurrency, quote.ToCurrency, quote.FromAmount, quote.ToAmount, quote.Fee,
+	)
+	if err != nil {
+		return nil, s.toStatus(c, err)
+	}
+
+	return &walletpb.ExchangeResponse{
+		Rate:            quote.Rate.String(),
+		ExchangedAmount: quote.ToAmount.String(),
+		Fee:             quote.Fee.String(),
+		FeeCurrency:     quote.FeeCurrency,
+		Balance:         balanceToProto(balance),
+	}, nil
+}
+
+func (s *WalletServer) ListTransactions(c context.Context, req *walletpb.ListTransactionsRequest) (*walletpb.ListTransactionsResponse, error) {
+	userID, err := parseUserID(req.GetUserId())
+	if err != nil {
+		return nil, s.toStatus(c, err)
+	}
+
+	query := models.TransactionsQuery{
+		Currency: req.GetCurrency(),
+		Type:     req.GetType(),
+		Limit:    int(req.GetLimit()),
+		Cursor:   req.GetCursor(),
+	}
+	if req.GetFrom() != nil {
+		query.From = req.GetFrom().AsTime()
+	}
+	if req.GetTo() != nil {
+		query.To = req.GetTo().AsTime()
+	}
+	if err := s.validate.ValidateStruct(&query); err != nil {
+		return nil, s.toStatus(c, err)
+	}
+
+	page, err := s.svc.WalletService.GetTransactions(c, userID, query)
+	if err != nil {
+		return nil, s.toStatus(c, err)
+	}
+
+	response := &walletpb.ListTransactionsResponse{
+		Transactions: make([]*walletpb.Transaction, 0, len(page.Transactions)),
+		NextCursor:   page.NextCursor,
+	}
+	for _, tx := range page.Transactions {
+		response.Transactions = append(response.Transactions, transactionToProto(tx))
+	}
+	return response, nil
+}
+
+// parseOperation разбирает общие поля пополнения и списания
+func (s *WalletServer) parseOperation(rawUserID, currency, rawAmount string) (uuid.UUID, decimal.Decimal, error) {
+	userID, err := parseUserID(rawUserID)
+	if err != nil {
+		return uuid.Nil, decimal.Decimal{}, err
+	}
+	if err := s.validate.ValidateStruct(&operationInput{Currency: currency}); err != nil {
+		return uuid.Nil, decimal.Decimal{}, err
+	}
+	amount, err := parseAmount(rawAmount)
+	if err != nil {
+		return uuid.Nil, decimal.Decimal{}, err
+	}
+	return userID, amount, nil
+}
+
+func parseUserID(raw string) (uuid.UUID, error) {
+	userID, err := uuid.Parse(raw)
+	if err != nil {
+		return uuid.Nil, errs.ErrInvalidUserId
+	}
+	return userID, nil
+}
+
+// parseAmount сумма передаётся строкой, чтобы не терять точность
+func parseAmount(raw string) (decimal.Decimal, error) {
+	amount, err := decimal.NewFromString(raw)
+	if err != nil || !amount.IsPositive() {
+		return decimal.Decimal{}, errs.ErrInvalidAmount
+	}
+	return amount, nil
+}
+
+func balanceToProto(balance models.WalletResponse) map[string]string {
+	result := make(map[string]string, len(balance))
+	for currency, amount := range balance {
+		result[currency] = amount.String()
+	}
+	return result
+}
+
+func transactionToProto(tx models.Transaction) *walletpb.Transaction {
+	result := &walletpb.Transaction{
+		Id:           tx.ID.String(),
+		Type:         tx.Type,
+		Currency:     tx.Currency,
+		Amount:       tx.Amount.String(),
+		BalanceAfter: tx.BalanceAfter.String(),
+		CreatedAt:    timestamppb.New(tx.CreatedAt),
+	}
+	if tx.CounterCurrency != nil {
+		result.CounterCurrency = *tx.CounterCurrency
+	}
+	if tx.CounterAmount != nil {
+		result.CounterAmount = tx.CounterAmount.String()
+	}
+	if tx.CounterpartyUserID != nil {
+		result.CounterpartyUserId = tx.CounterpartyUserID.String()
+	}
+	if tx.Fee != nil {
+		result.Fee = tx.Fee.String()
+	}
+	if tx.FeeCurrency != nil {
+		result.FeeCurrency = *tx.FeeCurrency
+	}
+	return result
+}
@@ -1,6 +1,10 @@
 package errs
 
-import "github.com/pkg/errors"
+import (
+	"time"
+
+	"github.com/pkg/errors"
+)
 
 // auth
 var (
@@ -9,8 +13,56 @@ var (
 	ErrInvalidPassword    = errors.New("invalid password")
 	ErrInvalidCredentials = errors.New("invalid credentials")
 	ErrUserNotFound       = errors.New("user not found")
+	ErrInvalidRefresh     = errors.New("invalid or expired refresh token")
+	ErrTokenRevoked       = errors.New("token has been revoked")
+	ErrForbidden          = errors.New("insufficient permissions")
+	ErrLoginLocked        = errors.New("too many failed login attempts")
+	ErrWrongPassword      = errors.New("current password is incorrect")
+)
+
+// mfa
+var (
+	ErrMFARequired         = errors.New("two-factor code required")
+	ErrInvalidMFACode      = errors.New("invalid two-factor code")
+	ErrInvalidMFAChallenge = errors.New("invalid or expired mfa challenge")
+	ErrMFAAlreadyEnabled   = errors.New("two-factor authentication already enabled")
+	ErrMFANotEnrolled      = errors.New("two-factor authentication is not enrolled")
+	ErrMFALocked           = errors.New("too many invalid two-factor codes")
+)
+
+// email
+var (
+	ErrInvalidEmailToken    = errors.New("invalid or expired token")
+	ErrEmailNotVerified     = errors.New("email is not verified")
+	ErrEmailAlreadyVerified = errors.New("email is already verified")
 )
 
+// LoginLockedError вход временно заблокирован до Until; errors.Is(err, ErrLoginLocked) == true
+type LoginLockedError struct {
+	Until time.Time
+}
+
+func (e *LoginLockedError) Error() string {
+	return ErrLoginLocked.Error() + ", locked until " + e.Until.UTC().Format(time.RFC3339)
+}
+
+func (e *LoginLockedError) Unwrap() error {
+	return ErrLoginLocked
+}
+
+// MFALockedError проверка кодов 2FA для операций заблокирована до Until; errors.Is(err, ErrMFALocked) == true
+type MFALockedError struct {
+	Until time.Time
+}
+
+func (e *MFALockedError) Error() string {
+	return ErrMFALocked.Error() + ", locked until " + e.Until.UTC().Format(time.RFC3339)
+}
+
+func (e *MFALockedError) Unwrap() error {
+	return ErrMFALocked
+}
+
 // wallets
 var (
 	ErrWalletNotFound      = errors.New("wallet not found")
@@ -18,6 +70,47 @@ var (
 	ErrInsufficientFunds   = errors.New("insufficient funds")
 	ErrInvalidAmount       = errors.New("invalid amount, must be greater than zero")
 	ErrUnsupportedCurrency = errors.New("unsupported currency")
+	ErrSelfTransfer        = errors.New("cannot transfer to yourself")
+	ErrRecipientNotFound   = errors.New("recipient not found")
+	ErrInvalidCursor       = errors.New("invalid pagination cursor")
+	ErrInvalidDateRange    = errors.New("invalid date range")
+	ErrAccountFrozen       = errors.New("account is frozen")
+	ErrRecipientFrozen     = errors.New("recipient account is frozen")
+)
+
+// admin
+var (
+	ErrAccountNotFound = errors.New("account not found")
+	ErrSelfAdjustment  = errors.New("cannot adjust your own balance")
+)
+
+// exchange
+var (
+	ErrQuoteExpired     = errors.New("exchange quote expired or not found")
+	ErrQuoteAlreadyUsed = errors.New("exchange quote already used")
+	ErrQuoteMismatch    = errors.New("exchange request does not match quote")
+	ErrAmountTooSmall   = errors.New("amount is too small to cover exchange fee")
+	ErrRateTooOld       = errors.New("exchange rate is outdated")
+)
+
+// webhooks
+var (
+	ErrInvalidWebhookId        = errors.New("invalid webhook id")
+	ErrWebhookNotFound         = errors.New("webhook not found")
+	ErrWebhookDeliveryNotFound = errors.New("webhook delivery not found")
+	ErrWebhookURLNotAllowed    = errors.New("webhook url must resolve to a public address")
+)
+
+// idempotency
+var (
+	ErrInvalidIdempotencyKey        = errors.New("invalid idempotency key")
+	ErrIdempotencyKeyReused         = errors.New("idempotency key reused with different request")
+	ErrIdempotencyRequestInProgress = errors.New("request with this idempotency key is in progress")
+)
+
+// rate limiting
+var (
+	ErrTooManyRequests = errors.New("too many requests")
 )
 
 var (
@@ -0,0 +1,99 @@
+package health
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"gw-currency-wallet/internal/storage/models"
+)
+
+// Check проверка одной зависимости; nil — зависимость доступна
+type Check func(ctx context.Context) error
+
+// Info состояние, которое показывается в /readyz, но не влияет на готовность
+type Info func() string
+
+type namedCheck struct {
+	name  string
+	check Check
+}
+
+type namedInfo struct {
+	name string
+	info Info
+}
+
+// Checker собирает проверки зависимостей для readiness и хранит признак остановки сервера
+type Checker struct {
+	timeout  time.Duration
+	checks   []namedCheck
+	infos    []namedInfo
+	draining atomic.Bool
+}
+
+func NewChecker(timeout time.Duration) *Checker {
+	return &Checker{timeout: timeout}
+}
+
+// Add регистрирует проверку зависимости под именем, которое попадёт в ответ /readyz
+func (h *Checker) Add(name string, check Check) {
+	h.checks = append(h.checks, namedCheck{name: name, check: check})
+}
+
+// AddInfo регистрирует состояние под именем, которое попадёт в поле info ответа /readyz
+func (h *Checker) AddInfo(name string, info Info) {
+	h.infos = append(h.infos, namedInfo{name: name, info: info})
+}
+
+// SetDraining помечает сервер останавливающимся: readiness больше не проходит,
+// чтобы балансировщик перестал направлять новые запросы
+func (h *Checker) SetDraining() {
+	h.draining.Store(true)
+}
+
+// Ready выполняет все проверки параллельно, каждую не дольше timeout.
+// Возвращает false, если сервер останавливается или хотя бы одна зависимость недоступна
+func (h *Checker) Ready(ctx context.Context) (models.HealthResponse, bool) {
+	ctx, cancel := context.WithTimeout(ctx, h.timeout)
+	defer cancel()
+
+	statuses := make([]models.DependencyStatus, len(h.checks))
+	var wg sync.WaitGroup
+	for i, check := range h.checks {
+		wg.Add(1)
+		go func(i int, check Check) {
+			defer wg.Done()
+			statuses[i] = models.DependencyStatus{Status: models.HealthStatusOK}
+			if err := check(ctx); err != nil {
+				statuses[i] = models.DependencyStatus{Status: models.HealthStatusFail, Error: err.Error()}
+			}
+		}(i, check.check)
+	}
+	wg.Wait()
+
+	response := models.HealthResponse{
+		Status: models.HealthStatusOK,
+		Checks: make(map[string]models.DependencyStatus, len(h.checks)),
+	}
+	if len(h.infos) > 0 {
+		response.Info = make(map[string]string, len(h.infos))
+		for _, info := range h.infos {
+			response.Info[info.name] = info.info()
+		}
+	}
+	ready := true
+	for i, check := range h.checks {
+		response.Checks[check.name] = statuses[i]
+		if statuses[i].Status != models.HealthStatusOK {
+			response.Status = models.HealthStatusFail
+			ready = false
+		}
+	}
+	if h.draining.Load() {
+		response.Status = models.HealthStatusDraining
+		ready = false
+	}
+	return response, ready
+}
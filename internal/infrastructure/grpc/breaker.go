@@ -0,0 +1,133 @@
+package grpc
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"gw-currency-wallet/internal/metrics"
+)
+
+// Состояния circuit breaker
+const (
+	BreakerClosed   = "closed"
+	BreakerOpen     = "open"
+	BreakerHalfOpen = "half_open"
+)
+
+// CircuitBreaker размыкается после threshold подряд неудачных вызовов и cooldown отклоняет вызовы сразу.
+// Затем пропускает один пробный вызов: успех замыкает цепь, ошибка снова размыкает её
+type CircuitBreaker struct {
+	name      string
+	threshold int
+	cooldown  time.Duration
+
+	mu       sync.Mutex
+	state    string
+	failures int
+	openedAt time.Time
+	probing  bool
+}
+
+func NewCircuitBreaker(name string, threshold int, cooldown time.Duration) *CircuitBreaker {
+	b := &CircuitBreaker{name: name, threshold: threshold, cooldown: cooldown}
+	b.setState(BreakerClosed)
+	return b
+}
+
+// State текущее состояние; открытая цепь по истечении cooldown показывается как half_open
+func (b *CircuitBreaker) State() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.state == BreakerOpen && time.Since(b.openedAt) >= b.cooldown {
+		return BreakerHalfOpen
+	}
+	return b.state
+}
+
+// allow решает, можно ли выполнить вызов. В half_open одновременно допускается только один пробный вызов
+func (b *CircuitBreaker) allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case BreakerOpen:
+		if time.Since(b.openedAt) < b.cooldown {
+			return false
+		}
+		b.setState(BreakerHalfOpen)
+		b.probing = true
+		return true
+	case BreakerHalfOpen:
+		if b.probing {
+			return false
+		}
+		b.probing = true
+		return true
+	default:
+		return true
+	}
+}
+
+// record учитывает результат вызова
+func (b *CircuitBreaker) record(failed bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.probing = false
+	if !failed {
+		b.failures = 0
+		b.setState(BreakerClosed)
+		return
+	}
+
+	b.failures++
+	if b.state == BreakerHalfOpen || b.failures >= b.threshold {
+		b.openedAt = time.Now()
+		b.setState(BreakerOpen)
+	}
+}
+
+// release отменяет пробный вызов, результат которого не говорит о состоянии сервиса
+func (b *CircuitBreaker) release() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.probing = false
+}
+
+func (b *CircuitBreaker) setState(state string) {
+	b.state = state
+	metrics.SetCircuitState(b.name, state)
+}
+
+// Interceptor отклоняет вызовы с codes.Unavailable, пока цепь разомкнута
+func (b *CircuitBreaker) Interceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		if !b.allow() {
+			return status.Errorf(codes.Unavailable, "%s circuit breaker is open", b.name)
+		}
+
+		err := invoker(ctx, method, req, reply, cc, opts...)
+		if status.Code(err) == codes.Canceled {
+			// Клиент отменил запрос сам — о доступности сервиса это ничего не говорит
+			b.release()
+			return err
+		}
+		b.record(isServiceFailure(err))
+		return err
+	}
+}
+
+// isServiceFailure ошибки, которые говорят о недоступности сервиса, а не о некорректном запросе
+func isServiceFailure(err error) bool {
+	switch status.Code(err) {
+	case codes.Unavailable, codes.DeadlineExceeded, codes.ResourceExhausted, codes.Internal, codes.Unknown:
+		return true
+	default:
+		return false
+	}
+}
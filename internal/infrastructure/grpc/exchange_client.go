@@ -2,31 +2,58 @@ package grpc
 
 import (
 	"context"
+	"fmt"
 
 	exchange "github.com/AndrewTarev/proto-repo/gen/exchange"
 	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/connectivity"
 	"google.golang.org/grpc/credentials/insecure"
+
+	config "gw-currency-wallet/internal/config"
+	"gw-currency-wallet/internal/metrics"
 )
 
 type ExchangeClient struct {
-	client exchange.ExchangeServiceClient
-	conn   *grpc.ClientConn
-	logger *logrus.Logger
+	client  exchange.ExchangeServiceClient
+	conn    *grpc.ClientConn
+	breaker *CircuitBreaker
+	logger  *logrus.Logger
 }
 
-func NewUserServiceClient(grpcAddr string, logger *logrus.Logger) *ExchangeClient {
-	// creds := credentials.NewTLS(&tls.Config{
-	// 	InsecureSkipVerify: true,
-	// })
-	logger.Debugf("connecting to gRPC server: %s", grpcAddr)
-	conn, err := grpc.NewClient(grpcAddr, grpc.WithTransportCredentials(insecure.NewCredentials())) // TODO Для продакш, подставь grpc.WithTransportCredentials(creds)
+func NewUserServiceClient(cfg *config.ExchangeService, logger *logrus.Logger) *ExchangeClient {
+	creds := insecure.NewCredentials()
+	if cfg.TLS.Enabled {
+		var err error
+		if creds, err = NewTLSCredentials(&cfg.TLS, logger); err != nil {
+			logger.Fatalf("Failed to load exchanger TLS certificates: %v", err)
+		}
+	} else {
+		logger.Warn("⚠️ Connection to gw-exchanger is not encrypted, enable exchange_service_grpc.tls in production")
+	}
+
+	breaker := NewCircuitBreaker("exchanger", cfg.BreakerThreshold, cfg.BreakerCooldown)
+
+	logger.Debugf("connecting to gRPC server: %s", cfg.Addr)
+	conn, err := grpc.NewClient(cfg.Addr,
+		grpc.WithTransportCredentials(creds),
+		// Метрики учитывают вызов целиком вместе с повторами; breaker видит один результат на вызов,
+		// а дедлайн ограничивает каждую попытку отдельно
+		grpc.WithChainUnaryInterceptor(
+			metrics.GRPCClientInterceptor(),
+			breaker.Interceptor(),
+			RetryInterceptor(cfg.MaxRetries, cfg.RetryBackoff, cfg.RetryMaxBackoff),
+			DeadlineInterceptor(cfg.Timeout),
+		),
+		grpc.WithStatsHandler(otelgrpc.NewClientHandler()),
+	)
 	if err != nil {
 		logger.Fatalf("Failed to connect to UserService: %v", err)
 	}
 
 	client := exchange.NewExchangeServiceClient(conn)
-	return &ExchangeClient{client: client, conn: conn, logger: logger}
+	return &ExchangeClient{client: client, conn: conn, breaker: breaker, logger: logger}
 }
 
 // GetExchangeRates Получить все курсы обмена валют
@@ -51,3 +78,28 @@ func (e *ExchangeClient) GetExchangeRateForCurrency(c context.Context, fromCurre
 	}
 	return resp, nil
 }
+
+// Ping проверяет состояние соединения с gw-exchanger.
+// Соединение устанавливается лениво, поэтому из Idle запускается подключение и ожидается его результат
+func (e *ExchangeClient) Ping(c context.Context) error {
+	for {
+		state := e.conn.GetState()
+		switch state {
+		case connectivity.Ready:
+			return nil
+		case connectivity.Idle:
+			e.conn.Connect()
+		case connectivity.TransientFailure, connectivity.Shutdown:
+			return fmt.Errorf("grpc connection is %s", state)
+		}
+		if !e.conn.WaitForStateChange(c, state) {
+			return fmt.Errorf("grpc connection is %s: %w", state, c.Err())
+		}
+	}
+}
+
+// BreakerState состояние цепи к gw-exchanger для /readyz. Разомкнутая цепь не делает сервис неготовым:
+// курсы отдаются из кэша, а доступность gw-exchanger проверяет Ping
+func (e *ExchangeClient) BreakerState() string {
+	return e.breaker.State()
+}
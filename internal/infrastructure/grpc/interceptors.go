@@ -0,0 +1,69 @@
+package grpc
+
+import (
+	"context"
+	"math/rand/v2"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"gw-currency-wallet/internal/metrics"
+)
+
+// DeadlineInterceptor ограничивает каждую попытку вызова timeout, если у контекста нет более раннего дедлайна
+func DeadlineInterceptor(timeout time.Duration) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		ctx, cancel := context.WithTimeout(ctx, timeout)
+		defer cancel()
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+}
+
+// RetryInterceptor повторяет вызов до maxRetries раз при временных ошибках с экспоненциальной паузой и джиттером.
+// Подходит только для идемпотентных методов — у gw-exchanger все методы читающие
+func RetryInterceptor(maxRetries int, baseBackoff, maxBackoff time.Duration) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		err := invoker(ctx, method, req, reply, cc, opts...)
+		for attempt := 1; attempt <= maxRetries && isRetryable(ctx, err); attempt++ {
+			timer := time.NewTimer(jitteredBackoff(attempt, baseBackoff, maxBackoff))
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return err
+			case <-timer.C:
+			}
+
+			metrics.ObserveGRPCRetry(method)
+			err = invoker(ctx, method, req, reply, cc, opts...)
+		}
+		return err
+	}
+}
+
+// isRetryable временные ошибки; DeadlineExceeded повторяется, только если истёк таймаут попытки, а не запроса
+func isRetryable(ctx context.Context, err error) bool {
+	if err == nil || ctx.Err() != nil {
+		return false
+	}
+	switch status.Code(err) {
+	case codes.Unavailable, codes.ResourceExhausted, codes.DeadlineExceeded:
+		return true
+	default:
+		return false
+	}
+}
+
+// jitteredBackoff пауза base * 2^(attempt-1), ограниченная max, со случайным разбросом в её второй половине
+func jitteredBackoff(attempt int, base, max time.Duration) time.Duration {
+	delay := base
+	for i := 1; i < attempt && delay < max; i++ {
+		delay *= 2
+	}
+	delay = min(delay, max)
+	if delay <= 0 {
+		return 0
+	}
+	return delay/2 + rand.N(delay/2+1)
+}
@@ -0,0 +1,132 @@
+package grpc
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc/credentials"
+
+	config "gw-currency-wallet/internal/config"
+)
+
+// reloadingCredentials TLS-учётные данные, которые перечитывают CA и клиентский сертификат при изменении файлов.
+// Проверка выполняется при каждом TLS-рукопожатии, поэтому новые соединения сразу используют
+// обновлённые сертификаты, а открытые соединения продолжают работать со старыми
+type reloadingCredentials struct {
+	cfg    *config.ExchangeTLSConfig
+	logger *logrus.Logger
+
+	mu      sync.Mutex
+	tlsCfg  *tls.Config
+	modTime map[string]time.Time
+}
+
+// NewTLSCredentials загружает сертификаты из cfg. Без CertFile выполняется обычный TLS, с ним — mTLS
+func NewTLSCredentials(cfg *config.ExchangeTLSConfig, logger *logrus.Logger) (credentials.TransportCredentials, error) {
+	r := &reloadingCredentials{cfg: cfg, logger: logger}
+	tlsCfg, modTime, err := r.load()
+	if err != nil {
+		return nil, err
+	}
+	r.tlsCfg, r.modTime = tlsCfg, modTime
+	return r, nil
+}
+
+// current возвращает актуальную конфигурацию; если файлы изменились, но не читаются, остаётся прежняя
+func (r *reloadingCredentials) current() *tls.Config {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if !r.changed() {
+		return r.tlsCfg
+	}
+	tlsCfg, modTime, err := r.load()
+	if err != nil {
+		r.logger.Errorf("❌ Failed to reload exchanger TLS certificates, keeping previous: %v", err)
+		return r.tlsCfg
+	}
+	r.tlsCfg, r.modTime = tlsCfg, modTime
+	r.logger.Info("Exchanger TLS certificates reloaded")
+	return r.tlsCfg
+}
+
+func (r *reloadingCredentials) changed() bool {
+	for path, modTime := range r.modTime {
+		info, err := os.Stat(path)
+		if err != nil || !info.ModTime().Equal(modTime) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *reloadingCredentials) load() (*tls.Config, map[string]time.Time, error) {
+	tlsCfg := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		ServerName: r.cfg.ServerName,
+	}
+	modTime := make(map[string]time.Time)
+
+	// Время изменения запоминаем до чтения, чтобы не пропустить запись, случившуюся во время загрузки
+	for _, path := range []string{r.cfg.CAFile, r.cfg.CertFile, r.cfg.KeyFile} {
+		if path == "" {
+			continue
+		}
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, nil, err
+		}
+		modTime[path] = info.ModTime()
+	}
+
+	if r.cfg.CAFile != "" {
+		pem, err := os.ReadFile(r.cfg.CAFile)
+		if err != nil {
+			return nil, nil, err
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, nil, fmt.Errorf("no certificates found in %s", r.cfg.CAFile)
+		}
+		tlsCfg.RootCAs = pool
+	}
+
+	if r.cfg.CertFile != "" {
+		cert, err := tls.LoadX509KeyPair(r.cfg.CertFile, r.cfg.KeyFile)
+		if err != nil {
+			return nil, nil, err
+		}
+		tlsCfg.Certificates = []tls.Certificate{cert}
+	}
+
+	return tlsCfg, modTime, nil
+}
+
+func (r *reloadingCredentials) ClientHandshake(ctx context.Context, authority string, conn net.Conn) (net.Conn, credentials.AuthInfo, error) {
+	return credentials.NewTLS(r.current()).ClientHandshake(ctx, authority, conn)
+}
+
+func (r *reloadingCredentials) ServerHandshake(conn net.Conn) (net.Conn, credentials.AuthInfo, error) {
+	return nil, nil, fmt.Errorf("reloadingCredentials supports client handshakes only")
+}
+
+func (r *reloadingCredentials) Info() credentials.ProtocolInfo {
+	return credentials.NewTLS(r.current()).Info()
+}
+
+// Clone возвращает те же учётные данные: перезагрузка сертификатов общая для всех соединений
+func (r *reloadingCredentials) Clone() credentials.TransportCredentials {
+	return r
+}
+
+// OverrideServerName устарел в gRPC; имя сервера задаётся через server_name в конфиге
+func (r *reloadingCredentials) OverrideServerName(string) error {
+	return nil
+}
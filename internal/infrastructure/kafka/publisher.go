@@ -0,0 +1,58 @@
+package kafka
+
+import (
+	"encoding/json"
+
+	"github.com/IBM/sarama"
+
+	config "gw-currency-wallet/internal/config"
+	"gw-currency-wallet/internal/storage/models"
+)
+
+type Publisher struct {
+	producer sarama.SyncProducer
+	topic    string
+}
+
+// NewProducerConfig настройки producer для доставки без потерь: подтверждение от всех реплик,
+// идемпотентная запись и одна заявка в полёте, чтобы повторы не меняли порядок сообщений
+func NewProducerConfig(cfg *config.OutboxConfig) *sarama.Config {
+	saramaCfg := sarama.NewConfig()
+	saramaCfg.Version = sarama.V2_1_0_0
+	saramaCfg.Producer.RequiredAcks = sarama.WaitForAll
+	saramaCfg.Producer.Idempotent = true
+	saramaCfg.Producer.Retry.Max = cfg.MaxRetries
+	saramaCfg.Producer.Partitioner = sarama.NewHashPartitioner
+	saramaCfg.Producer.Return.Successes = true
+	saramaCfg.Producer.Return.Errors = true
+	saramaCfg.Net.MaxOpenRequests = 1
+	return saramaCfg
+}
+
+func NewSyncProducer(cfg *config.OutboxConfig) (sarama.SyncProducer, error) {
+	return sarama.NewSyncProducer(cfg.Brokers, NewProducerConfig(cfg))
+}
+
+func NewPublisher(producer sarama.SyncProducer, topic string) *Publisher {
+	return &Publisher{producer: producer, topic: topic}
+}
+
+// Publish отправляет событие и ждёт подтверждения. Ключ сообщения — aggregate_id,
+// поэтому события одного пользователя попадают в одну партицию
+func (p *Publisher) Publish(event models.OutboxEvent) error {
+	value, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	_, _, err = p.producer.SendMessage(&sarama.ProducerMessage{
+		Topic: p.topic,
+		Key:   sarama.StringEncoder(event.AggregateID.String()),
+		Value: sarama.ByteEncoder(value),
+		Headers: []sarama.RecordHeader{
+			{Key: []byte("event_id"), Value: []byte(event.EventID.String())},
+			{Key: []byte("event_type"), Value: []byte(event.Type)},
+		},
+	})
+	return err
+}
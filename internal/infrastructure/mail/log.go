@@ -0,0 +1,54 @@
+package mail
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	config "gw-currency-wallet/internal/config"
+)
+
+// LogMailer для локальной разработки: письма не отправляются, а пишутся в лог и файлы .eml в mail.dir
+type LogMailer struct {
+	from   string
+	dir    string
+	logger *logrus.Logger
+}
+
+func NewLogMailer(cfg *config.MailConfig, logger *logrus.Logger) *LogMailer {
+	return &LogMailer{from: cfg.From, dir: cfg.Dir, logger: logger}
+}
+
+func (m *LogMailer) Send(c context.Context, msg Message) error {
+	if err := checkHeaders(msg); err != nil {
+		return err
+	}
+	entry := m.logger.WithContext(c).WithFields(logrus.Fields{
+		"to":      msg.To,
+		"subject": msg.Subject,
+	})
+
+	if m.dir != "" {
+		if err := os.MkdirAll(m.dir, 0o755); err != nil {
+			return err
+		}
+		suffix := make([]byte, 4)
+		if _, err := rand.Read(suffix); err != nil {
+			return err
+		}
+		now := time.Now()
+		path := filepath.Join(m.dir, now.UTC().Format("20060102T150405.000000000")+"-"+hex.EncodeToString(suffix)+".eml")
+		if err := os.WriteFile(path, compose(m.from, msg, now), 0o600); err != nil {
+			return err
+		}
+		entry = entry.WithField("file", path)
+	}
+
+	entry.Infof("📧 Mail is not sent (log driver):\n%s", msg.Body)
+	return nil
+}
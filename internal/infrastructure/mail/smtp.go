@@ -0,0 +1,96 @@
+package mail
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	netmail "net/mail"
+	"net/smtp"
+	"strconv"
+	"time"
+
+	config "gw-currency-wallet/internal/config"
+)
+
+// implicitTLSPort порт SMTPS, на котором TLS включается до приветствия сервера
+const implicitTLSPort = 465
+
+type SMTPMailer struct {
+	cfg *config.MailConfig
+}
+
+func NewSMTPMailer(cfg *config.MailConfig) *SMTPMailer {
+	return &SMTPMailer{cfg: cfg}
+}
+
+// Send открывает соединение на каждое письмо; всё общение с сервером ограничено mail.timeout
+func (m *SMTPMailer) Send(c context.Context, msg Message) error {
+	if err := checkHeaders(msg); err != nil {
+		return err
+	}
+	from, err := netmail.ParseAddress(m.cfg.From)
+	if err != nil {
+		return fmt.Errorf("invalid mail.from: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(c, m.cfg.Timeout)
+	defer cancel()
+
+	host := m.cfg.SMTP.Host
+	conn, err := m.dial(ctx, net.JoinHostPort(host, strconv.Itoa(m.cfg.SMTP.Port)))
+	if err != nil {
+		return fmt.Errorf("failed to connect to smtp server: %w", err)
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	client, err := smtp.NewClient(conn, host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer client.Close()
+
+	if m.cfg.SMTP.Port != implicitTLSPort {
+		if ok, _ := client.Extension("STARTTLS"); ok {
+			if err := client.StartTLS(&tls.Config{ServerName: host}); err != nil {
+				return fmt.Errorf("smtp starttls: %w", err)
+			}
+		}
+	}
+	// PlainAuth сам откажется передавать пароль без TLS на удалённый сервер
+	if m.cfg.SMTP.Username != "" {
+		if err := client.Auth(smtp.PlainAuth("", m.cfg.SMTP.Username, m.cfg.SMTP.Password, host)); err != nil {
+			return fmt.Errorf("smtp auth: %w", err)
+		}
+	}
+
+	if err := client.Mail(from.Address); err != nil {
+		return err
+	}
+	if err := client.Rcpt(msg.To); err != nil {
+		return err
+	}
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(compose(m.cfg.From, msg, time.Now())); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return client.Quit()
+}
+
+func (m *SMTPMailer) dial(ctx context.Context, addr string) (net.Conn, error) {
+	dialer := &net.Dialer{}
+	if m.cfg.SMTP.Port == implicitTLSPort {
+		tlsDialer := &tls.Dialer{NetDialer: dialer, Config: &tls.Config{ServerName: m.cfg.SMTP.Host}}
+		return tlsDialer.DialContext(ctx, "tcp", addr)
+	}
+	return dialer.DialContext(ctx, "tcp", addr)
+}
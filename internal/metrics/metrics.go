@@ -0,0 +1,163 @@
+package metrics
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/shopspring/decimal"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+const namespace = "wallet"
+
+// Результат обращения к кэшу. CacheStale — найдены устаревшие данные, которые отдаются до обновления
+const (
+	CacheHit   = "hit"
+	CacheMiss  = "miss"
+	CacheStale = "stale"
+)
+
+var (
+	httpRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "HTTP requests by route, method and status code.",
+	}, []string{"method", "route", "status"})
+
+	httpDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "HTTP request latency by route and method.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route"})
+
+	cacheRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "cache_requests_total",
+		Help:      "Redis cache lookups by cache name and result (hit, miss or stale).",
+	}, []string{"cache", "result"})
+
+	grpcDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "grpc_client_request_duration_seconds",
+		Help:      "Latency of outgoing gRPC calls by method.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method"})
+
+	grpcErrors = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "grpc_client_errors_total",
+		Help:      "Failed outgoing gRPC calls by method and status code.",
+	}, []string{"method", "code"})
+
+	grpcRetries = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "grpc_client_retries_total",
+		Help:      "Retried outgoing gRPC calls by method.",
+	}, []string{"method"})
+
+	circuitState = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "grpc_client_circuit_state",
+		Help:      "Circuit breaker state of a gRPC dependency: 0 closed, 1 half-open, 2 open.",
+	}, []string{"target"})
+
+	rateLimited = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "rate_limited_total",
+		Help:      "Requests rejected with 429 by route group.",
+	}, []string{"group"})
+
+	streamClients = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "stream_clients",
+		Help:      "Clients connected to the event stream on this replica.",
+	})
+
+	operations = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "operations_total",
+		Help:      "Successful wallet operations by type and currency.",
+	}, []string{"operation", "currency"})
+
+	operationVolume = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "operation_volume_total",
+		Help:      "Volume of successful wallet operations by type and currency. For exchanges the debited currency is used.",
+	}, []string{"operation", "currency"})
+)
+
+// HTTPMiddleware считает запросы и время ответа по шаблону маршрута.
+// Запросы к несуществующим маршрутам собираются под route="unmatched", чтобы не раздувать число серий
+func HTTPMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		httpRequests.WithLabelValues(c.Request.Method, route, strconv.Itoa(c.Writer.Status())).Inc()
+		httpDuration.WithLabelValues(c.Request.Method, route).Observe(time.Since(start).Seconds())
+	}
+}
+
+// GRPCClientInterceptor измеряет время и ошибки исходящих gRPC-вызовов
+func GRPCClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		start := time.Now()
+		err := invoker(ctx, method, req, reply, cc, opts...)
+		grpcDuration.WithLabelValues(method).Observe(time.Since(start).Seconds())
+		if err != nil {
+			grpcErrors.WithLabelValues(method, status.Code(err).String()).Inc()
+		}
+		return err
+	}
+}
+
+// ObserveGRPCRetry учитывает повтор исходящего gRPC-вызова
+func ObserveGRPCRetry(method string) {
+	grpcRetries.WithLabelValues(method).Inc()
+}
+
+// SetCircuitState публикует состояние circuit breaker: closed, half_open или open
+func SetCircuitState(target, state string) {
+	value := 0.0
+	switch state {
+	case "half_open":
+		value = 1
+	case "open":
+		value = 2
+	}
+	circuitState.WithLabelValues(target).Set(value)
+}
+
+// ObserveRateLimited учитывает запрос, отклонённый ограничением частоты
+func ObserveRateLimited(group string) {
+	rateLimited.WithLabelValues(group).Inc()
+}
+
+// SetStreamClients публикует число подключений к потоку событий
+func SetStreamClients(count int) {
+	streamClients.Set(float64(count))
+}
+
+// ObserveCache учитывает попадание или промах кэша
+func ObserveCache(cache, result string) {
+	cacheRequests.WithLabelValues(cache, result).Inc()
+}
+
+// ObserveOperation учитывает успешную операцию кошелька и её сумму
+func ObserveOperation(operation, currency string, amount decimal.Decimal) {
+	currency = strings.ToUpper(currency)
+	volume, _ := amount.Float64()
+	operations.WithLabelValues(operation, currency).Inc()
+	operationVolume.WithLabelValues(operation, currency).Add(volume)
+}
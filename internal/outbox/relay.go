@@ -0,0 +1,99 @@
+package outbox
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+
+	config "gw-currency-wallet/internal/config"
+	"gw-currency-wallet/internal/storage"
+	"gw-currency-wallet/internal/storage/models"
+)
+
+// Publisher отправляет событие во внешний брокер и возвращает ошибку, если доставка не подтверждена
+type Publisher interface {
+	Publish(event models.OutboxEvent) error
+}
+
+// Relay периодически забирает события из outbox и публикует их
+type Relay struct {
+	stor         storage.OutboxStorage
+	publisher    Publisher
+	logger       *logrus.Logger
+	pollInterval time.Duration
+	maxBackoff   time.Duration
+	batchSize    int
+}
+
+func NewRelay(stor storage.OutboxStorage, publisher Publisher, cfg *config.OutboxConfig, logger *logrus.Logger) *Relay {
+	return &Relay{
+		stor:         stor,
+		publisher:    publisher,
+		logger:       logger,
+		pollInterval: cfg.PollInterval,
+		maxBackoff:   cfg.MaxBackoff,
+		batchSize:    cfg.BatchSize,
+	}
+}
+
+// Run публикует события до отмены ctx. После ошибок пауза между проходами растёт вдвое до maxBackoff
+func (r *Relay) Run(ctx context.Context) {
+	r.logger.Info("Outbox relay started")
+	delay := r.pollInterval
+	for {
+		result, err := r.Flush(ctx)
+		switch {
+		case err != nil:
+			r.logger.Errorf("Outbox relay: %v", err)
+			delay = min(delay*2, r.maxBackoff)
+		case len(result.Failed) > 0:
+			delay = min(delay*2, r.maxBackoff)
+		case len(result.Published) == r.batchSize:
+			// Очередь не разобрана, продолжаем без паузы
+			continue
+		default:
+			delay = r.pollInterval
+		}
+
+		select {
+		case <-ctx.Done():
+			r.logger.Info("Outbox relay stopped")
+			return
+		case <-time.After(delay):
+		}
+	}
+}
+
+// Flush выполняет один проход по очереди
+func (r *Relay) Flush(ctx context.Context) (models.OutboxResult, error) {
+	var result models.OutboxResult
+	_, err := r.stor.ProcessOutbox(ctx, r.batchSize, func(events []models.OutboxEvent) models.OutboxResult {
+		result = r.publishBatch(events)
+		return result
+	})
+	return result, err
+}
+
+// publishBatch публикует события по порядку. После ошибки остальные события того же ключа
+// в этом проходе пропускаются, чтобы потребители не получили их раньше неотправленного
+func (r *Relay) publishBatch(events []models.OutboxEvent) models.OutboxResult {
+	result := models.OutboxResult{Failed: make(map[int64]error)}
+	blocked := make(map[uuid.UUID]bool)
+
+	for _, event := range events {
+		if blocked[event.AggregateID] {
+			continue
+		}
+		if err := r.publisher.Publish(event); err != nil {
+			r.logger.Warnf("Failed to publish outbox event %s (%s), attempt %d: %v", event.EventID, event.Type, event.Attempts+1, err)
+			result.Failed[event.ID] = fmt.Errorf("publish: %w", err)
+			blocked[event.AggregateID] = true
+			continue
+		}
+		result.Published = append(result.Published, event.ID)
+	}
+	return result
+}
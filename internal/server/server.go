@@ -3,6 +3,7 @@ package server
 import (
 	"context"
 	"errors"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
@@ -11,11 +12,24 @@ import (
 	"time"
 
 	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
 
 	config "gw-currency-wallet/internal/config"
+	"gw-currency-wallet/internal/health"
 )
 
-func SetupAndRunServer(cfg *config.ServerConfig, handler http.Handler, logger *logrus.Logger) {
+// SetupAndRunServer запускает HTTP-сервер и, если передан grpcServer, gRPC API кошелька на grpcAddr.
+// По сигналу завершения оба сервера останавливаются вместе после периода draining.
+// onShutdown вызываются в начале остановки HTTP-сервера, например чтобы закрыть долгие соединения
+func SetupAndRunServer(
+	cfg *config.ServerConfig,
+	handler http.Handler,
+	grpcServer *grpc.Server,
+	grpcAddr string,
+	checker *health.Checker,
+	logger *logrus.Logger,
+	onShutdown ...func(),
+) {
 	// Создаем HTTP-сервер
 	server := &http.Server{
 		Addr:           cfg.Host + ":" + strconv.Itoa(cfg.Port),
@@ -24,6 +38,9 @@ func SetupAndRunServer(cfg *config.ServerConfig, handler http.Handler, logger *l
 		WriteTimeout:   cfg.WriteTimeout,
 		MaxHeaderBytes: cfg.MaxHeaderBytes,
 	}
+	for _, f := range onShutdown {
+		server.RegisterOnShutdown(f)
+	}
 
 	// Канал для обработки сигналов завершения работы
 	stop := make(chan os.Signal, 1)
@@ -37,14 +54,51 @@ func SetupAndRunServer(cfg *config.ServerConfig, handler http.Handler, logger *l
 		}
 	}()
 
+	if grpcServer != nil {
+		listener, err := net.Listen("tcp", grpcAddr)
+		if err != nil {
+			logger.Fatalf("Could not listen for gRPC: %v", err)
+		}
+		go func() {
+			logger.Infof("Starting gRPC server on %s", listener.Addr())
+			if err := grpcServer.Serve(listener); err != nil {
+				logger.Fatalf("Could not start gRPC server: %v", err)
+			}
+		}()
+	}
+
 	// Ожидаем сигнал завершения
 	<-stop
 	logger.Info("Shutting down server...")
 
+	// Сначала перестаём проходить readiness и продолжаем обслуживать запросы,
+	// пока балансировщик не уберёт под из ротации
+	checker.SetDraining()
+	if cfg.DrainDelay > 0 {
+		logger.Infof("Draining for %s before shutdown", cfg.DrainDelay)
+		time.Sleep(cfg.DrainDelay)
+	}
+
 	// Контекст для завершения активных соединений
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
+	if grpcServer != nil {
+		stopped := make(chan struct{})
+		go func() {
+			grpcServer.GracefulStop()
+			close(stopped)
+		}()
+		defer func() {
+			// Незавершённые вызовы прерываются по истечении общего таймаута
+			select {
+			case <-stopped:
+			case <-ctx.Done():
+				grpcServer.Stop()
+			}
+		}()
+	}
+
 	if err := server.Shutdown(ctx); err != nil {
 		logger.Fatalf("Server forced to shutdown: %v", err)
 	}
@@ -0,0 +1,92 @@
+package service
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/sirupsen/logrus"
+
+	"gw-currency-wallet/internal/errs"
+	"gw-currency-wallet/internal/storage"
+	"gw-currency-wallet/internal/storage/models"
+	"gw-currency-wallet/internal/stream"
+)
+
+// adminSearchLimit максимальное число пользователей в результатах поиска
+const adminSearchLimit = 50
+
+// Admin Сервис операций сотрудников поддержки
+type Admin struct {
+	stor   *storage.Storage
+	logger *logrus.Logger
+	stream *stream.Publisher
+}
+
+func NewAdminService(stor *storage.Storage, logger *logrus.Logger, publisher *stream.Publisher) *Admin {
+	return &Admin{
+		stor:   stor,
+		logger: logger,
+		stream: publisher,
+	}
+}
+
+func (a *Admin) SearchUsers(c context.Context, query string) ([]models.AdminUser, error) {
+	return a.stor.AdminStorage.SearchUsers(c, query, adminSearchLimit)
+}
+
+func (a *Admin) GetUser(c context.Context, userID uuid.UUID) (models.AdminUser, error) {
+	return a.stor.AdminStorage.GetUser(c, userID)
+}
+
+// FreezeUser запрещает пользователю операции с кошельком
+func (a *Admin) FreezeUser(c context.Context, actorID, userID uuid.UUID, reason string) (models.AdminUser, error) {
+	user, err := a.stor.AdminStorage.SetFrozen(c, actorID, userID, true, reason)
+	if err != nil {
+		return models.AdminUser{}, err
+	}
+	a.logger.Infof("Account %s frozen by %s: %s", userID, actorID, reason)
+	return user, nil
+}
+
+func (a *Admin) UnfreezeUser(c context.Context, actorID, userID uuid.UUID) (models.AdminUser, error) {
+	user, err := a.stor.AdminStorage.SetFrozen(c, actorID, userID, false, "")
+	if err != nil {
+		return models.AdminUser{}, err
+	}
+	a.logger.Infof("Account %s unfrozen by %s", userID, actorID)
+	return user, nil
+}
+
+// UnlockUser снимает блокировку входа после неудачных попыток
+func (a *Admin) UnlockUser(c context.Context, actorID, userID uuid.UUID) (models.AdminUser, error) {
+	user, err := a.stor.AdminStorage.Unlock(c, actorID, userID)
+	if err != nil {
+		return models.AdminUser{}, err
+	}
+	a.logger.Infof("Login of %s unlocked by %s", userID, actorID)
+	return user, nil
+}
+
+// AdjustBalance ручная корректировка баланса с записью в журнал аудита.
+// Свой баланс администратор корректировать не может
+func (a *Admin) AdjustBalance(
+	c context.Context,
+	actorID uuid.UUID,
+	userID uuid.UUID,
+	currency string,
+	amount decimal.Decimal,
+	reason string,
+) (models.WalletResponse, error) {
+	if actorID == userID {
+		return models.WalletResponse{}, errs.ErrSelfAdjustment
+	}
+
+	result, err := a.stor.AdminStorage.AdjustBalance(c, actorID, userID, currency, amount, reason)
+	if err != nil {
+		return models.WalletResponse{}, err
+	}
+	a.logger.Infof("Balance of %s adjusted by %s: %s %s (%s)", userID, actorID, result.Amount, result.Currency, reason)
+	a.stream.BalanceChanged(c, userID)
+	return result.Balance, nil
+}
@@ -2,55 +2,329 @@ package service
 
 import (
 	"context"
+	"errors"
+	"strconv"
+	"time"
 
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
 	"github.com/sirupsen/logrus"
 
+	config "gw-currency-wallet/internal/config"
 	"gw-currency-wallet/internal/errs"
+	"gw-currency-wallet/internal/infrastructure/mail"
 	"gw-currency-wallet/internal/storage"
 	"gw-currency-wallet/internal/storage/models"
 	"gw-currency-wallet/internal/utils"
 )
 
 type Auth struct {
-	stor   *storage.Storage
-	logger *logrus.Logger
-	jwt    *utils.JWTManager
+	stor    *storage.Storage
+	logger  *logrus.Logger
+	jwt     *utils.JWTManager
+	cache   *redis.Client
+	lockout *config.LockoutConfig
+	mfa     *config.MFAConfig
+	cipher  *utils.SecretCipher
+	email   *config.EmailConfig
+	mailer  mail.Mailer
+	// Срок access-токена: столько живёт отметка об отзыве всех токенов пользователя
+	tokenTTL time.Duration
 }
 
-func NewAuthService(stor *storage.Storage, logger *logrus.Logger, jwtManager *utils.JWTManager) *Auth {
+func NewAuthService(
+	stor *storage.Storage,
+	logger *logrus.Logger,
+	jwtManager *utils.JWTManager,
+	cache *redis.Client,
+	authCfg *config.AuthConfig,
+	mailer mail.Mailer,
+) *Auth {
 	return &Auth{
-		stor:   stor,
-		logger: logger,
-		jwt:    jwtManager,
+		stor:    stor,
+		logger:  logger,
+		jwt:     jwtManager,
+		cache:   cache,
+		lockout: &authCfg.Lockout,
+		mfa:     &authCfg.MFA,
+		cipher:  utils.NewSecretCipher(authCfg.MFA.EncryptionKey),
+		email:   &authCfg.Email,
+		mailer:  mailer,
+
+		tokenTTL: authCfg.TokenTTl,
 	}
 }
 
+// Register создаёт пользователя и отправляет письмо со ссылкой подтверждения email.
+// Ошибка отправки письма регистрацию не отменяет: письмо можно запросить повторно
 func (a *Auth) Register(c context.Context, user models.UserRegister) error {
 	passwordHash, err := utils.HashPassword(user.Password)
 	if err != nil {
 		return err
 	}
 
-	err = a.stor.CreateUser(c, user.Username, user.Email, passwordHash)
+	userID, err := a.stor.CreateUser(c, user.Username, user.Email, passwordHash)
 	if err != nil {
 		return err
 	}
+	if err := a.sendVerification(c, userID, user.Email); err != nil {
+		a.logger.WithContext(c).Errorf("Failed to issue email verification for user %s: %v", userID, err)
+	}
 	return nil
 }
 
-func (a *Auth) Login(c context.Context, userInput *models.UserLogin) (string, error) {
+// Login проверяет пароль и выдаёт токены. Неудачные входы считаются по аккаунту и по IP клиента:
+// после lockout.max_failures подряд аккаунт блокируется, каждая следующая блокировка вдвое дольше,
+// после lockout.ip_max_failures за lockout.ip_window блокируются входы с IP. Все попытки пишутся в login_attempts.
+// Если включена 2FA, вместо токенов возвращается mfa_token для второго шага LoginMFA
+func (a *Auth) Login(c context.Context, userInput *models.UserLogin, ip string) (models.LoginSuccessResponse, error) {
+	attempt := models.LoginAttempt{Username: userInput.Username, IP: ip}
+
+	if until, err := a.ipLockedUntil(c, ip); err != nil {
+		return models.LoginSuccessResponse{}, err
+	} else if until != nil {
+		attempt.Result = models.LoginLocked
+		a.recordAttempt(c, attempt)
+		return models.LoginSuccessResponse{}, &errs.LoginLockedError{Until: *until}
+	}
+
 	user, err := a.stor.AuthStorage.GetUserByUsername(c, userInput.Username)
 	if err != nil {
-		return "", err
+		if errors.Is(err, errs.ErrUserNotFound) {
+			attempt.Result = models.LoginInvalidCredentials
+			a.recordAttempt(c, attempt)
+		}
+		return models.LoginSuccessResponse{}, err
+	}
+	attempt.UserID = &user.ID
+
+	// Пароль заблокированного аккаунта не проверяем, чтобы перебор во время блокировки был бесполезен
+	if user.LockedUntil != nil && time.Now().Before(*user.LockedUntil) {
+		attempt.Result = models.LoginLocked
+		a.recordAttempt(c, attempt)
+		return models.LoginSuccessResponse{}, &errs.LoginLockedError{Until: *user.LockedUntil}
 	}
 
 	if !utils.CheckPassword(userInput.Password, user.PasswordHash) {
-		return "", errs.ErrInvalidPassword
+		attempt.Result = models.LoginInvalidCredentials
+		a.recordAttempt(c, attempt)
+		return models.LoginSuccessResponse{}, a.registerFailure(c, user, errs.ErrInvalidPassword)
+	}
+
+	// Счётчики неудачных входов сбрасываются только после второго шага, иначе перебор кодов их бы обнулял
+	if user.MFAEnabled {
+		challenge, err := a.createMFAChallenge(c, user.ID)
+		if err != nil {
+			return models.LoginSuccessResponse{}, err
+		}
+		return models.LoginSuccessResponse{MFARequired: true, MFAToken: challenge}, nil
+	}
+
+	return a.completeLogin(c, user, attempt)
+}
+
+// completeLogin сбрасывает счётчики неудачных входов и выдаёт токены
+func (a *Auth) completeLogin(c context.Context, user *models.UserOutput, attempt models.LoginAttempt) (models.LoginSuccessResponse, error) {
+	if err := a.stor.AuthStorage.ResetLoginFailures(c, user.ID); err != nil {
+		return models.LoginSuccessResponse{}, err
+	}
+	attempt.Result = models.LoginSuccess
+	a.recordAttempt(c, attempt)
+
+	// Каждый вход начинает новое семейство refresh-токенов
+	tokens, refreshToken, err := a.issueTokens(user, uuid.New())
+	if err != nil {
+		return models.LoginSuccessResponse{}, err
+	}
+
+	if err := a.stor.AuthStorage.CreateRefreshToken(c, refreshToken); err != nil {
+		return models.LoginSuccessResponse{}, err
+	}
+	return tokens, nil
+}
+
+// LockoutDuration длительность блокировки после lockouts блокировок подряд: base_duration·2^lockouts, не больше max_duration
+func LockoutDuration(cfg *config.LockoutConfig, lockouts int) time.Duration {
+	duration := cfg.BaseDuration
+	for i := 0; i < lockouts && duration < cfg.MaxDuration; i++ {
+		duration *= 2
+	}
+	return min(duration, cfg.MaxDuration)
+}
+
+// registerFailure учитывает неудачный вход и при достижении порога блокирует аккаунт.
+// До порога возвращает failure — ошибку неудачной попытки
+func (a *Auth) registerFailure(c context.Context, user *models.UserOutput, failure error) error {
+	if a.lockout.MaxFailures == 0 {
+		return failure
+	}
+
+	failures, lockouts, err := a.stor.AuthStorage.RegisterLoginFailure(c, user.ID, time.Now().Add(-a.lockout.FailureWindow))
+	if err != nil {
+		return err
+	}
+	if failures < a.lockout.MaxFailures {
+		return failure
+	}
+
+	until := time.Now().Add(LockoutDuration(a.lockout, lockouts))
+	if err := a.stor.AuthStorage.LockUser(c, user.ID, until); err != nil {
+		return err
+	}
+	a.logger.WithContext(c).Warnf("⚠️ Login of user %s locked until %s after %d failed attempts", user.ID, until.Format(time.RFC3339), failures)
+	return &errs.LoginLockedError{Until: until}
+}
+
+// ipLockedUntil до какого времени заблокированы входы с ip; nil — не заблокированы
+func (a *Auth) ipLockedUntil(c context.Context, ip string) (*time.Time, error) {
+	if a.lockout.IPMaxFailures == 0 {
+		return nil, nil
+	}
+
+	failures, oldest, err := a.stor.AuthStorage.CountIPLoginFailures(c, ip, time.Now().Add(-a.lockout.IPWindow))
+	if err != nil {
+		return nil, err
+	}
+	if failures < a.lockout.IPMaxFailures {
+		return nil, nil
+	}
+	// Блокировка снимается, когда самая ранняя неудача выйдет из окна
+	until := oldest.Add(a.lockout.IPWindow)
+	return &until, nil
+}
+
+// recordAttempt пишет попытку в журнал; ошибка журнала не мешает входу
+func (a *Auth) recordAttempt(c context.Context, attempt models.LoginAttempt) {
+	if err := a.stor.AuthStorage.RecordLoginAttempt(c, attempt); err != nil {
+		a.logger.WithContext(c).Errorf("Failed to record login attempt of %s: %v", attempt.Username, err)
+	}
+}
+
+// Refresh выдаёт новую пару токенов и отзывает использованный refresh-токен.
+// Повторное использование отозванного токена считается кражей: отзывается всё семейство
+func (a *Auth) Refresh(c context.Context, refreshToken string) (models.LoginSuccessResponse, error) {
+	current, err := a.stor.AuthStorage.GetRefreshToken(c, utils.HashToken(refreshToken))
+	if err != nil {
+		return models.LoginSuccessResponse{}, err
 	}
 
-	token, err := a.jwt.GenerateToken(user.ID, user.Username)
+	if current.RevokedAt != nil {
+		a.logger.Warnf("Reuse of revoked refresh token detected, user_id=%s", current.UserID)
+		if err := a.stor.AuthStorage.RevokeRefreshTokenFamily(c, current.FamilyID); err != nil {
+			return models.LoginSuccessResponse{}, err
+		}
+		return models.LoginSuccessResponse{}, errs.ErrInvalidRefresh
+	}
+	if time.Now().After(current.ExpiresAt) {
+		return models.LoginSuccessResponse{}, errs.ErrInvalidRefresh
+	}
+
+	user, err := a.stor.AuthStorage.GetUserByID(c, current.UserID)
+	if err != nil {
+		if errors.Is(err, errs.ErrUserNotFound) {
+			return models.LoginSuccessResponse{}, errs.ErrInvalidRefresh
+		}
+		return models.LoginSuccessResponse{}, err
+	}
+
+	tokens, next, err := a.issueTokens(user, current.FamilyID)
+	if err != nil {
+		return models.LoginSuccessResponse{}, err
+	}
+
+	if err := a.stor.AuthStorage.RotateRefreshToken(c, current.ID, next); err != nil {
+		return models.LoginSuccessResponse{}, err
+	}
+	return tokens, nil
+}
+
+// Logout отзывает refresh-токен вместе с семейством и вносит access-токен в denylist до истечения его срока
+func (a *Auth) Logout(c context.Context, claims *models.Claims, refreshToken string) error {
+	current, err := a.stor.AuthStorage.GetRefreshToken(c, utils.HashToken(refreshToken))
+	if err != nil {
+		return err
+	}
+	if current.UserID.String() != claims.UserID {
+		return errs.ErrInvalidRefresh
+	}
+
+	if err := a.stor.AuthStorage.RevokeRefreshTokenFamily(c, current.FamilyID); err != nil {
+		return err
+	}
+	return a.revokeAccessToken(c, claims)
+}
+
+// revokeAccessToken вносит access-токен в denylist до истечения его срока
+func (a *Auth) revokeAccessToken(c context.Context, claims *models.Claims) error {
+	ttl := time.Until(claims.ExpiresAt.Time)
+	if ttl <= 0 {
+		return nil
+	}
+	return a.cache.Set(c, denylistKey(claims.ID), 1, ttl).Err()
+}
+
+// revokeAllAccessTokens отзывает все access-токены пользователя, выпущенные раньше before (с точностью до микросекунды).
+// Отметка хранится, пока не истекут токены, выпущенные до неё
+func (a *Auth) revokeAllAccessTokens(c context.Context, userID uuid.UUID, before time.Time) error {
+	return a.cache.Set(c, tokensValidAfterKey(userID.String()), before.UnixMicro(), a.tokenTTL).Err()
+}
+
+// IsTokenRevoked проверяет, внесён ли jti access-токена в denylist и не выпущен ли токен
+// до отзыва всех сессий пользователя (смена или сброс пароля)
+func (a *Auth) IsTokenRevoked(c context.Context, claims *models.Claims) (bool, error) {
+	values, err := a.cache.MGet(c, denylistKey(claims.ID), tokensValidAfterKey(claims.UserID)).Result()
+	if err != nil {
+		return false, err
+	}
+	if values[0] != nil {
+		return true, nil
+	}
+	if values[1] == nil {
+		return false, nil
+	}
+	validAfter, err := strconv.ParseInt(values[1].(string), 10, 64)
 	if err != nil {
-		return "", err
+		return false, err
 	}
-	return token, nil
+	// iat разбирается через float64 и может оказаться на микросекунду меньше выпущенного
+	return claims.IssuedAt == nil || claims.IssuedAt.UnixMicro()+1 < validAfter, nil
+}
+
+// JWKS публичные ключи для проверки access-токенов другими сервисами
+func (a *Auth) JWKS() models.JWKS {
+	return a.jwt.JWKS()
+}
+
+// issueTokens выпускает access-токен и refresh-токен из семейства familyID
+func (a *Auth) issueTokens(user *models.UserOutput, familyID uuid.UUID) (models.LoginSuccessResponse, models.RefreshToken, error) {
+	accessToken, err := a.jwt.GenerateToken(user.ID, user.Username, user.Role)
+	if err != nil {
+		return models.LoginSuccessResponse{}, models.RefreshToken{}, err
+	}
+
+	refreshToken, expiresAt, err := a.jwt.GenerateRefreshToken()
+	if err != nil {
+		return models.LoginSuccessResponse{}, models.RefreshToken{}, err
+	}
+
+	tokens := models.LoginSuccessResponse{
+		Token:        accessToken,
+		RefreshToken: refreshToken,
+		ExpiresIn:    int64(a.jwt.TokenTTL().Seconds()),
+	}
+	record := models.RefreshToken{
+		UserID:    user.ID,
+		FamilyID:  familyID,
+		TokenHash: utils.HashToken(refreshToken),
+		ExpiresAt: expiresAt,
+	}
+	return tokens, record, nil
+}
+
+func denylistKey(jti string) string {
+	return "jwt_denylist:" + jti
+}
+
+func tokensValidAfterKey(userID string) string {
+	return "jwt_valid_after:" + userID
 }
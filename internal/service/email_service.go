@@ -0,0 +1,138 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/trace"
+
+	"gw-currency-wallet/internal/errs"
+	"gw-currency-wallet/internal/infrastructure/mail"
+	"gw-currency-wallet/internal/storage/models"
+	"gw-currency-wallet/internal/utils"
+)
+
+// VerifyEmail подтверждает email по токену из письма. Токен одноразовый
+func (a *Auth) VerifyEmail(c context.Context, token string) error {
+	userID, err := a.stor.AuthStorage.VerifyEmail(c, utils.HashToken(token))
+	if err != nil {
+		return err
+	}
+	a.logger.WithContext(c).Infof("Email of user %s verified", userID)
+	return nil
+}
+
+// ResendVerification отправляет новое письмо подтверждения; ссылка из прежнего письма перестаёт действовать
+func (a *Auth) ResendVerification(c context.Context, userID uuid.UUID) error {
+	user, err := a.stor.AuthStorage.GetUserByID(c, userID)
+	if err != nil {
+		return err
+	}
+	if user.EmailVerified {
+		return errs.ErrEmailAlreadyVerified
+	}
+	return a.sendVerification(c, user.ID, user.Email)
+}
+
+// ForgotPassword отправляет ссылку сброса пароля. Чтобы по ответу нельзя было узнать,
+// зарегистрирован ли адрес, для неизвестного email ошибка не возвращается
+func (a *Auth) ForgotPassword(c context.Context, email string) error {
+	user, err := a.stor.AuthStorage.GetUserByEmail(c, email)
+	if err != nil {
+		if errors.Is(err, errs.ErrUserNotFound) {
+			a.logger.WithContext(c).Debug("Password reset requested for unknown email")
+			return nil
+		}
+		return err
+	}
+
+	token, err := a.issueUserToken(c, user.ID, user.Email, models.TokenPasswordReset, a.email.ResetTTL)
+	if err != nil {
+		return err
+	}
+	a.sendMail(c, mail.Message{
+		To:      user.Email,
+		Subject: "Reset your password",
+		Body: fmt.Sprintf("Hello, %s!\n\nTo set a new password, follow the link below. It is valid for %s.\n\n%s\n\n"+
+			"If you did not request a password reset, ignore this email: your password will not change.\n",
+			user.Username, a.email.ResetTTL, a.emailLink("/reset-password", token)),
+	})
+	return nil
+}
+
+// ResetPassword задаёт новый пароль по токену из письма и завершает все сессии пользователя:
+// отзываются refresh-токены и все выданные до сброса access-токены
+func (a *Auth) ResetPassword(c context.Context, input models.ResetPasswordRequest) error {
+	passwordHash, err := utils.HashPassword(input.Password)
+	if err != nil {
+		return err
+	}
+	userID, err := a.stor.AuthStorage.ResetPassword(c, utils.HashToken(input.Token), passwordHash)
+	if err != nil {
+		return err
+	}
+	if err := a.revokeAllAccessTokens(c, userID, time.Now()); err != nil {
+		return err
+	}
+	a.logger.WithContext(c).Infof("Password of user %s reset, all sessions revoked", userID)
+	return nil
+}
+
+// sendVerification выдаёт токен подтверждения для email и отправляет письмо со ссылкой
+func (a *Auth) sendVerification(c context.Context, userID uuid.UUID, email string) error {
+	token, err := a.issueUserToken(c, userID, email, models.TokenEmailVerification, a.email.VerificationTTL)
+	if err != nil {
+		return err
+	}
+	a.sendMail(c, mail.Message{
+		To:      email,
+		Subject: "Confirm your email",
+		Body: fmt.Sprintf("Hello!\n\nTo confirm your email address, follow the link below. It is valid for %s.\n\n%s\n\n"+
+			"Deposits, withdrawals, exchanges and transfers are available after confirmation.\n",
+			a.email.VerificationTTL, a.emailLink("/verify-email", token)),
+	})
+	return nil
+}
+
+// issueUserToken сохраняет хэш нового токена из письма и возвращает сам токен
+func (a *Auth) issueUserToken(c context.Context, userID uuid.UUID, email, purpose string, ttl time.Duration) (string, error) {
+	token, err := utils.RandomToken()
+	if err != nil {
+		return "", err
+	}
+	err = a.stor.AuthStorage.CreateUserToken(c, models.UserToken{
+		UserID:    userID,
+		Purpose:   purpose,
+		TokenHash: utils.HashToken(token),
+		Email:     email,
+		ExpiresAt: time.Now().Add(ttl),
+	})
+	if err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+// sendMail отправляет письмо после ответа клиенту: медленный SMTP-сервер не задерживает запрос,
+// а время ответа не выдаёт, существует ли адрес. *gin.Context после ответа переиспользуется,
+// поэтому из контекста запроса берётся только span для трассировки
+func (a *Auth) sendMail(c context.Context, msg mail.Message) {
+	ctx := trace.ContextWithSpanContext(context.Background(), trace.SpanContextFromContext(c))
+	go func() {
+		if err := a.mailer.Send(ctx, msg); err != nil {
+			a.logger.WithContext(ctx).Errorf("❌ Failed to send %q mail: %v", msg.Subject, err)
+		}
+	}()
+}
+
+// emailLink ссылка на страницу фронтенда с токеном; без auth.email.link_base_url — только токен
+func (a *Auth) emailLink(path, token string) string {
+	if a.email.LinkBaseURL == "" {
+		return "Token: " + token
+	}
+	return a.email.LinkBaseURL + path + "?token=" + url.QueryEscape(token)
+}
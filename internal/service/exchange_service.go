@@ -3,6 +3,7 @@ package service
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"time"
 
@@ -10,10 +11,16 @@ import (
 	"github.com/redis/go-redis/v9"
 	"github.com/shopspring/decimal"
 	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/attribute"
+	"golang.org/x/sync/singleflight"
 
+	config "gw-currency-wallet/internal/config"
+	"gw-currency-wallet/internal/errs"
 	"gw-currency-wallet/internal/infrastructure/grpc"
+	"gw-currency-wallet/internal/metrics"
 	"gw-currency-wallet/internal/storage"
 	"gw-currency-wallet/internal/storage/models"
+	"gw-currency-wallet/internal/stream"
 )
 
 // Exchange Сервис, работающий с gRPC-клиентом обмена валют
@@ -22,6 +29,10 @@ type Exchange struct {
 	cache    *redis.Client
 	logger   *logrus.Logger
 	stor     *storage.Storage
+	cfg      *config.ExchangeConfig
+	fees     *FeeEngine
+	stream   *stream.Publisher
+	inflight singleflight.Group // Объединяет одновременные запросы курсов в gw-exchanger
 }
 
 // NewExchangeService Конструктор
@@ -30,64 +41,199 @@ func NewExchangeService(
 	cache *redis.Client,
 	logger *logrus.Logger,
 	stor *storage.Storage,
+	cfg *config.ExchangeConfig,
+	publisher *stream.Publisher,
 ) *Exchange {
 	return &Exchange{
 		exClient: exClient,
 		cache:    cache,
 		logger:   logger,
 		stor:     stor,
+		cfg:      cfg,
+		fees:     NewFeeEngine(&cfg.Fees),
+		stream:   publisher,
 	}
 }
 
-// GetRates Метод получения курсов обмена
-func (e *Exchange) GetRates(c context.Context) (map[string]string, error) {
-	cacheKey := "exchange_rates"
+const (
+	ratesCacheKey = "exchange_rates"
+	rateCacheKey  = "exchange_rate"
 
-	// Проверяем кэш Redis
-	data, err := e.cache.Get(c, cacheKey).Bytes()
-	if err == nil {
-		var cachedRates map[string]string
-		if json.Unmarshal(data, &cachedRates) == nil {
-			e.logger.Debug("✅ Курсы валют получены из кэша Redis")
-			return cachedRates, nil
+	// Время на фоновое обновление курсов после ответа клиенту
+	ratesRefreshTimeout = 5 * time.Second
+)
+
+// GetRates Метод получения курсов обмена.
+// Устаревшие курсы отдаются с признаком Stale и обновляются в фоне, поэтому недоступность gw-exchanger
+// не ломает просмотр курсов, пока в кэше есть последние известные значения
+func (e *Exchange) GetRates(c context.Context) (_ models.ExchangeRates, err error) {
+	c, span := startSpan(c, "Exchange.GetRates")
+	defer func() { endSpan(span, err) }()
+
+	var cached models.ExchangeRates
+	if e.getCached(c, ratesCacheKey, &cached) && len(cached.Rates) > 0 {
+		if e.isFresh(cached.AsOf) {
+			metrics.ObserveCache(ratesCacheKey, metrics.CacheHit)
+			e.logger.WithContext(c).Debug("✅ Курсы валют получены из кэша Redis")
+			return cached, nil
 		}
+
+		metrics.ObserveCache(ratesCacheKey, metrics.CacheStale)
+		e.refreshInBackground(c, func(ctx context.Context) error {
+			_, err := e.fetchRates(ctx)
+			return err
+		})
+		cached.Stale = true
+		return cached, nil
 	}
 
 	// Данных нет в кэше — делаем запрос в сервис
-	rates, err := e.exClient.GetExchangeRates(c)
-	e.logger.Debug("❌ Курсы валют получены НЕ из кэша Redis")
-	if err != nil {
-		return nil, err
+	metrics.ObserveCache(ratesCacheKey, metrics.CacheMiss)
+	e.logger.WithContext(c).Debug("❌ Курсы валют получены НЕ из кэша Redis")
+	return e.fetchRates(c)
+}
+
+// GetRate Метод получения курса для конкретной валютной пары. Устаревший курс отдаётся так же, как в GetRates
+func (e *Exchange) GetRate(c context.Context, fromCurrency, toCurrency string) (_ models.ExchangeRate, err error) {
+	c, span := startSpan(c, "Exchange.GetRate",
+		attribute.String("exchange.from_currency", fromCurrency),
+		attribute.String("exchange.to_currency", toCurrency),
+	)
+	defer func() { endSpan(span, err) }()
+
+	var cached models.ExchangeRate
+	if e.getCached(c, pairCacheKey(fromCurrency, toCurrency), &cached) {
+		if e.isFresh(cached.AsOf) {
+			metrics.ObserveCache(rateCacheKey, metrics.CacheHit)
+			e.logger.WithContext(c).Debug("✅ Курс валюты получен из кэша Redis")
+			return cached, nil
+		}
+
+		metrics.ObserveCache(rateCacheKey, metrics.CacheStale)
+		e.refreshInBackground(c, func(ctx context.Context) error {
+			_, err := e.fetchRate(ctx, fromCurrency, toCurrency)
+			return err
+		})
+		cached.Stale = true
+		return cached, nil
 	}
 
-	// Сохраняем в кэш на 5 минут
-	data, _ = json.Marshal(rates.Rates)
-	e.cache.Set(c, cacheKey, data, 5*time.Minute)
-	return rates.Rates, nil
+	// Данных нет в кэше — делаем запрос в сервис
+	metrics.ObserveCache(rateCacheKey, metrics.CacheMiss)
+	e.logger.WithContext(c).Debug("❌ Курс валюты получены НЕ из кэша Redis")
+	return e.fetchRate(c, fromCurrency, toCurrency)
 }
 
-// GetRate Метод получения курса для конкретной валютной пары
-func (e *Exchange) GetRate(c context.Context, fromCurrency, toCurrency string) (string, error) {
-	cacheKey := fmt.Sprintf("exchange_rate:%s:%s", fromCurrency, toCurrency)
+// rateForExchange курс для проведения обмена. Устаревший курс сначала обновляется синхронно,
+// а если gw-exchanger недоступен, последний известный курс используется не дольше cfg.MaxRateAge
+func (e *Exchange) rateForExchange(c context.Context, fromCurrency, toCurrency string) (models.ExchangeRate, error) {
+	var cached models.ExchangeRate
+	found := e.getCached(c, pairCacheKey(fromCurrency, toCurrency), &cached)
+	if found && e.isFresh(cached.AsOf) {
+		metrics.ObserveCache(rateCacheKey, metrics.CacheHit)
+		return cached, nil
+	}
+
+	if found {
+		metrics.ObserveCache(rateCacheKey, metrics.CacheStale)
+	} else {
+		metrics.ObserveCache(rateCacheKey, metrics.CacheMiss)
+	}
+	rate, err := e.fetchRate(c, fromCurrency, toCurrency)
+	if err == nil || !found {
+		return rate, err
+	}
+
+	age := time.Since(cached.AsOf)
+	if age > e.cfg.MaxRateAge {
+		e.logger.WithContext(c).Warnf("⚠️ Обмен %s/%s отклонён: gw-exchanger недоступен (%v), курс устарел на %s",
+			fromCurrency, toCurrency, err, age.Round(time.Second))
+		return models.ExchangeRate{}, errs.ErrRateTooOld
+	}
+
+	e.logger.WithContext(c).Warnf("⚠️ gw-exchanger недоступен (%v), обмен %s/%s по курсу от %s",
+		err, fromCurrency, toCurrency, cached.AsOf.Format(time.RFC3339))
+	cached.Stale = true
+	return cached, nil
+}
+
+// fetchRates запрашивает курсы у gw-exchanger и сохраняет их как последние известные.
+// Одновременные запросы объединяются в один вызов
+func (e *Exchange) fetchRates(c context.Context) (models.ExchangeRates, error) {
+	result, err, _ := e.inflight.Do(ratesCacheKey, func() (interface{}, error) {
+		resp, err := e.exClient.GetExchangeRates(c)
+		if err != nil {
+			return nil, err
+		}
+
+		rates := models.ExchangeRates{Rates: resp.Rates, AsOf: time.Now().UTC()}
+		e.setCached(c, ratesCacheKey, rates)
+		e.stream.RatesUpdated(c, rates)
+		return rates, nil
+	})
+	if err != nil {
+		return models.ExchangeRates{}, err
+	}
+	return result.(models.ExchangeRates), nil
+}
+
+// fetchRate запрашивает курс пары у gw-exchanger и сохраняет его как последний известный
+func (e *Exchange) fetchRate(c context.Context, fromCurrency, toCurrency string) (models.ExchangeRate, error) {
+	key := pairCacheKey(fromCurrency, toCurrency)
+	result, err, _ := e.inflight.Do(key, func() (interface{}, error) {
+		resp, err := e.exClient.GetExchangeRateForCurrency(c, fromCurrency, toCurrency)
+		if err != nil {
+			return nil, err
+		}
 
-	// Проверяем кэш Redis
-	rate, err := e.cache.Get(c, cacheKey).Result()
-	if err == nil {
-		e.logger.Debug("✅ Курс валюты получен из кэша Redis")
+		rate := models.ExchangeRate{Rate: resp.Rate, AsOf: time.Now().UTC()}
+		e.setCached(c, key, rate)
 		return rate, nil
+	})
+	if err != nil {
+		return models.ExchangeRate{}, err
 	}
+	return result.(models.ExchangeRate), nil
+}
 
-	// Данных нет в кэше — делаем запрос в сервис
-	rateResponse, err := e.exClient.GetExchangeRateForCurrency(c, fromCurrency, toCurrency)
-	e.logger.Debug("❌ Курс валюты получены НЕ из кэша Redis")
+// refreshInBackground обновляет курсы после ответа клиенту; отмена запроса обновление не прерывает
+func (e *Exchange) refreshInBackground(c context.Context, refresh func(ctx context.Context) error) {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(c), ratesRefreshTimeout)
+	go func() {
+		defer cancel()
+		if err := refresh(ctx); err != nil {
+			e.logger.WithContext(ctx).Warnf("⚠️ Не удалось обновить курсы валют: %v", err)
+		}
+	}()
+}
+
+func (e *Exchange) isFresh(asOf time.Time) bool {
+	return time.Since(asOf) < e.cfg.RatesTTL
+}
+
+// getCached читает значение из Redis; false — значения нет или оно в старом формате
+func (e *Exchange) getCached(c context.Context, key string, dest interface{}) bool {
+	data, err := e.cache.Get(c, key).Bytes()
 	if err != nil {
-		return "", err
+		return false
 	}
+	return json.Unmarshal(data, dest) == nil
+}
 
-	// Сохраняем в кэш на 5 минут
-	e.cache.Set(c, cacheKey, rateResponse.Rate, 5*time.Minute)
+// setCached хранит курсы cfg.RatesRetention: это запас на время недоступности gw-exchanger,
+// свежесть проверяется по полю as_of
+func (e *Exchange) setCached(c context.Context, key string, value interface{}) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return
+	}
+	if err := e.cache.Set(c, key, data, e.cfg.RatesRetention).Err(); err != nil {
+		e.logger.WithContext(c).Warnf("⚠️ Не удалось сохранить курсы в Redis: %v", err)
+	}
+}
 
-	return rateResponse.Rate, nil
+func pairCacheKey(fromCurrency, toCurrency string) string {
+	return fmt.Sprintf("%s:%s:%s", rateCacheKey, fromCurrency, toCurrency)
 }
 
 // ExchangeCurrency обмен валют
@@ -98,10 +244,166 @@ func (e *Exchange) ExchangeCurrency(
 	toCurrency string,
 	amount decimal.Decimal,
 	exchangedAmount decimal.Decimal,
-) (models.WalletResponse, error) {
-	balance, err := e.stor.WalletStorage.Exchange(c, userID, fromCurrency, toCurrency, amount, exchangedAmount)
+	fee decimal.Decimal,
+) (_ models.WalletResponse, err error) {
+	c, span := startSpan(c, "Exchange.ExchangeCurrency",
+		attribute.String("exchange.from_currency", fromCurrency),
+		attribute.String("exchange.to_currency", toCurrency),
+	)
+	defer func() { endSpan(span, err) }()
+
+	balance, err := e.stor.WalletStorage.Exchange(c, userID, fromCurrency, toCurrency, amount, exchangedAmount, fee)
 	if err != nil {
 		return models.WalletResponse{}, err
 	}
+
+	metrics.ObserveOperation(models.TransactionExchange, fromCurrency, amount)
+	e.stream.BalanceChanged(c, userID)
 	return balance, nil
 }
+
+// PriceExchange рассчитывает обмен по текущему курсу: сумму к зачислению и комиссию по уровню пользователя
+func (e *Exchange) PriceExchange(
+	c context.Context,
+	userID uuid.UUID,
+	fromCurrency string,
+	toCurrency string,
+	amount decimal.Decimal,
+) (_ models.ExchangeQuote, err error) {
+	c, span := startSpan(c, "Exchange.PriceExchange",
+		attribute.String("exchange.from_currency", fromCurrency),
+		attribute.String("exchange.to_currency", toCurrency),
+	)
+	defer func() { endSpan(span, err) }()
+
+	from, err := e.stor.WalletStorage.GetCurrency(c, fromCurrency)
+	if err != nil {
+		return models.ExchangeQuote{}, err
+	}
+	to, err := e.stor.WalletStorage.GetCurrency(c, toCurrency)
+	if err != nil {
+		return models.ExchangeQuote{}, err
+	}
+
+	// Списываемая сумма округляется до точности исходной валюты
+	amount = amount.Round(from.MinorUnits)
+	if !amount.IsPositive() {
+		return models.ExchangeQuote{}, errs.ErrInvalidAmount
+	}
+
+	current, err := e.rateForExchange(c, from.Code, to.Code)
+	if err != nil {
+		return models.ExchangeQuote{}, err
+	}
+
+	rate, err := decimal.NewFromString(current.Rate)
+	if err != nil {
+		return models.ExchangeQuote{}, err
+	}
+
+	tier, err := e.stor.AuthStorage.GetUserTier(c, userID)
+	if err != nil {
+		return models.ExchangeQuote{}, err
+	}
+
+	// Комиссия удерживается из суммы зачисления. Сумма и комиссия округляются один раз до точности валюты зачисления,
+	// а к зачислению идёт разность, чтобы зачисление и комиссия в сумме не превышали обмен
+	gross := amount.Mul(rate).Round(to.MinorUnits)
+	fee := e.fees.Calculate(tier, from.Code, to.Code, gross).Round(to.MinorUnits)
+	net := gross.Sub(fee)
+	if !net.IsPositive() {
+		return models.ExchangeQuote{}, errs.ErrAmountTooSmall
+	}
+
+	return models.ExchangeQuote{
+		FromCurrency: from.Code,
+		ToCurrency:   to.Code,
+		Rate:         rate,
+		FromAmount:   amount,
+		ToAmount:     net,
+		Fee:          fee,
+		FeeCurrency:  to.Code,
+	}, nil
+}
+
+// CreateQuote фиксирует текущий курс и комиссию для обмена на время cfg.QuoteTTL
+func (e *Exchange) CreateQuote(
+	c context.Context,
+	userID uuid.UUID,
+	fromCurrency string,
+	toCurrency string,
+	amount decimal.Decimal,
+) (models.ExchangeQuote, error) {
+	quote, err := e.PriceExchange(c, userID, fromCurrency, toCurrency, amount)
+	if err != nil {
+		return models.ExchangeQuote{}, err
+	}
+	quote.QuoteID = uuid.New()
+	quote.ExpiresAt = time.Now().Add(e.cfg.QuoteTTL).UTC()
+
+	data, err := json.Marshal(quote)
+	if err != nil {
+		return models.ExchangeQuote{}, err
+	}
+
+	// Котировка доступна только создавшему её пользователю
+	if err := e.cache.Set(c, quoteCacheKey(userID, quote.QuoteID), data, e.cfg.QuoteTTL).Err(); err != nil {
+		return models.ExchangeQuote{}, err
+	}
+
+	return quote, nil
+}
+
+// UseQuote возвращает котировку пользователя и помечает её использованной.
+// Повторное использование, истёкшие и не совпадающие с запросом котировки отклоняются.
+// Если обмен по котировке не состоялся, отметку нужно снять через ReleaseQuote
+func (e *Exchange) UseQuote(c context.Context, userID uuid.UUID, quoteID uuid.UUID, req models.ExchangeRequest) (models.ExchangeQuote, error) {
+	data, err := e.cache.Get(c, quoteCacheKey(userID, quoteID)).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return models.ExchangeQuote{}, errs.ErrQuoteExpired
+	}
+	if err != nil {
+		return models.ExchangeQuote{}, err
+	}
+
+	var quote models.ExchangeQuote
+	if err := json.Unmarshal(data, &quote); err != nil {
+		return models.ExchangeQuote{}, err
+	}
+
+	ttl := time.Until(quote.ExpiresAt)
+	if ttl <= 0 {
+		return models.ExchangeQuote{}, errs.ErrQuoteExpired
+	}
+
+	from, err := e.stor.WalletStorage.GetCurrency(c, quote.FromCurrency)
+	if err != nil {
+		return models.ExchangeQuote{}, err
+	}
+	if !quote.Matches(req, from.MinorUnits) {
+		return models.ExchangeQuote{}, errs.ErrQuoteMismatch
+	}
+
+	// Отметка об использовании живёт столько же, сколько котировка; SETNX не даёт использовать её дважды
+	acquired, err := e.cache.SetNX(c, quoteCacheKey(userID, quoteID)+":used", 1, ttl).Result()
+	if err != nil {
+		return models.ExchangeQuote{}, err
+	}
+	if !acquired {
+		return models.ExchangeQuote{}, errs.ErrQuoteAlreadyUsed
+	}
+
+	return quote, nil
+}
+
+// ReleaseQuote снимает отметку об использовании, чтобы котировку можно было применить повторно
+// после неудачного обмена. Вызывается без отмены контекста: клиент мог уже отключиться
+func (e *Exchange) ReleaseQuote(c context.Context, userID uuid.UUID, quoteID uuid.UUID) {
+	if err := e.cache.Del(context.WithoutCancel(c), quoteCacheKey(userID, quoteID)+":used").Err(); err != nil {
+		e.logger.WithContext(c).Errorf("Failed to release exchange quote %s: %v", quoteID, err)
+	}
+}
+
+func quoteCacheKey(userID, quoteID uuid.UUID) string {
+	return fmt.Sprintf("exchange_quote:%s:%s", userID, quoteID)
+}
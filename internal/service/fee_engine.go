@@ -0,0 +1,49 @@
+package service
+
+import (
+	"strings"
+
+	"github.com/shopspring/decimal"
+
+	config "gw-currency-wallet/internal/config"
+)
+
+var hundred = decimal.NewFromInt(100)
+
+// FeeEngine рассчитывает комиссию за обмен по правилам из конфигурации
+type FeeEngine struct {
+	cfg *config.FeeConfig
+}
+
+func NewFeeEngine(cfg *config.FeeConfig) *FeeEngine {
+	return &FeeEngine{cfg: cfg}
+}
+
+// Calculate возвращает комиссию в валюте зачисления для суммы обмена gross.
+// Расписание уровня пользователя приоритетнее общего, правило пары — приоритетнее правила по умолчанию
+func (f *FeeEngine) Calculate(tier, fromCurrency, toCurrency string, gross decimal.Decimal) decimal.Decimal {
+	rule := f.rule(tier, fromCurrency, toCurrency)
+
+	fee := gross.Mul(decimal.NewFromFloat(rule.SpreadPercent)).Div(hundred).
+		Add(decimal.NewFromFloat(rule.FixedFee))
+
+	if minFee := decimal.NewFromFloat(rule.MinFee); fee.LessThan(minFee) {
+		fee = minFee
+	}
+	return fee
+}
+
+// rule подбирает правило комиссии для пользователя и валютной пары
+func (f *FeeEngine) rule(tier, fromCurrency, toCurrency string) config.FeeRule {
+	schedule := f.cfg.FeeSchedule
+	if tierSchedule, ok := f.cfg.Tiers[strings.ToLower(tier)]; ok {
+		schedule = tierSchedule
+	}
+
+	// viper приводит ключи к нижнему регистру
+	pair := strings.ToLower(fromCurrency + "_" + toCurrency)
+	if pairRule, ok := schedule.Pairs[pair]; ok {
+		return pairRule
+	}
+	return schedule.FeeRule
+}
@@ -0,0 +1,315 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"github.com/shopspring/decimal"
+
+	"gw-currency-wallet/internal/errs"
+	"gw-currency-wallet/internal/storage/models"
+	"gw-currency-wallet/internal/utils"
+)
+
+// LoginMFA второй шаг входа: обменивает mfa_token и код из приложения или код восстановления на токены.
+// Неверный код считается неудачным входом, после mfa.max_attempts ошибок mfa_token перестаёт действовать
+func (a *Auth) LoginMFA(c context.Context, input *models.MFALoginRequest, ip string) (models.LoginSuccessResponse, error) {
+	key := mfaChallengeKey(input.MFAToken)
+	rawID, err := a.cache.HGet(c, key, "user_id").Result()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return models.LoginSuccessResponse{}, errs.ErrInvalidMFAChallenge
+		}
+		return models.LoginSuccessResponse{}, err
+	}
+	userID, err := uuid.Parse(rawID)
+	if err != nil {
+		return models.LoginSuccessResponse{}, errs.ErrInvalidMFAChallenge
+	}
+
+	user, err := a.stor.AuthStorage.GetUserByID(c, userID)
+	if err != nil {
+		if errors.Is(err, errs.ErrUserNotFound) {
+			return models.LoginSuccessResponse{}, errs.ErrInvalidMFAChallenge
+		}
+		return models.LoginSuccessResponse{}, err
+	}
+	attempt := models.LoginAttempt{Username: user.Username, UserID: &user.ID, IP: ip}
+
+	if user.LockedUntil != nil && time.Now().Before(*user.LockedUntil) {
+		attempt.Result = models.LoginLocked
+		a.recordAttempt(c, attempt)
+		return models.LoginSuccessResponse{}, &errs.LoginLockedError{Until: *user.LockedUntil}
+	}
+	// 2FA отключили между шагами входа
+	if !user.MFAEnabled {
+		return models.LoginSuccessResponse{}, errs.ErrInvalidMFAChallenge
+	}
+
+	mfa, err := a.stor.AuthStorage.GetMFA(c, user.ID)
+	if err != nil {
+		return models.LoginSuccessResponse{}, err
+	}
+	if err := a.verifyMFACode(c, user.ID, mfa, input.Code, true); err != nil {
+		if !errors.Is(err, errs.ErrInvalidMFACode) {
+			return models.LoginSuccessResponse{}, err
+		}
+		attempt.Result = models.LoginInvalidCredentials
+		a.recordAttempt(c, attempt)
+		if attempts, err := a.cache.HIncrBy(c, key, "attempts", 1).Result(); err == nil && attempts >= int64(a.mfa.MaxAttempts) {
+			a.cache.Del(c, key)
+		}
+		return models.LoginSuccessResponse{}, a.registerFailure(c, user, errs.ErrInvalidMFACode)
+	}
+
+	// mfa_token одноразовый: из параллельных запросов токены получит только тот, кто его удалил
+	deleted, err := a.cache.Del(c, key).Result()
+	if err != nil {
+		return models.LoginSuccessResponse{}, err
+	}
+	if deleted == 0 {
+		return models.LoginSuccessResponse{}, errs.ErrInvalidMFAChallenge
+	}
+	return a.completeLogin(c, user, attempt)
+}
+
+// EnrollMFA выдаёт новый секрет TOTP. 2FA включится после подтверждения первым кодом в ConfirmMFA
+func (a *Auth) EnrollMFA(c context.Context, userID uuid.UUID) (models.MFAEnrollResponse, error) {
+	user, err := a.stor.AuthStorage.GetUserByID(c, userID)
+	if err != nil {
+		return models.MFAEnrollResponse{}, err
+	}
+	if user.MFAEnabled {
+		return models.MFAEnrollResponse{}, errs.ErrMFAAlreadyEnabled
+	}
+
+	secret, err := utils.GenerateTOTPSecret()
+	if err != nil {
+		return models.MFAEnrollResponse{}, err
+	}
+	encrypted, err := a.cipher.Encrypt(secret)
+	if err != nil {
+		return models.MFAEnrollResponse{}, err
+	}
+	if err := a.stor.AuthStorage.SetPendingMFASecret(c, userID, encrypted); err != nil {
+		return models.MFAEnrollResponse{}, err
+	}
+
+	return models.MFAEnrollResponse{
+		Secret:     secret,
+		OTPAuthURI: utils.TOTPURI(a.mfa.Issuer, user.Username, secret),
+	}, nil
+}
+
+// ConfirmMFA включает 2FA по первому коду из приложения и выдаёт коды восстановления
+func (a *Auth) ConfirmMFA(c context.Context, userID uuid.UUID, code string) (models.MFARecoveryCodesResponse, error) {
+	mfa, err := a.stor.AuthStorage.GetMFA(c, userID)
+	if err != nil {
+		return models.MFARecoveryCodesResponse{}, err
+	}
+	if mfa.EnabledAt != nil {
+		return models.MFARecoveryCodesResponse{}, errs.ErrMFAAlreadyEnabled
+	}
+	if err := a.verifyMFACode(c, userID, mfa, code, false); err != nil {
+		return models.MFARecoveryCodesResponse{}, err
+	}
+
+	codes, hashes, err := generateRecoveryCodes(a.mfa.RecoveryCodes)
+	if err != nil {
+		return models.MFARecoveryCodesResponse{}, err
+	}
+	if err := a.stor.AuthStorage.EnableMFA(c, userID, hashes); err != nil {
+		return models.MFARecoveryCodesResponse{}, err
+	}
+	a.logger.WithContext(c).Infof("Two-factor authentication enabled for user %s", userID)
+	return models.MFARecoveryCodesResponse{RecoveryCodes: codes}, nil
+}
+
+// DisableMFA отключает 2FA по коду из приложения или коду восстановления
+func (a *Auth) DisableMFA(c context.Context, userID uuid.UUID, code string) error {
+	mfa, err := a.stor.AuthStorage.GetMFA(c, userID)
+	if err != nil {
+		return err
+	}
+	if mfa.EnabledAt == nil {
+		return errs.ErrMFANotEnrolled
+	}
+	if err := a.verifyMFACode(c, userID, mfa, code, true); err != nil {
+		return err
+	}
+
+	if err := a.stor.AuthStorage.DisableMFA(c, userID); err != nil {
+		return err
+	}
+	a.logger.WithContext(c).Infof("Two-factor authentication disabled for user %s", userID)
+	return nil
+}
+
+// VerifyWithdrawal требует свежий код из приложения для вывода или перевода от mfa.withdraw_thresholds в их валюте.
+// Пользователей без 2FA и суммы ниже порога не проверяет. После mfa.max_attempts неверных кодов подряд
+// проверка блокируется на lockout.failure_window, чтобы украденный access-токен не позволял перебирать коды
+func (a *Auth) VerifyWithdrawal(c context.Context, userID uuid.UUID, currency string, amount decimal.Decimal, code string) error {
+	threshold, ok := a.mfa.WithdrawThresholds[strings.ToUpper(currency)]
+	if !ok || amount.LessThan(decimal.NewFromFloat(threshold)) {
+		return nil
+	}
+
+	mfa, err := a.stor.AuthStorage.GetMFA(c, userID)
+	if err != nil {
+		return err
+	}
+	if mfa.EnabledAt == nil {
+		return nil
+	}
+	if code == "" {
+		return errs.ErrMFARequired
+	}
+
+	key := mfaFailuresKey(userID)
+	failures, err := a.cache.Get(c, key).Int()
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return err
+	}
+	if failures >= a.mfa.MaxAttempts {
+		return &errs.MFALockedError{Until: time.Now().Add(a.cache.TTL(c, key).Val())}
+	}
+
+	if err := a.verifyMFACode(c, userID, mfa, code, false); err != nil {
+		if errors.Is(err, errs.ErrInvalidMFACode) {
+			return a.registerMFAFailure(c, userID)
+		}
+		return err
+	}
+	return a.cache.Del(c, key).Err()
+}
+
+// registerMFAFailure учитывает неверный код операции. Счётчик сбрасывается, если неверных кодов
+// не было lockout.failure_window
+func (a *Auth) registerMFAFailure(c context.Context, userID uuid.UUID) error {
+	key := mfaFailuresKey(userID)
+	var incr *redis.IntCmd
+	_, err := a.cache.TxPipelined(c, func(pipe redis.Pipeliner) error {
+		incr = pipe.Incr(c, key)
+		pipe.Expire(c, key, a.lockout.FailureWindow)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if incr.Val() >= int64(a.mfa.MaxAttempts) {
+		a.logger.WithContext(c).Warnf("⚠️ Two-factor checks of user %s locked after %d invalid codes", userID, incr.Val())
+		return &errs.MFALockedError{Until: time.Now().Add(a.lockout.FailureWindow)}
+	}
+	return errs.ErrInvalidMFACode
+}
+
+// verifyMFACode проверяет код TOTP и запоминает его интервал, чтобы код нельзя было использовать повторно.
+// С allowRecovery вместо кода из приложения принимается неиспользованный код восстановления
+func (a *Auth) verifyMFACode(c context.Context, userID uuid.UUID, mfa models.UserMFA, code string, allowRecovery bool) error {
+	if mfa.Secret == nil {
+		return errs.ErrMFANotEnrolled
+	}
+	code = strings.TrimSpace(code)
+
+	if !isTOTPCode(code) {
+		if !allowRecovery {
+			return errs.ErrInvalidMFACode
+		}
+		used, err := a.stor.AuthStorage.UseRecoveryCode(c, userID, utils.HashToken(normalizeRecoveryCode(code)))
+		if err != nil {
+			return err
+		}
+		if !used {
+			return errs.ErrInvalidMFACode
+		}
+		a.logger.WithContext(c).Warnf("⚠️ Recovery code used by user %s", userID)
+		return nil
+	}
+
+	secret, err := a.cipher.Decrypt(*mfa.Secret)
+	if err != nil {
+		return err
+	}
+	step, ok := utils.VerifyTOTP(secret, code, time.Now())
+	if !ok {
+		return errs.ErrInvalidMFACode
+	}
+	used, err := a.stor.AuthStorage.UseTOTPStep(c, userID, step)
+	if err != nil {
+		return err
+	}
+	if !used {
+		return errs.ErrInvalidMFACode
+	}
+	return nil
+}
+
+// createMFAChallenge сохраняет в Redis одноразовый mfa_token первого шага входа
+func (a *Auth) createMFAChallenge(c context.Context, userID uuid.UUID) (string, error) {
+	token, err := utils.RandomToken()
+	if err != nil {
+		return "", err
+	}
+	key := mfaChallengeKey(token)
+	_, err = a.cache.TxPipelined(c, func(pipe redis.Pipeliner) error {
+		pipe.HSet(c, key, "user_id", userID.String(), "attempts", 0)
+		pipe.Expire(c, key, a.mfa.ChallengeTTL)
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+// generateRecoveryCodes коды вида xxxx-xxxx-xxxx-xxxx и их хэши для хранения
+func generateRecoveryCodes(n int) ([]string, []string, error) {
+	const alphabet = "0123456789abcdefghjkmnpqrstvwxyz" // Base32 Крокфорда: без i, l, o, u, 80 бит на код
+	codes := make([]string, 0, n)
+	hashes := make([]string, 0, n)
+	for i := 0; i < n; i++ {
+		raw := make([]byte, 16)
+		if _, err := rand.Read(raw); err != nil {
+			return nil, nil, err
+		}
+		var code strings.Builder
+		for j, b := range raw {
+			if j > 0 && j%4 == 0 {
+				code.WriteByte('-')
+			}
+			code.WriteByte(alphabet[b%32])
+		}
+		codes = append(codes, code.String())
+		hashes = append(hashes, utils.HashToken(normalizeRecoveryCode(code.String())))
+	}
+	return codes, hashes, nil
+}
+
+func normalizeRecoveryCode(code string) string {
+	return strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(code))
+}
+
+func isTOTPCode(code string) bool {
+	if len(code) != utils.TOTPDigits {
+		return false
+	}
+	for _, r := range code {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+func mfaFailuresKey(userID uuid.UUID) string {
+	return "mfa_failures:" + userID.String()
+}
+
+func mfaChallengeKey(token string) string {
+	return "mfa_challenge:" + utils.HashToken(token)
+}
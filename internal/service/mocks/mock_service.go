@@ -37,19 +37,180 @@ func (m *MockAuthService) EXPECT() *MockAuthServiceMockRecorder {
 	return m.recorder
 }
 
+// ChangePassword mocks base method.
+func (m *MockAuthService) ChangePassword(c context.Context, claims *models.Claims, input models.ChangePasswordRequest) (models.LoginSuccessResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ChangePassword", c, claims, input)
+	ret0, _ := ret[0].(models.LoginSuccessResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ChangePassword indicates an expected call of ChangePassword.
+func (mr *MockAuthServiceMockRecorder) ChangePassword(c, claims, input interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ChangePassword", reflect.TypeOf((*MockAuthService)(nil).ChangePassword), c, claims, input)
+}
+
+// ConfirmMFA mocks base method.
+func (m *MockAuthService) ConfirmMFA(c context.Context, userID uuid.UUID, code string) (models.MFARecoveryCodesResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ConfirmMFA", c, userID, code)
+	ret0, _ := ret[0].(models.MFARecoveryCodesResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ConfirmMFA indicates an expected call of ConfirmMFA.
+func (mr *MockAuthServiceMockRecorder) ConfirmMFA(c, userID, code interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConfirmMFA", reflect.TypeOf((*MockAuthService)(nil).ConfirmMFA), c, userID, code)
+}
+
+// DisableMFA mocks base method.
+func (m *MockAuthService) DisableMFA(c context.Context, userID uuid.UUID, code string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DisableMFA", c, userID, code)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DisableMFA indicates an expected call of DisableMFA.
+func (mr *MockAuthServiceMockRecorder) DisableMFA(c, userID, code interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DisableMFA", reflect.TypeOf((*MockAuthService)(nil).DisableMFA), c, userID, code)
+}
+
+// EnrollMFA mocks base method.
+func (m *MockAuthService) EnrollMFA(c context.Context, userID uuid.UUID) (models.MFAEnrollResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EnrollMFA", c, userID)
+	ret0, _ := ret[0].(models.MFAEnrollResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// EnrollMFA indicates an expected call of EnrollMFA.
+func (mr *MockAuthServiceMockRecorder) EnrollMFA(c, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EnrollMFA", reflect.TypeOf((*MockAuthService)(nil).EnrollMFA), c, userID)
+}
+
+// ForgotPassword mocks base method.
+func (m *MockAuthService) ForgotPassword(c context.Context, email string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ForgotPassword", c, email)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ForgotPassword indicates an expected call of ForgotPassword.
+func (mr *MockAuthServiceMockRecorder) ForgotPassword(c, email interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ForgotPassword", reflect.TypeOf((*MockAuthService)(nil).ForgotPassword), c, email)
+}
+
+// GetProfile mocks base method.
+func (m *MockAuthService) GetProfile(c context.Context, userID uuid.UUID) (models.UserProfile, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetProfile", c, userID)
+	ret0, _ := ret[0].(models.UserProfile)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetProfile indicates an expected call of GetProfile.
+func (mr *MockAuthServiceMockRecorder) GetProfile(c, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetProfile", reflect.TypeOf((*MockAuthService)(nil).GetProfile), c, userID)
+}
+
+// IsTokenRevoked mocks base method.
+func (m *MockAuthService) IsTokenRevoked(c context.Context, claims *models.Claims) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsTokenRevoked", c, claims)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IsTokenRevoked indicates an expected call of IsTokenRevoked.
+func (mr *MockAuthServiceMockRecorder) IsTokenRevoked(c, claims interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsTokenRevoked", reflect.TypeOf((*MockAuthService)(nil).IsTokenRevoked), c, claims)
+}
+
+// JWKS mocks base method.
+func (m *MockAuthService) JWKS() models.JWKS {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "JWKS")
+	ret0, _ := ret[0].(models.JWKS)
+	return ret0
+}
+
+// JWKS indicates an expected call of JWKS.
+func (mr *MockAuthServiceMockRecorder) JWKS() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "JWKS", reflect.TypeOf((*MockAuthService)(nil).JWKS))
+}
+
 // Login mocks base method.
-func (m *MockAuthService) Login(c context.Context, userInput *models.UserLogin) (string, error) {
+func (m *MockAuthService) Login(c context.Context, userInput *models.UserLogin, ip string) (models.LoginSuccessResponse, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Login", c, userInput)
-	ret0, _ := ret[0].(string)
+	ret := m.ctrl.Call(m, "Login", c, userInput, ip)
+	ret0, _ := ret[0].(models.LoginSuccessResponse)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // Login indicates an expected call of Login.
-func (mr *MockAuthServiceMockRecorder) Login(c, userInput interface{}) *gomock.Call {
+func (mr *MockAuthServiceMockRecorder) Login(c, userInput, ip interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Login", reflect.TypeOf((*MockAuthService)(nil).Login), c, userInput, ip)
+}
+
+// LoginMFA mocks base method.
+func (m *MockAuthService) LoginMFA(c context.Context, input *models.MFALoginRequest, ip string) (models.LoginSuccessResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LoginMFA", c, input, ip)
+	ret0, _ := ret[0].(models.LoginSuccessResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LoginMFA indicates an expected call of LoginMFA.
+func (mr *MockAuthServiceMockRecorder) LoginMFA(c, input, ip interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LoginMFA", reflect.TypeOf((*MockAuthService)(nil).LoginMFA), c, input, ip)
+}
+
+// Logout mocks base method.
+func (m *MockAuthService) Logout(c context.Context, claims *models.Claims, refreshToken string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Logout", c, claims, refreshToken)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Logout indicates an expected call of Logout.
+func (mr *MockAuthServiceMockRecorder) Logout(c, claims, refreshToken interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Logout", reflect.TypeOf((*MockAuthService)(nil).Logout), c, claims, refreshToken)
+}
+
+// Refresh mocks base method.
+func (m *MockAuthService) Refresh(c context.Context, refreshToken string) (models.LoginSuccessResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Refresh", c, refreshToken)
+	ret0, _ := ret[0].(models.LoginSuccessResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Refresh indicates an expected call of Refresh.
+func (mr *MockAuthServiceMockRecorder) Refresh(c, refreshToken interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Login", reflect.TypeOf((*MockAuthService)(nil).Login), c, userInput)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Refresh", reflect.TypeOf((*MockAuthService)(nil).Refresh), c, refreshToken)
 }
 
 // Register mocks base method.
@@ -66,6 +227,77 @@ func (mr *MockAuthServiceMockRecorder) Register(c, input interface{}) *gomock.Ca
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Register", reflect.TypeOf((*MockAuthService)(nil).Register), c, input)
 }
 
+// ResendVerification mocks base method.
+func (m *MockAuthService) ResendVerification(c context.Context, userID uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResendVerification", c, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ResendVerification indicates an expected call of ResendVerification.
+func (mr *MockAuthServiceMockRecorder) ResendVerification(c, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResendVerification", reflect.TypeOf((*MockAuthService)(nil).ResendVerification), c, userID)
+}
+
+// ResetPassword mocks base method.
+func (m *MockAuthService) ResetPassword(c context.Context, input models.ResetPasswordRequest) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResetPassword", c, input)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ResetPassword indicates an expected call of ResetPassword.
+func (mr *MockAuthServiceMockRecorder) ResetPassword(c, input interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResetPassword", reflect.TypeOf((*MockAuthService)(nil).ResetPassword), c, input)
+}
+
+// UpdateProfile mocks base method.
+func (m *MockAuthService) UpdateProfile(c context.Context, userID uuid.UUID, input models.UpdateProfileRequest) (models.UserProfile, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateProfile", c, userID, input)
+	ret0, _ := ret[0].(models.UserProfile)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateProfile indicates an expected call of UpdateProfile.
+func (mr *MockAuthServiceMockRecorder) UpdateProfile(c, userID, input interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateProfile", reflect.TypeOf((*MockAuthService)(nil).UpdateProfile), c, userID, input)
+}
+
+// VerifyEmail mocks base method.
+func (m *MockAuthService) VerifyEmail(c context.Context, token string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "VerifyEmail", c, token)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// VerifyEmail indicates an expected call of VerifyEmail.
+func (mr *MockAuthServiceMockRecorder) VerifyEmail(c, token interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VerifyEmail", reflect.TypeOf((*MockAuthService)(nil).VerifyEmail), c, token)
+}
+
+// VerifyWithdrawal mocks base method.
+func (m *MockAuthService) VerifyWithdrawal(c context.Context, userID uuid.UUID, currency string, amount decimal.Decimal, code string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "VerifyWithdrawal", c, userID, currency, amount, code)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// VerifyWithdrawal indicates an expected call of VerifyWithdrawal.
+func (mr *MockAuthServiceMockRecorder) VerifyWithdrawal(c, userID, currency, amount, code interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VerifyWithdrawal", reflect.TypeOf((*MockAuthService)(nil).VerifyWithdrawal), c, userID, currency, amount, code)
+}
+
 // MockExchangeService is a mock of ExchangeService interface.
 type MockExchangeService struct {
 	ctrl     *gomock.Controller
@@ -89,26 +321,41 @@ func (m *MockExchangeService) EXPECT() *MockExchangeServiceMockRecorder {
 	return m.recorder
 }
 
+// CreateQuote mocks base method.
+func (m *MockExchangeService) CreateQuote(c context.Context, userID uuid.UUID, fromCurrency, toCurrency string, amount decimal.Decimal) (models.ExchangeQuote, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateQuote", c, userID, fromCurrency, toCurrency, amount)
+	ret0, _ := ret[0].(models.ExchangeQuote)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateQuote indicates an expected call of CreateQuote.
+func (mr *MockExchangeServiceMockRecorder) CreateQuote(c, userID, fromCurrency, toCurrency, amount interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateQuote", reflect.TypeOf((*MockExchangeService)(nil).CreateQuote), c, userID, fromCurrency, toCurrency, amount)
+}
+
 // ExchangeCurrency mocks base method.
-func (m *MockExchangeService) ExchangeCurrency(c context.Context, userID uuid.UUID, fromCurrency, toCurrency string, amount, exchangedAmount decimal.Decimal) (models.WalletResponse, error) {
+func (m *MockExchangeService) ExchangeCurrency(c context.Context, userID uuid.UUID, fromCurrency, toCurrency string, amount, exchangedAmount, fee decimal.Decimal) (models.WalletResponse, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ExchangeCurrency", c, userID, fromCurrency, toCurrency, amount, exchangedAmount)
+	ret := m.ctrl.Call(m, "ExchangeCurrency", c, userID, fromCurrency, toCurrency, amount, exchangedAmount, fee)
 	ret0, _ := ret[0].(models.WalletResponse)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // ExchangeCurrency indicates an expected call of ExchangeCurrency.
-func (mr *MockExchangeServiceMockRecorder) ExchangeCurrency(c, userID, fromCurrency, toCurrency, amount, exchangedAmount interface{}) *gomock.Call {
+func (mr *MockExchangeServiceMockRecorder) ExchangeCurrency(c, userID, fromCurrency, toCurrency, amount, exchangedAmount, fee interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExchangeCurrency", reflect.TypeOf((*MockExchangeService)(nil).ExchangeCurrency), c, userID, fromCurrency, toCurrency, amount, exchangedAmount)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExchangeCurrency", reflect.TypeOf((*MockExchangeService)(nil).ExchangeCurrency), c, userID, fromCurrency, toCurrency, amount, exchangedAmount, fee)
 }
 
 // GetRate mocks base method.
-func (m *MockExchangeService) GetRate(c context.Context, fromCurrency, toCurrency string) (string, error) {
+func (m *MockExchangeService) GetRate(c context.Context, fromCurrency, toCurrency string) (models.ExchangeRate, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "GetRate", c, fromCurrency, toCurrency)
-	ret0, _ := ret[0].(string)
+	ret0, _ := ret[0].(models.ExchangeRate)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
@@ -120,10 +367,10 @@ func (mr *MockExchangeServiceMockRecorder) GetRate(c, fromCurrency, toCurrency i
 }
 
 // GetRates mocks base method.
-func (m *MockExchangeService) GetRates(c context.Context) (map[string]string, error) {
+func (m *MockExchangeService) GetRates(c context.Context) (models.ExchangeRates, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "GetRates", c)
-	ret0, _ := ret[0].(map[string]string)
+	ret0, _ := ret[0].(models.ExchangeRates)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
@@ -134,6 +381,48 @@ func (mr *MockExchangeServiceMockRecorder) GetRates(c interface{}) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRates", reflect.TypeOf((*MockExchangeService)(nil).GetRates), c)
 }
 
+// PriceExchange mocks base method.
+func (m *MockExchangeService) PriceExchange(c context.Context, userID uuid.UUID, fromCurrency, toCurrency string, amount decimal.Decimal) (models.ExchangeQuote, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PriceExchange", c, userID, fromCurrency, toCurrency, amount)
+	ret0, _ := ret[0].(models.ExchangeQuote)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PriceExchange indicates an expected call of PriceExchange.
+func (mr *MockExchangeServiceMockRecorder) PriceExchange(c, userID, fromCurrency, toCurrency, amount interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PriceExchange", reflect.TypeOf((*MockExchangeService)(nil).PriceExchange), c, userID, fromCurrency, toCurrency, amount)
+}
+
+// ReleaseQuote mocks base method.
+func (m *MockExchangeService) ReleaseQuote(c context.Context, userID, quoteID uuid.UUID) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "ReleaseQuote", c, userID, quoteID)
+}
+
+// ReleaseQuote indicates an expected call of ReleaseQuote.
+func (mr *MockExchangeServiceMockRecorder) ReleaseQuote(c, userID, quoteID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReleaseQuote", reflect.TypeOf((*MockExchangeService)(nil).ReleaseQuote), c, userID, quoteID)
+}
+
+// UseQuote mocks base method.
+func (m *MockExchangeService) UseQuote(c context.Context, userID, quoteID uuid.UUID, req models.ExchangeRequest) (models.ExchangeQuote, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UseQuote", c, userID, quoteID, req)
+	ret0, _ := ret[0].(models.ExchangeQuote)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UseQuote indicates an expected call of UseQuote.
+func (mr *MockExchangeServiceMockRecorder) UseQuote(c, userID, quoteID, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UseQuote", reflect.TypeOf((*MockExchangeService)(nil).UseQuote), c, userID, quoteID, req)
+}
+
 // MockWalletService is a mock of WalletService interface.
 type MockWalletService struct {
 	ctrl     *gomock.Controller
@@ -187,6 +476,36 @@ func (mr *MockWalletServiceMockRecorder) GetBalance(c, userID interface{}) *gomo
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBalance", reflect.TypeOf((*MockWalletService)(nil).GetBalance), c, userID)
 }
 
+// GetTransactions mocks base method.
+func (m *MockWalletService) GetTransactions(c context.Context, userID uuid.UUID, query models.TransactionsQuery) (models.TransactionsResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTransactions", c, userID, query)
+	ret0, _ := ret[0].(models.TransactionsResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTransactions indicates an expected call of GetTransactions.
+func (mr *MockWalletServiceMockRecorder) GetTransactions(c, userID, query interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTransactions", reflect.TypeOf((*MockWalletService)(nil).GetTransactions), c, userID, query)
+}
+
+// Transfer mocks base method.
+func (m *MockWalletService) Transfer(c context.Context, userID uuid.UUID, recipient, currency string, amount decimal.Decimal) (models.WalletResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Transfer", c, userID, recipient, currency, amount)
+	ret0, _ := ret[0].(models.WalletResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Transfer indicates an expected call of Transfer.
+func (mr *MockWalletServiceMockRecorder) Transfer(c, userID, recipient, currency, amount interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Transfer", reflect.TypeOf((*MockWalletService)(nil).Transfer), c, userID, recipient, currency, amount)
+}
+
 // Withdraw mocks base method.
 func (m *MockWalletService) Withdraw(c context.Context, userID uuid.UUID, currency string, amount decimal.Decimal) (models.WalletResponse, error) {
 	m.ctrl.T.Helper()
@@ -201,3 +520,213 @@ func (mr *MockWalletServiceMockRecorder) Withdraw(c, userID, currency, amount in
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Withdraw", reflect.TypeOf((*MockWalletService)(nil).Withdraw), c, userID, currency, amount)
 }
+
+// MockAdminService is a mock of AdminService interface.
+type MockAdminService struct {
+	ctrl     *gomock.Controller
+	recorder *MockAdminServiceMockRecorder
+}
+
+// MockAdminServiceMockRecorder is the mock recorder for MockAdminService.
+type MockAdminServiceMockRecorder struct {
+	mock *MockAdminService
+}
+
+// NewMockAdminService creates a new mock instance.
+func NewMockAdminService(ctrl *gomock.Controller) *MockAdminService {
+	mock := &MockAdminService{ctrl: ctrl}
+	mock.recorder = &MockAdminServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAdminService) EXPECT() *MockAdminServiceMockRecorder {
+	return m.recorder
+}
+
+// AdjustBalance mocks base method.
+func (m *MockAdminService) AdjustBalance(c context.Context, actorID, userID uuid.UUID, currency string, amount decimal.Decimal, reason string) (models.WalletResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AdjustBalance", c, actorID, userID, currency, amount, reason)
+	ret0, _ := ret[0].(models.WalletResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AdjustBalance indicates an expected call of AdjustBalance.
+func (mr *MockAdminServiceMockRecorder) AdjustBalance(c, actorID, userID, currency, amount, reason interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AdjustBalance", reflect.TypeOf((*MockAdminService)(nil).AdjustBalance), c, actorID, userID, currency, amount, reason)
+}
+
+// FreezeUser mocks base method.
+func (m *MockAdminService) FreezeUser(c context.Context, actorID, userID uuid.UUID, reason string) (models.AdminUser, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FreezeUser", c, actorID, userID, reason)
+	ret0, _ := ret[0].(models.AdminUser)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FreezeUser indicates an expected call of FreezeUser.
+func (mr *MockAdminServiceMockRecorder) FreezeUser(c, actorID, userID, reason interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FreezeUser", reflect.TypeOf((*MockAdminService)(nil).FreezeUser), c, actorID, userID, reason)
+}
+
+// GetUser mocks base method.
+func (m *MockAdminService) GetUser(c context.Context, userID uuid.UUID) (models.AdminUser, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUser", c, userID)
+	ret0, _ := ret[0].(models.AdminUser)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUser indicates an expected call of GetUser.
+func (mr *MockAdminServiceMockRecorder) GetUser(c, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUser", reflect.TypeOf((*MockAdminService)(nil).GetUser), c, userID)
+}
+
+// SearchUsers mocks base method.
+func (m *MockAdminService) SearchUsers(c context.Context, query string) ([]models.AdminUser, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SearchUsers", c, query)
+	ret0, _ := ret[0].([]models.AdminUser)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SearchUsers indicates an expected call of SearchUsers.
+func (mr *MockAdminServiceMockRecorder) SearchUsers(c, query interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchUsers", reflect.TypeOf((*MockAdminService)(nil).SearchUsers), c, query)
+}
+
+// UnfreezeUser mocks base method.
+func (m *MockAdminService) UnfreezeUser(c context.Context, actorID, userID uuid.UUID) (models.AdminUser, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UnfreezeUser", c, actorID, userID)
+	ret0, _ := ret[0].(models.AdminUser)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UnfreezeUser indicates an expected call of UnfreezeUser.
+func (mr *MockAdminServiceMockRecorder) UnfreezeUser(c, actorID, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UnfreezeUser", reflect.TypeOf((*MockAdminService)(nil).UnfreezeUser), c, actorID, userID)
+}
+
+// UnlockUser mocks base method.
+func (m *MockAdminService) UnlockUser(c context.Context, actorID, userID uuid.UUID) (models.AdminUser, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UnlockUser", c, actorID, userID)
+	ret0, _ := ret[0].(models.AdminUser)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UnlockUser indicates an expected call of UnlockUser.
+func (mr *MockAdminServiceMockRecorder) UnlockUser(c, actorID, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UnlockUser", reflect.TypeOf((*MockAdminService)(nil).UnlockUser), c, actorID, userID)
+}
+
+// MockWebhookService is a mock of WebhookService interface.
+type MockWebhookService struct {
+	ctrl     *gomock.Controller
+	recorder *MockWebhookServiceMockRecorder
+}
+
+// MockWebhookServiceMockRecorder is the mock recorder for MockWebhookService.
+type MockWebhookServiceMockRecorder struct {
+	mock *MockWebhookService
+}
+
+// NewMockWebhookService creates a new mock instance.
+func NewMockWebhookService(ctrl *gomock.Controller) *MockWebhookService {
+	mock := &MockWebhookService{ctrl: ctrl}
+	mock.recorder = &MockWebhookServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockWebhookService) EXPECT() *MockWebhookServiceMockRecorder {
+	return m.recorder
+}
+
+// CreateWebhook mocks base method.
+func (m *MockWebhookService) CreateWebhook(c context.Context, userID uuid.UUID, input models.WebhookRequest) (models.WebhookSubscription, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateWebhook", c, userID, input)
+	ret0, _ := ret[0].(models.WebhookSubscription)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateWebhook indicates an expected call of CreateWebhook.
+func (mr *MockWebhookServiceMockRecorder) CreateWebhook(c, userID, input interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateWebhook", reflect.TypeOf((*MockWebhookService)(nil).CreateWebhook), c, userID, input)
+}
+
+// DeleteWebhook mocks base method.
+func (m *MockWebhookService) DeleteWebhook(c context.Context, userID, webhookID uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteWebhook", c, userID, webhookID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteWebhook indicates an expected call of DeleteWebhook.
+func (mr *MockWebhookServiceMockRecorder) DeleteWebhook(c, userID, webhookID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteWebhook", reflect.TypeOf((*MockWebhookService)(nil).DeleteWebhook), c, userID, webhookID)
+}
+
+// GetDeliveries mocks base method.
+func (m *MockWebhookService) GetDeliveries(c context.Context, userID, webhookID uuid.UUID, status string) ([]models.WebhookDelivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDeliveries", c, userID, webhookID, status)
+	ret0, _ := ret[0].([]models.WebhookDelivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDeliveries indicates an expected call of GetDeliveries.
+func (mr *MockWebhookServiceMockRecorder) GetDeliveries(c, userID, webhookID, status interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDeliveries", reflect.TypeOf((*MockWebhookService)(nil).GetDeliveries), c, userID, webhookID, status)
+}
+
+// GetWebhooks mocks base method.
+func (m *MockWebhookService) GetWebhooks(c context.Context, userID uuid.UUID) ([]models.WebhookSubscription, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWebhooks", c, userID)
+	ret0, _ := ret[0].([]models.WebhookSubscription)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWebhooks indicates an expected call of GetWebhooks.
+func (mr *MockWebhookServiceMockRecorder) GetWebhooks(c, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWebhooks", reflect.TypeOf((*MockWebhookService)(nil).GetWebhooks), c, userID)
+}
+
+// ReplayDelivery mocks base method.
+func (m *MockWebhookService) ReplayDelivery(c context.Context, userID, webhookID, deliveryID uuid.UUID) (models.WebhookDelivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReplayDelivery", c, userID, webhookID, deliveryID)
+	ret0, _ := ret[0].(models.WebhookDelivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReplayDelivery indicates an expected call of ReplayDelivery.
+func (mr *MockWebhookServiceMockRecorder) ReplayDelivery(c, userID, webhookID, deliveryID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReplayDelivery", reflect.TypeOf((*MockWebhookService)(nil).ReplayDelivery), c, userID, webhookID, deliveryID)
+}
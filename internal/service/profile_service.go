@@ -0,0 +1,127 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+
+	"gw-currency-wallet/internal/errs"
+	"gw-currency-wallet/internal/infrastructure/mail"
+	"gw-currency-wallet/internal/storage/models"
+	"gw-currency-wallet/internal/utils"
+)
+
+// GetProfile профиль текущего пользователя
+func (a *Auth) GetProfile(c context.Context, userID uuid.UUID) (models.UserProfile, error) {
+	user, err := a.stor.AuthStorage.GetUserByID(c, userID)
+	if err != nil {
+		return models.UserProfile{}, err
+	}
+	return toProfile(user), nil
+}
+
+// UpdateProfile меняет email: новый адрес нужно подтвердить заново, на прежний уходит уведомление.
+// По email можно сбросить пароль, поэтому смена требует текущий пароль
+func (a *Auth) UpdateProfile(c context.Context, userID uuid.UUID, input models.UpdateProfileRequest) (models.UserProfile, error) {
+	user, err := a.stor.AuthStorage.GetUserByID(c, userID)
+	if err != nil {
+		return models.UserProfile{}, err
+	}
+	if input.Email == nil || *input.Email == user.Email {
+		return toProfile(user), nil
+	}
+
+	if err := a.checkCurrentPassword(c, user, input.CurrentPassword); err != nil {
+		return models.UserProfile{}, err
+	}
+	if err := a.stor.AuthStorage.UpdateEmail(c, user.ID, *input.Email); err != nil {
+		return models.UserProfile{}, err
+	}
+	a.logger.WithContext(c).Infof("Email of user %s changed, verification required", user.ID)
+
+	if err := a.sendVerification(c, user.ID, *input.Email); err != nil {
+		a.logger.WithContext(c).Errorf("Failed to issue email verification for user %s: %v", user.ID, err)
+	}
+	a.sendMail(c, mail.Message{
+		To:      user.Email,
+		Subject: "Your email was changed",
+		Body: "Hello, " + user.Username + "!\n\nThe email address of your account was changed.\n\n" +
+			"If you did not do this, contact support immediately.\n",
+	})
+
+	user.Email = *input.Email
+	user.EmailVerified = false
+	return toProfile(user), nil
+}
+
+// ChangePassword меняет пароль и завершает все сессии пользователя, включая текущую: отзываются refresh-токены
+// и все access-токены, выпущенные до смены пароля, текущий access-токен вносится в denylist.
+// Взамен возвращаются токены новой сессии
+func (a *Auth) ChangePassword(c context.Context, claims *models.Claims, input models.ChangePasswordRequest) (models.LoginSuccessResponse, error) {
+	userID, err := uuid.Parse(claims.UserID)
+	if err != nil {
+		return models.LoginSuccessResponse{}, errs.ErrInvalidUserId
+	}
+	user, err := a.stor.AuthStorage.GetUserByID(c, userID)
+	if err != nil {
+		return models.LoginSuccessResponse{}, err
+	}
+	if err := a.checkCurrentPassword(c, user, input.CurrentPassword); err != nil {
+		return models.LoginSuccessResponse{}, err
+	}
+
+	passwordHash, err := utils.HashPassword(input.NewPassword)
+	if err != nil {
+		return models.LoginSuccessResponse{}, err
+	}
+	// Отметка берётся до выпуска новых токенов, чтобы они остались действительными
+	revokedBefore := time.Now()
+	tokens, session, err := a.issueTokens(user, uuid.New())
+	if err != nil {
+		return models.LoginSuccessResponse{}, err
+	}
+	if err := a.stor.AuthStorage.ChangePassword(c, user.ID, passwordHash, session); err != nil {
+		return models.LoginSuccessResponse{}, err
+	}
+	// Отзываются и access-токены других сессий, выпущенные до смены пароля
+	if err := a.revokeAllAccessTokens(c, user.ID, revokedBefore); err != nil {
+		return models.LoginSuccessResponse{}, err
+	}
+	if err := a.revokeAccessToken(c, claims); err != nil {
+		return models.LoginSuccessResponse{}, err
+	}
+	a.logger.WithContext(c).Infof("Password of user %s changed, all sessions revoked", user.ID)
+
+	a.sendMail(c, mail.Message{
+		To:      user.Email,
+		Subject: "Your password was changed",
+		Body: "Hello, " + user.Username + "!\n\nThe password of your account was changed and all sessions were signed out.\n\n" +
+			"If you did not do this, reset your password and contact support.\n",
+	})
+	return tokens, nil
+}
+
+// checkCurrentPassword проверяет текущий пароль перед изменением учётных данных. Неверный пароль считается
+// неудачным входом, чтобы украденный access-токен не позволял его перебирать
+func (a *Auth) checkCurrentPassword(c context.Context, user *models.UserOutput, password string) error {
+	if user.LockedUntil != nil && time.Now().Before(*user.LockedUntil) {
+		return &errs.LoginLockedError{Until: *user.LockedUntil}
+	}
+	if !utils.CheckPassword(password, user.PasswordHash) {
+		return a.registerFailure(c, user, errs.ErrWrongPassword)
+	}
+	return nil
+}
+
+func toProfile(user *models.UserOutput) models.UserProfile {
+	return models.UserProfile{
+		ID:            user.ID,
+		Username:      user.Username,
+		Email:         user.Email,
+		EmailVerified: user.EmailVerified,
+		Role:          user.Role,
+		MFAEnabled:    user.MFAEnabled,
+		CreatedAt:     user.CreatedAt,
+	}
+}
@@ -8,33 +8,77 @@ import (
 	"github.com/shopspring/decimal"
 	"github.com/sirupsen/logrus"
 
+	config "gw-currency-wallet/internal/config"
 	"gw-currency-wallet/internal/infrastructure/grpc"
+	"gw-currency-wallet/internal/infrastructure/mail"
 	"gw-currency-wallet/internal/storage"
 	"gw-currency-wallet/internal/storage/models"
+	"gw-currency-wallet/internal/stream"
 	"gw-currency-wallet/internal/utils"
 )
 
 type AuthService interface {
 	Register(c context.Context, input models.UserRegister) error
-	Login(c context.Context, userInput *models.UserLogin) (string, error)
+	Login(c context.Context, userInput *models.UserLogin, ip string) (models.LoginSuccessResponse, error)
+	LoginMFA(c context.Context, input *models.MFALoginRequest, ip string) (models.LoginSuccessResponse, error)
+	EnrollMFA(c context.Context, userID uuid.UUID) (models.MFAEnrollResponse, error)
+	ConfirmMFA(c context.Context, userID uuid.UUID, code string) (models.MFARecoveryCodesResponse, error)
+	DisableMFA(c context.Context, userID uuid.UUID, code string) error
+	VerifyWithdrawal(c context.Context, userID uuid.UUID, currency string, amount decimal.Decimal, code string) error
+	VerifyEmail(c context.Context, token string) error
+	ResendVerification(c context.Context, userID uuid.UUID) error
+	ForgotPassword(c context.Context, email string) error
+	ResetPassword(c context.Context, input models.ResetPasswordRequest) error
+	GetProfile(c context.Context, userID uuid.UUID) (models.UserProfile, error)
+	UpdateProfile(c context.Context, userID uuid.UUID, input models.UpdateProfileRequest) (models.UserProfile, error)
+	ChangePassword(c context.Context, claims *models.Claims, input models.ChangePasswordRequest) (models.LoginSuccessResponse, error)
+	Refresh(c context.Context, refreshToken string) (models.LoginSuccessResponse, error)
+	Logout(c context.Context, claims *models.Claims, refreshToken string) error
+	IsTokenRevoked(c context.Context, claims *models.Claims) (bool, error)
+	JWKS() models.JWKS
 }
 
 type ExchangeService interface {
-	GetRates(c context.Context) (map[string]string, error)
-	GetRate(c context.Context, fromCurrency, toCurrency string) (string, error)
-	ExchangeCurrency(c context.Context, userID uuid.UUID, fromCurrency string, toCurrency string, amount decimal.Decimal, exchangedAmount decimal.Decimal) (models.WalletResponse, error)
+	GetRates(c context.Context) (models.ExchangeRates, error)
+	GetRate(c context.Context, fromCurrency, toCurrency string) (models.ExchangeRate, error)
+	PriceExchange(c context.Context, userID uuid.UUID, fromCurrency string, toCurrency string, amount decimal.Decimal) (models.ExchangeQuote, error)
+	ExchangeCurrency(c context.Context, userID uuid.UUID, fromCurrency string, toCurrency string, amount decimal.Decimal, exchangedAmount decimal.Decimal, fee decimal.Decimal) (models.WalletResponse, error)
+	CreateQuote(c context.Context, userID uuid.UUID, fromCurrency string, toCurrency string, amount decimal.Decimal) (models.ExchangeQuote, error)
+	UseQuote(c context.Context, userID uuid.UUID, quoteID uuid.UUID, req models.ExchangeRequest) (models.ExchangeQuote, error)
+	ReleaseQuote(c context.Context, userID uuid.UUID, quoteID uuid.UUID)
 }
 
 type WalletService interface {
 	GetBalance(c context.Context, userID uuid.UUID) (models.WalletResponse, error)
 	Deposit(c context.Context, userID uuid.UUID, currency string, amount decimal.Decimal) (models.WalletResponse, error)
 	Withdraw(c context.Context, userID uuid.UUID, currency string, amount decimal.Decimal) (models.WalletResponse, error)
+	Transfer(c context.Context, userID uuid.UUID, recipient string, currency string, amount decimal.Decimal) (models.WalletResponse, error)
+	GetTransactions(c context.Context, userID uuid.UUID, query models.TransactionsQuery) (models.TransactionsResponse, error)
+}
+
+type AdminService interface {
+	SearchUsers(c context.Context, query string) ([]models.AdminUser, error)
+	GetUser(c context.Context, userID uuid.UUID) (models.AdminUser, error)
+	FreezeUser(c context.Context, actorID, userID uuid.UUID, reason string) (models.AdminUser, error)
+	UnfreezeUser(c context.Context, actorID, userID uuid.UUID) (models.AdminUser, error)
+	UnlockUser(c context.Context, actorID, userID uuid.UUID) (models.AdminUser, error)
+	AdjustBalance(c context.Context, actorID uuid.UUID, userID uuid.UUID, currency string, amount decimal.Decimal, reason string) (models.WalletResponse, error)
+}
+
+type WebhookService interface {
+	CreateWebhook(c context.Context, userID uuid.UUID, input models.WebhookRequest) (models.WebhookSubscription, error)
+	GetWebhooks(c context.Context, userID uuid.UUID) ([]models.WebhookSubscription, error)
+	DeleteWebhook(c context.Context, userID, webhookID uuid.UUID) error
+	GetDeliveries(c context.Context, userID, webhookID uuid.UUID, status string) ([]models.WebhookDelivery, error)
+	ReplayDelivery(c context.Context, userID, webhookID, deliveryID uuid.UUID) (models.WebhookDelivery, error)
 }
 
 type Service struct {
 	AuthService
 	ExchangeService
 	WalletService
+	AdminService
+	WebhookService
 }
 
 func NewService(
@@ -43,10 +87,17 @@ func NewService(
 	jwtManager *utils.JWTManager,
 	exClient *grpc.ExchangeClient,
 	cache *redis.Client,
+	exchangeCfg *config.ExchangeConfig,
+	authCfg *config.AuthConfig,
+	webhookCfg *config.WebhookConfig,
+	mailer mail.Mailer,
 ) *Service {
+	publisher := stream.NewPublisher(cache, logger)
 	return &Service{
-		AuthService:     NewAuthService(stor, logger, jwtManager),
-		ExchangeService: NewExchangeService(exClient, cache, logger, stor),
-		WalletService:   NewWalletService(stor, logger),
+		AuthService:     NewAuthService(stor, logger, jwtManager, cache, authCfg, mailer),
+		ExchangeService: NewExchangeService(exClient, cache, logger, stor, exchangeCfg, publisher),
+		WalletService:   NewWalletService(stor, logger, publisher),
+		AdminService:    NewAdminService(stor, logger, publisher),
+		WebhookService:  NewWebhookService(stor, logger, webhookCfg),
 	}
 }
@@ -0,0 +1,26 @@
+package service
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var tracer = otel.Tracer("gw-currency-wallet/internal/service")
+
+// startSpan начинает span операции сервиса; вложенные запросы в Postgres, Redis и gRPC попадут в него
+func startSpan(c context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return tracer.Start(c, name, trace.WithAttributes(attrs...))
+}
+
+// endSpan завершает span, отмечая ошибку операции
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
@@ -2,25 +2,33 @@ package service
 
 import (
 	"context"
+	"encoding/base64"
+	"strings"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/shopspring/decimal"
 	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/attribute"
 
 	"gw-currency-wallet/internal/errs"
+	"gw-currency-wallet/internal/metrics"
 	"gw-currency-wallet/internal/storage"
 	"gw-currency-wallet/internal/storage/models"
+	"gw-currency-wallet/internal/stream"
 )
 
 type Wallet struct {
 	stor   *storage.Storage
 	logger *logrus.Logger
+	stream *stream.Publisher
 }
 
-func NewWalletService(stor *storage.Storage, logger *logrus.Logger) *Wallet {
+func NewWalletService(stor *storage.Storage, logger *logrus.Logger, publisher *stream.Publisher) *Wallet {
 	return &Wallet{
 		stor:   stor,
 		logger: logger,
+		stream: publisher,
 	}
 }
 
@@ -34,33 +42,147 @@ func (w *Wallet) GetBalance(c context.Context, userID uuid.UUID) (models.WalletR
 }
 
 // Deposit – пополнение баланса
-func (w *Wallet) Deposit(c context.Context, userID uuid.UUID, currency string, amount decimal.Decimal) (models.WalletResponse, error) {
+func (w *Wallet) Deposit(c context.Context, userID uuid.UUID, currency string, amount decimal.Decimal) (_ models.WalletResponse, err error) {
+	c, span := startSpan(c, "Wallet.Deposit", attribute.String("wallet.currency", currency))
+	defer func() { endSpan(span, err) }()
+
 	// Проверим, что сумма больше нуля
 	if amount.IsNegative() || amount.IsZero() {
 		return models.WalletResponse{}, errs.ErrInvalidAmount
 	}
 
-	balance, err := w.stor.WalletStorage.Deposit(c, userID, currency, amount)
+	result, err := w.stor.WalletStorage.Deposit(c, userID, currency, amount)
 	if err != nil {
 		return models.WalletResponse{}, err
 	}
-	w.logger.Debugf("Deposit succeeded")
-	return balance, nil
+	w.logger.WithContext(c).Debugf("Deposit succeeded")
+
+	metrics.ObserveOperation(models.TransactionDeposit, result.Currency, result.Amount)
+	w.stream.BalanceChanged(c, userID)
+	return result.Balance, nil
 }
 
 // Withdraw – создаем Kafka-событие на списание
-func (w *Wallet) Withdraw(c context.Context, userID uuid.UUID, currency string, amount decimal.Decimal) (models.WalletResponse, error) {
+func (w *Wallet) Withdraw(c context.Context, userID uuid.UUID, currency string, amount decimal.Decimal) (_ models.WalletResponse, err error) {
+	c, span := startSpan(c, "Wallet.Withdraw", attribute.String("wallet.currency", currency))
+	defer func() { endSpan(span, err) }()
+
 	// Проверим, что сумма больше нуля
 	if amount.IsNegative() || amount.IsZero() {
 		return models.WalletResponse{}, errs.ErrInvalidAmount
 	}
 
 	// Пытаемся снять средства
-	balance, err := w.stor.WalletStorage.Withdraw(c, userID, currency, amount)
+	result, err := w.stor.WalletStorage.Withdraw(c, userID, currency, amount)
 	if err != nil {
 		return models.WalletResponse{}, err
 	}
 
-	w.logger.Debugf("Successfully withdrew %s %s from user %v", amount, currency, userID)
-	return balance, nil
+	w.logger.WithContext(c).Debugf("Successfully withdrew %s %s from user %v", result.Amount, result.Currency, userID)
+
+	metrics.ObserveOperation(models.TransactionWithdraw, result.Currency, result.Amount)
+	w.stream.BalanceChanged(c, userID)
+	return result.Balance, nil
+}
+
+// Transfer – перевод средств другому пользователю
+func (w *Wallet) Transfer(c context.Context, userID uuid.UUID, recipient string, currency string, amount decimal.Decimal) (_ models.WalletResponse, err error) {
+	c, span := startSpan(c, "Wallet.Transfer", attribute.String("wallet.currency", currency))
+	defer func() { endSpan(span, err) }()
+
+	// Проверим, что сумма больше нуля
+	if amount.IsNegative() || amount.IsZero() {
+		return models.WalletResponse{}, errs.ErrInvalidAmount
+	}
+
+	result, err := w.stor.WalletStorage.Transfer(c, userID, recipient, currency, amount)
+	if err != nil {
+		return models.WalletResponse{}, err
+	}
+
+	w.logger.WithContext(c).Debugf("Successfully transferred %s %s from user %v to %s", result.Amount, result.Currency, userID, recipient)
+
+	w.stream.BalanceChanged(c, userID, result.RecipientID)
+	return result.Balance, nil
+}
+
+const (
+	defaultTransactionsLimit = 20
+	cursorSeparator          = "|"
+)
+
+// GetTransactions – история операций пользователя с курсорной пагинацией
+func (w *Wallet) GetTransactions(c context.Context, userID uuid.UUID, query models.TransactionsQuery) (models.TransactionsResponse, error) {
+	if !query.From.IsZero() && !query.To.IsZero() && !query.From.Before(query.To) {
+		return models.TransactionsResponse{}, errs.ErrInvalidDateRange
+	}
+
+	filter := models.TransactionFilter{
+		Currency: query.Currency,
+		Type:     query.Type,
+		From:     query.From,
+		To:       query.To,
+		Limit:    query.Limit,
+	}
+	if filter.Limit == 0 {
+		filter.Limit = defaultTransactionsLimit
+	}
+
+	if query.Cursor != "" {
+		createdAt, id, err := decodeCursor(query.Cursor)
+		if err != nil {
+			return models.TransactionsResponse{}, err
+		}
+		filter.AfterCreatedAt = createdAt
+		filter.AfterID = id
+	}
+
+	// Запрашиваем на одну запись больше, чтобы понять, есть ли следующая страница
+	limit := filter.Limit
+	filter.Limit++
+
+	transactions, err := w.stor.WalletStorage.GetTransactions(c, userID, filter)
+	if err != nil {
+		return models.TransactionsResponse{}, err
+	}
+
+	response := models.TransactionsResponse{Transactions: transactions}
+	if len(transactions) > limit {
+		response.Transactions = transactions[:limit]
+		last := response.Transactions[limit-1]
+		response.NextCursor = encodeCursor(last.CreatedAt, last.ID)
+	}
+
+	return response, nil
+}
+
+// encodeCursor упаковывает позицию последней записи страницы в непрозрачную строку
+func encodeCursor(createdAt time.Time, id uuid.UUID) string {
+	raw := createdAt.UTC().Format(time.RFC3339Nano) + cursorSeparator + id.String()
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// decodeCursor разбирает курсор, выданный encodeCursor
+func decodeCursor(cursor string) (time.Time, uuid.UUID, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return time.Time{}, uuid.Nil, errs.ErrInvalidCursor
+	}
+
+	parts := strings.SplitN(string(raw), cursorSeparator, 2)
+	if len(parts) != 2 {
+		return time.Time{}, uuid.Nil, errs.ErrInvalidCursor
+	}
+
+	createdAt, err := time.Parse(time.RFC3339Nano, parts[0])
+	if err != nil {
+		return time.Time{}, uuid.Nil, errs.ErrInvalidCursor
+	}
+
+	id, err := uuid.Parse(parts[1])
+	if err != nil {
+		return time.Time{}, uuid.Nil, errs.ErrInvalidCursor
+	}
+
+	return createdAt, id, nil
 }
@@ -0,0 +1,77 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+
+	config "gw-currency-wallet/internal/config"
+	"gw-currency-wallet/internal/storage"
+	"gw-currency-wallet/internal/storage/models"
+	"gw-currency-wallet/internal/webhook"
+)
+
+// webhookDeliveriesLimit максимальное число доставок в истории подписки
+const webhookDeliveriesLimit = 50
+
+// Webhook Сервис подписок на события кошелька
+type Webhook struct {
+	stor   *storage.Storage
+	logger *logrus.Logger
+	cfg    *config.WebhookConfig
+}
+
+func NewWebhookService(stor *storage.Storage, logger *logrus.Logger, cfg *config.WebhookConfig) *Webhook {
+	return &Webhook{
+		stor:   stor,
+		logger: logger,
+		cfg:    cfg,
+	}
+}
+
+// CreateWebhook регистрирует подписку и выдаёт секрет для проверки подписи доставок.
+// URL, ведущий во внутреннюю сеть, отклоняется
+func (w *Webhook) CreateWebhook(c context.Context, userID uuid.UUID, input models.WebhookRequest) (models.WebhookSubscription, error) {
+	if !w.cfg.AllowPrivateNetworks {
+		if err := webhook.CheckURL(c, input.URL); err != nil {
+			return models.WebhookSubscription{}, err
+		}
+	}
+
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return models.WebhookSubscription{}, err
+	}
+
+	return w.stor.WebhookStorage.CreateWebhook(c, models.WebhookSubscription{
+		UserID:     userID,
+		URL:        input.URL,
+		EventTypes: input.EventTypes,
+		Secret:     "whsec_" + hex.EncodeToString(secret),
+	})
+}
+
+func (w *Webhook) GetWebhooks(c context.Context, userID uuid.UUID) ([]models.WebhookSubscription, error) {
+	return w.stor.WebhookStorage.GetWebhooks(c, userID)
+}
+
+func (w *Webhook) DeleteWebhook(c context.Context, userID, webhookID uuid.UUID) error {
+	return w.stor.WebhookStorage.DeleteWebhook(c, userID, webhookID)
+}
+
+func (w *Webhook) GetDeliveries(c context.Context, userID, webhookID uuid.UUID, status string) ([]models.WebhookDelivery, error) {
+	return w.stor.WebhookStorage.GetWebhookDeliveries(c, userID, webhookID, status, webhookDeliveriesLimit)
+}
+
+// ReplayDelivery повторно ставит доставку в очередь, в том числе из статуса dead
+func (w *Webhook) ReplayDelivery(c context.Context, userID, webhookID, deliveryID uuid.UUID) (models.WebhookDelivery, error) {
+	delivery, err := w.stor.WebhookStorage.ReplayWebhookDelivery(c, userID, webhookID, deliveryID)
+	if err != nil {
+		return models.WebhookDelivery{}, err
+	}
+	w.logger.Infof("Webhook delivery %s replayed by user %s", deliveryID, userID)
+	return delivery, nil
+}
@@ -0,0 +1,302 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/shopspring/decimal"
+	"github.com/sirupsen/logrus"
+
+	"gw-currency-wallet/internal/errs"
+	"gw-currency-wallet/internal/storage/models"
+)
+
+const adminUserColumns = `id, username, email, role, tier, frozen_at, frozen_reason, locked_until, created_at`
+
+// likeEscaper экранирует спецсимволы LIKE, чтобы строка поиска сравнивалась буквально
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+type Admin struct {
+	db     *pgxpool.Pool
+	logger *logrus.Logger
+}
+
+func NewAdminStorage(db *pgxpool.Pool, logger *logrus.Logger) *Admin {
+	return &Admin{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// SearchUsers ищет пользователей по точному id или по вхождению в username и email
+func (a *Admin) SearchUsers(c context.Context, query string, limit int) ([]models.AdminUser, error) {
+	// Строку, не являющуюся UUID, сравниваем с id как NULL
+	var userID *uuid.UUID
+	if id, err := uuid.Parse(query); err == nil {
+		userID = &id
+	}
+
+	rows, err := a.db.Query(c, `
+		SELECT `+adminUserColumns+`
+		FROM users
+		WHERE id = $1 OR username ILIKE '%' || $2 || '%' ESCAPE '\' OR email ILIKE '%' || $2 || '%' ESCAPE '\'
+		ORDER BY username
+		LIMIT $3`,
+		userID, likeEscaper.Replace(query), limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	users := make([]models.AdminUser, 0)
+	for rows.Next() {
+		user, err := scanAdminUser(rows)
+		if err != nil {
+			return nil, err
+		}
+		users = append(users, user)
+	}
+	return users, rows.Err()
+}
+
+// GetUser возвращает пользователя по id
+func (a *Admin) GetUser(c context.Context, userID uuid.UUID) (models.AdminUser, error) {
+	return getAdminUser(c, a.db, userID)
+}
+
+// SetFrozen замораживает или размораживает аккаунт и пишет действие в журнал аудита.
+// Кошелёк блокируется, чтобы заморозка не пересеклась с выполняющейся операцией
+func (a *Admin) SetFrozen(c context.Context, actorID, userID uuid.UUID, frozen bool, reason string) (models.AdminUser, error) {
+	tx, err := a.db.Begin(c)
+	if err != nil {
+		return models.AdminUser{}, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(c)
+
+	if _, err := lockWalletForUpdate(c, tx, userID); err != nil {
+		if errors.Is(err, errs.ErrWalletNotFound) {
+			return models.AdminUser{}, errs.ErrAccountNotFound
+		}
+		return models.AdminUser{}, err
+	}
+
+	action := models.AuditUnfreeze
+	query := `UPDATE users SET frozen_at = NULL, frozen_reason = NULL WHERE id = $1`
+	args := []interface{}{userID}
+	if frozen {
+		action = models.AuditFreeze
+		query = `UPDATE users SET frozen_at = COALESCE(frozen_at, NOW()), frozen_reason = $2 WHERE id = $1`
+		args = append(args, reason)
+	}
+	if _, err := tx.Exec(c, query, args...); err != nil {
+		return models.AdminUser{}, err
+	}
+
+	if err := insertAuditEntry(c, tx, actorID, action, userID, map[string]interface{}{"reason": reason}); err != nil {
+		return models.AdminUser{}, err
+	}
+
+	user, err := getAdminUser(c, tx, userID)
+	if err != nil {
+		return models.AdminUser{}, err
+	}
+
+	if err := tx.Commit(c); err != nil {
+		return models.AdminUser{}, err
+	}
+	return user, nil
+}
+
+// Unlock снимает блокировку входа после неудачных попыток и сбрасывает счётчики, чтобы следующая блокировка
+// снова была минимальной. Действие пишется в журнал аудита
+func (a *Admin) Unlock(c context.Context, actorID, userID uuid.UUID) (models.AdminUser, error) {
+	tx, err := a.db.Begin(c)
+	if err != nil {
+		return models.AdminUser{}, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(c)
+
+	var lockedUntil *time.Time
+	err = tx.QueryRow(c, `SELECT locked_until FROM users WHERE id = $1 FOR UPDATE`, userID).Scan(&lockedUntil)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.AdminUser{}, errs.ErrAccountNotFound
+		}
+		return models.AdminUser{}, err
+	}
+
+	_, err = tx.Exec(c, `
+		UPDATE users
+		SET failed_logins = 0, last_failed_login_at = NULL, lockout_count = 0, locked_until = NULL
+		WHERE id = $1`, userID)
+	if err != nil {
+		return models.AdminUser{}, err
+	}
+
+	if err := insertAuditEntry(c, tx, actorID, models.AuditUnlock, userID, map[string]interface{}{"locked_until": lockedUntil}); err != nil {
+		return models.AdminUser{}, err
+	}
+
+	user, err := getAdminUser(c, tx, userID)
+	if err != nil {
+		return models.AdminUser{}, err
+	}
+
+	if err := tx.Commit(c); err != nil {
+		return models.AdminUser{}, err
+	}
+	return user, nil
+}
+
+// AdjustBalance вручную зачисляет (amount > 0) или списывает (amount < 0) средства.
+// Корректировка разрешена и для замороженного аккаунта, попадает в историю операций, outbox и журнал аудита
+func (a *Admin) AdjustBalance(
+	c context.Context,
+	actorID uuid.UUID,
+	userID uuid.UUID,
+	currency string,
+	amount decimal.Decimal,
+	reason string,
+) (models.OperationResult, error) {
+	tx, err := a.db.Begin(c)
+	if err != nil {
+		return models.OperationResult{}, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(c)
+
+	// Проверяем, что валюта поддерживается
+	cur, err := getCurrency(c, tx, currency)
+	if err != nil {
+		return models.OperationResult{}, err
+	}
+	absAmount, err := roundAmount(cur, amount.Abs())
+	if err != nil {
+		return models.OperationResult{}, err
+	}
+
+	wallet, err := lockWalletForUpdate(c, tx, userID)
+	if err != nil {
+		if errors.Is(err, errs.ErrWalletNotFound) {
+			return models.OperationResult{}, errs.ErrAccountNotFound
+		}
+		return models.OperationResult{}, err
+	}
+	walletID := wallet.id
+
+	var balanceAfter decimal.Decimal
+	if amount.IsNegative() {
+		balanceAfter, err = debitBalance(c, tx, walletID, cur.Code, absAmount)
+		amount = absAmount.Neg()
+	} else {
+		balanceAfter, err = creditBalance(c, tx, walletID, cur.Code, absAmount)
+		amount = absAmount
+	}
+	if err != nil {
+		return models.OperationResult{}, err
+	}
+
+	// Записываем корректировку в журнал операций, outbox и журнал аудита
+	transactionID, err := insertTransaction(c, tx, walletID, userID, models.Transaction{
+		Type:         models.TransactionAdjustment,
+		Currency:     cur.Code,
+		Amount:       amount,
+		BalanceAfter: balanceAfter,
+	})
+	if err != nil {
+		return models.OperationResult{}, err
+	}
+
+	err = insertOutboxEvent(c, tx, models.EventWalletAdjusted, userID, models.WalletOperationEvent{
+		UserID:        userID,
+		TransactionID: transactionID,
+		Currency:      cur.Code,
+		Amount:        amount,
+		BalanceAfter:  balanceAfter,
+	})
+	if err != nil {
+		return models.OperationResult{}, err
+	}
+
+	err = insertAuditEntry(c, tx, actorID, models.AuditAdjustment, userID, map[string]interface{}{
+		"currency":      cur.Code,
+		"amount":        amount,
+		"balance_after": balanceAfter,
+		"reason":        reason,
+	})
+	if err != nil {
+		return models.OperationResult{}, err
+	}
+
+	response, err := loadBalances(c, tx, walletID)
+	if err != nil {
+		return models.OperationResult{}, err
+	}
+
+	err = insertWebhookEvent(c, tx, models.EventWalletAdjusted, userID, models.WalletChangedWebhook{
+		UserID:   userID,
+		Currency: cur.Code,
+		Amount:   amount,
+		Balance:  response,
+	})
+	if err != nil {
+		return models.OperationResult{}, err
+	}
+
+	if err := tx.Commit(c); err != nil {
+		return models.OperationResult{}, err
+	}
+	return models.OperationResult{Currency: cur.Code, Amount: amount, Balance: response}, nil
+}
+
+func getAdminUser(c context.Context, q querier, userID uuid.UUID) (models.AdminUser, error) {
+	user, err := scanAdminUser(q.QueryRow(c, `SELECT `+adminUserColumns+` FROM users WHERE id = $1`, userID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.AdminUser{}, errs.ErrAccountNotFound
+		}
+		return models.AdminUser{}, err
+	}
+	return user, nil
+}
+
+func scanAdminUser(row pgx.Row) (models.AdminUser, error) {
+	var user models.AdminUser
+	err := row.Scan(
+		&user.ID,
+		&user.Username,
+		&user.Email,
+		&user.Role,
+		&user.Tier,
+		&user.FrozenAt,
+		&user.FrozenReason,
+		&user.LockedUntil,
+		&user.CreatedAt,
+	)
+	return user, err
+}
+
+// insertAuditEntry пишет действие сотрудника в журнал аудита
+func insertAuditEntry(c context.Context, tx pgx.Tx, actorID uuid.UUID, action string, targetID uuid.UUID, details map[string]interface{}) error {
+	data, err := json.Marshal(details)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(c, `
+		INSERT INTO admin_audit_log (actor_user_id, action, target_user_id, details)
+		VALUES ($1, $2, $3, $4)`,
+		actorID, action, targetID, data,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to write audit log: %w", err)
+	}
+	return nil
+}
@@ -48,58 +48,94 @@ func handlePgError(err error) error {
 	return err
 }
 
-func (s *Auth) CreateUser(c context.Context, username, email, passwordHash string) error {
+// CreateUser создаёт пользователя с пустым кошельком и возвращает его ID
+func (s *Auth) CreateUser(c context.Context, username, email, passwordHash string) (uuid.UUID, error) {
 	tx, err := s.db.Begin(c)
 	if err != nil {
-		return fmt.Errorf("failed to begin transaction: %w", err)
+		return uuid.Nil, fmt.Errorf("failed to begin transaction: %w", err)
 	}
 	defer tx.Rollback(c)
 
 	// Вставляем пользователя
 	_, err = tx.Exec(c, "INSERT INTO users (username, password_hash, email) VALUES ($1, $2, $3)", username, passwordHash, email)
 	if err != nil {
-		return handlePgError(err)
+		return uuid.Nil, handlePgError(err)
 	}
 
 	// Получаем ID вставленного пользователя
 	var userID uuid.UUID
 	err = tx.QueryRow(c, "SELECT id FROM users WHERE username = $1", username).Scan(&userID)
 	if err != nil {
-		return err
+		return uuid.Nil, err
 	}
 
 	// Создаем кошелек для пользователя
 	_, err = tx.Exec(c, "INSERT INTO wallets (user_id) VALUES ($1)", userID)
 	if err != nil {
-		return err
+		return uuid.Nil, err
+	}
+
+	err = insertOutboxEvent(c, tx, models.EventUserRegistered, userID, models.UserRegisteredEvent{
+		UserID:   userID,
+		Username: username,
+		Email:    email,
+	})
+	if err != nil {
+		return uuid.Nil, err
 	}
 
 	// Фиксируем транзакцию
 	if err := tx.Commit(c); err != nil {
-		return err
+		return uuid.Nil, err
 	}
 
-	return nil
+	return userID, nil
 }
 
 func (s *Auth) GetUserByUsername(c context.Context, username string) (*models.UserOutput, error) {
-	var user models.UserOutput
+	return scanUser(s.db.QueryRow(c, `SELECT `+userColumns+` FROM users WHERE username = $1`, username))
+}
 
-	query := `SELECT id, username, email, password_hash, created_at FROM users WHERE username = $1`
-	err := s.db.QueryRow(c, query, username).Scan(
+// GetUserByEmail возвращает пользователя по email
+func (s *Auth) GetUserByEmail(c context.Context, email string) (*models.UserOutput, error) {
+	return scanUser(s.db.QueryRow(c, `SELECT `+userColumns+` FROM users WHERE email = $1`, email))
+}
+
+// userColumns столбцы users в порядке scanUser
+const userColumns = `id, username, email, password_hash, role, locked_until,
+	totp_enabled_at IS NOT NULL, email_verified_at IS NOT NULL, created_at`
+
+func scanUser(row pgx.Row) (*models.UserOutput, error) {
+	var user models.UserOutput
+	err := row.Scan(
 		&user.ID,
 		&user.Username,
 		&user.Email,
 		&user.PasswordHash,
+		&user.Role,
+		&user.LockedUntil,
+		&user.MFAEnabled,
+		&user.EmailVerified,
 		&user.CreatedAt,
 	)
-
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, errs.ErrUserNotFound
 		}
 		return nil, err
 	}
-
 	return &user, nil
 }
+
+// GetUserTier возвращает уровень пользователя, от которого зависят комиссии
+func (s *Auth) GetUserTier(c context.Context, userID uuid.UUID) (string, error) {
+	var tier string
+	err := s.db.QueryRow(c, `SELECT tier FROM users WHERE id = $1`, userID).Scan(&tier)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", errs.ErrUserNotFound
+		}
+		return "", err
+	}
+	return tier, nil
+}
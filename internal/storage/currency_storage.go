@@ -0,0 +1,55 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/shopspring/decimal"
+
+	"gw-currency-wallet/internal/errs"
+	"gw-currency-wallet/internal/storage/models"
+)
+
+// querier общий интерфейс пула соединений и транзакции
+type querier interface {
+	Exec(c context.Context, sql string, arguments ...interface{}) (pgconn.CommandTag, error)
+	Query(c context.Context, sql string, args ...interface{}) (pgx.Rows, error)
+	QueryRow(c context.Context, sql string, args ...interface{}) pgx.Row
+}
+
+// getCurrency возвращает включённую валюту из реестра
+func getCurrency(c context.Context, q querier, code string) (models.Currency, error) {
+	var currency models.Currency
+	err := q.QueryRow(c,
+		`SELECT code, minor_units, enabled FROM currencies WHERE code = $1`,
+		strings.ToUpper(code),
+	).Scan(&currency.Code, &currency.MinorUnits, &currency.Enabled)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.Currency{}, errs.ErrUnsupportedCurrency
+		}
+		return models.Currency{}, err
+	}
+
+	if !currency.Enabled {
+		return models.Currency{}, errs.ErrUnsupportedCurrency
+	}
+	return currency, nil
+}
+
+// GetCurrency возвращает включённую валюту из реестра
+func (w *Wallet) GetCurrency(c context.Context, code string) (models.Currency, error) {
+	return getCurrency(c, w.db, code)
+}
+
+// roundAmount округляет сумму до точности валюты. Сумма, обнулившаяся после округления, недопустима
+func roundAmount(currency models.Currency, amount decimal.Decimal) (decimal.Decimal, error) {
+	rounded := amount.Round(currency.MinorUnits)
+	if !rounded.IsPositive() {
+		return decimal.Zero, errs.ErrInvalidAmount
+	}
+	return rounded, nil
+}
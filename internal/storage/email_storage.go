@@ -0,0 +1,120 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+
+	"gw-currency-wallet/internal/errs"
+	"gw-currency-wallet/internal/storage/models"
+)
+
+// CreateUserToken сохраняет токен из письма. Неиспользованные токены того же назначения перестают действовать,
+// поэтому работает только ссылка из последнего письма
+func (s *Auth) CreateUserToken(c context.Context, token models.UserToken) error {
+	tx, err := s.db.Begin(c)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(c)
+
+	_, err = tx.Exec(c, `DELETE FROM user_tokens WHERE user_id = $1 AND purpose = $2 AND used_at IS NULL`, token.UserID, token.Purpose)
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec(c, `
+		INSERT INTO user_tokens (user_id, purpose, token_hash, email, expires_at)
+		VALUES ($1, $2, $3, $4, $5)`,
+		token.UserID, token.Purpose, token.TokenHash, token.Email, token.ExpiresAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save user token: %w", err)
+	}
+	return tx.Commit(c)
+}
+
+// VerifyEmail гасит токен подтверждения и отмечает email пользователя подтверждённым
+func (s *Auth) VerifyEmail(c context.Context, tokenHash string) (uuid.UUID, error) {
+	tx, err := s.db.Begin(c)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(c)
+
+	userID, err := useUserToken(c, tx, models.TokenEmailVerification, tokenHash)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	if err := markEmailVerified(c, tx, userID); err != nil {
+		return uuid.Nil, err
+	}
+	return userID, tx.Commit(c)
+}
+
+// ResetPassword гасит токен сброса, меняет пароль, снимает блокировку входа и отзывает все refresh-токены.
+// Переход по ссылке из письма доказывает владение адресом, поэтому email тоже отмечается подтверждённым
+func (s *Auth) ResetPassword(c context.Context, tokenHash, passwordHash string) (uuid.UUID, error) {
+	tx, err := s.db.Begin(c)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(c)
+
+	userID, err := useUserToken(c, tx, models.TokenPasswordReset, tokenHash)
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	_, err = tx.Exec(c, `
+		UPDATE users
+		SET password_hash = $2, failed_logins = 0, last_failed_login_at = NULL, lockout_count = 0, locked_until = NULL
+		WHERE id = $1`,
+		userID, passwordHash,
+	)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	if err := markEmailVerified(c, tx, userID); err != nil {
+		return uuid.Nil, err
+	}
+	if err := revokeUserRefreshTokens(c, tx, userID); err != nil {
+		return uuid.Nil, err
+	}
+	return userID, tx.Commit(c)
+}
+
+// useUserToken гасит действующий токен и возвращает его владельца. Токен, выданный на прежний email
+// пользователя, недействителен
+func useUserToken(c context.Context, tx pgx.Tx, purpose, tokenHash string) (uuid.UUID, error) {
+	var userID uuid.UUID
+	err := tx.QueryRow(c, `
+		UPDATE user_tokens t
+		SET used_at = NOW()
+		FROM users u
+		WHERE t.token_hash = $1 AND t.purpose = $2 AND t.used_at IS NULL AND t.expires_at > NOW()
+			AND u.id = t.user_id AND u.email = t.email
+		RETURNING t.user_id`,
+		tokenHash, purpose,
+	).Scan(&userID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return uuid.Nil, errs.ErrInvalidEmailToken
+		}
+		return uuid.Nil, err
+	}
+	return userID, nil
+}
+
+func markEmailVerified(c context.Context, q querier, userID uuid.UUID) error {
+	_, err := q.Exec(c, `UPDATE users SET email_verified_at = NOW() WHERE id = $1 AND email_verified_at IS NULL`, userID)
+	return err
+}
+
+// revokeUserRefreshTokens отзывает все refresh-токены пользователя, то есть завершает все его сессии
+func revokeUserRefreshTokens(c context.Context, q querier, userID uuid.UUID) error {
+	_, err := q.Exec(c, `UPDATE refresh_tokens SET revoked_at = NOW() WHERE user_id = $1 AND revoked_at IS NULL`, userID)
+	return err
+}
@@ -0,0 +1,82 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+
+	"gw-currency-wallet/internal/errs"
+	"gw-currency-wallet/internal/storage/models"
+)
+
+// RecordLoginAttempt пишет попытку входа в журнал login_attempts
+func (s *Auth) RecordLoginAttempt(c context.Context, attempt models.LoginAttempt) error {
+	_, err := s.db.Exec(c, `
+		INSERT INTO login_attempts (username, user_id, ip, result)
+		VALUES ($1, $2, $3, $4)`,
+		attempt.Username, attempt.UserID, attempt.IP, attempt.Result,
+	)
+	return err
+}
+
+// CountIPLoginFailures число неудачных входов с ip начиная с since и время самого раннего из них
+func (s *Auth) CountIPLoginFailures(c context.Context, ip string, since time.Time) (int, time.Time, error) {
+	var count int
+	var oldest *time.Time
+	err := s.db.QueryRow(c, `
+		SELECT COUNT(*), MIN(created_at)
+		FROM login_attempts
+		WHERE ip = $1 AND result = $2 AND created_at > $3`,
+		ip, models.LoginInvalidCredentials, since,
+	).Scan(&count, &oldest)
+	if err != nil || oldest == nil {
+		return 0, time.Time{}, err
+	}
+	return count, *oldest, nil
+}
+
+// RegisterLoginFailure увеличивает счётчик неудачных входов пользователя. Если последний неудачный вход
+// был раньше since, счёт начинается заново. Возвращает неудачные входы подряд и число блокировок подряд
+func (s *Auth) RegisterLoginFailure(c context.Context, userID uuid.UUID, since time.Time) (int, int, error) {
+	var failures, lockouts int
+	err := s.db.QueryRow(c, `
+		UPDATE users
+		SET failed_logins = CASE WHEN last_failed_login_at > $2 THEN failed_logins + 1 ELSE 1 END,
+			last_failed_login_at = NOW()
+		WHERE id = $1
+		RETURNING failed_logins, lockout_count`,
+		userID, since,
+	).Scan(&failures, &lockouts)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, 0, errs.ErrUserNotFound
+		}
+		return 0, 0, err
+	}
+	return failures, lockouts, nil
+}
+
+// LockUser блокирует вход до until и начинает счёт неудачных входов заново
+func (s *Auth) LockUser(c context.Context, userID uuid.UUID, until time.Time) error {
+	_, err := s.db.Exec(c, `
+		UPDATE users
+		SET locked_until = $2, lockout_count = lockout_count + 1, failed_logins = 0
+		WHERE id = $1`,
+		userID, until,
+	)
+	return err
+}
+
+// ResetLoginFailures сбрасывает счётчики после успешного входа
+func (s *Auth) ResetLoginFailures(c context.Context, userID uuid.UUID) error {
+	_, err := s.db.Exec(c, `
+		UPDATE users
+		SET failed_logins = 0, last_failed_login_at = NULL, lockout_count = 0, locked_until = NULL
+		WHERE id = $1 AND (failed_logins > 0 OR lockout_count > 0 OR locked_until IS NOT NULL)`,
+		userID,
+	)
+	return err
+}
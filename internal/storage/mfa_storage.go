@@ -0,0 +1,135 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+
+	"gw-currency-wallet/internal/errs"
+	"gw-currency-wallet/internal/storage/models"
+)
+
+// GetMFA возвращает настройки TOTP пользователя
+func (s *Auth) GetMFA(c context.Context, userID uuid.UUID) (models.UserMFA, error) {
+	var mfa models.UserMFA
+	err := s.db.QueryRow(c, `SELECT totp_secret, totp_enabled_at FROM users WHERE id = $1`, userID).
+		Scan(&mfa.Secret, &mfa.EnabledAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.UserMFA{}, errs.ErrUserNotFound
+		}
+		return models.UserMFA{}, err
+	}
+	return mfa, nil
+}
+
+// SetPendingMFASecret сохраняет новый секрет до подтверждения, заменяя неподтверждённый
+func (s *Auth) SetPendingMFASecret(c context.Context, userID uuid.UUID, secret string) error {
+	tag, err := s.db.Exec(c, `
+		UPDATE users
+		SET totp_secret = $2, totp_last_step = NULL
+		WHERE id = $1 AND totp_enabled_at IS NULL`,
+		userID, secret,
+	)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return errs.ErrMFAAlreadyEnabled
+	}
+	return nil
+}
+
+// EnableMFA включает 2FA и заменяет коды восстановления новыми
+func (s *Auth) EnableMFA(c context.Context, userID uuid.UUID, recoveryCodeHashes []string) error {
+	tx, err := s.db.Begin(c)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(c)
+
+	tag, err := tx.Exec(c, `
+		UPDATE users
+		SET totp_enabled_at = NOW()
+		WHERE id = $1 AND totp_secret IS NOT NULL AND totp_enabled_at IS NULL`,
+		userID,
+	)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return errs.ErrMFAAlreadyEnabled
+	}
+
+	if err := replaceRecoveryCodes(c, tx, userID, recoveryCodeHashes); err != nil {
+		return err
+	}
+	return tx.Commit(c)
+}
+
+// DisableMFA отключает 2FA и удаляет секрет и коды восстановления
+func (s *Auth) DisableMFA(c context.Context, userID uuid.UUID) error {
+	tx, err := s.db.Begin(c)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(c)
+
+	_, err = tx.Exec(c, `
+		UPDATE users
+		SET totp_secret = NULL, totp_enabled_at = NULL, totp_last_step = NULL
+		WHERE id = $1`,
+		userID,
+	)
+	if err != nil {
+		return err
+	}
+	if err := replaceRecoveryCodes(c, tx, userID, nil); err != nil {
+		return err
+	}
+	return tx.Commit(c)
+}
+
+// UseTOTPStep запоминает интервал принятого кода. false — код этого или более позднего интервала уже использован
+func (s *Auth) UseTOTPStep(c context.Context, userID uuid.UUID, step int64) (bool, error) {
+	tag, err := s.db.Exec(c, `
+		UPDATE users
+		SET totp_last_step = $2
+		WHERE id = $1 AND (totp_last_step IS NULL OR totp_last_step < $2)`,
+		userID, step,
+	)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+// UseRecoveryCode гасит код восстановления. false — кода нет или он уже использован
+func (s *Auth) UseRecoveryCode(c context.Context, userID uuid.UUID, codeHash string) (bool, error) {
+	tag, err := s.db.Exec(c, `
+		UPDATE mfa_recovery_codes
+		SET used_at = NOW()
+		WHERE user_id = $1 AND code_hash = $2 AND used_at IS NULL`,
+		userID, codeHash,
+	)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+func replaceRecoveryCodes(c context.Context, tx pgx.Tx, userID uuid.UUID, codeHashes []string) error {
+	if _, err := tx.Exec(c, `DELETE FROM mfa_recovery_codes WHERE user_id = $1`, userID); err != nil {
+		return err
+	}
+	for _, hash := range codeHashes {
+		_, err := tx.Exec(c, `INSERT INTO mfa_recovery_codes (user_id, code_hash) VALUES ($1, $2)`, userID, hash)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,48 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Действия сотрудников, которые пишутся в журнал аудита
+const (
+	AuditFreeze     = "freeze"
+	AuditUnfreeze   = "unfreeze"
+	AuditAdjustment = "adjustment"
+	AuditUnlock     = "unlock"
+)
+
+// AdminUser сведения о пользователе для сотрудников поддержки
+type AdminUser struct {
+	ID           uuid.UUID  `json:"id"`
+	Username     string     `json:"username"`
+	Email        string     `json:"email"`
+	Role         string     `json:"role"`
+	Tier         string     `json:"tier"`
+	FrozenAt     *time.Time `json:"frozen_at,omitempty"`
+	FrozenReason *string    `json:"frozen_reason,omitempty"`
+	LockedUntil  *time.Time `json:"locked_until,omitempty"` // Вход заблокирован после неудачных попыток
+	CreatedAt    time.Time  `json:"created_at"`
+}
+
+// UserSearchQuery поиск пользователя по id, username или email
+type UserSearchQuery struct {
+	Query string `form:"q" validate:"required,min=2,max=254"`
+}
+
+type UsersResponse struct {
+	Users []AdminUser `json:"users"`
+}
+
+type FreezeRequest struct {
+	Reason string `json:"reason" validate:"required,max=500"`
+}
+
+// AdjustmentRequest ручная корректировка баланса. Положительная сумма зачисляется, отрицательная — списывается
+type AdjustmentRequest struct {
+	Currency string  `json:"currency" validate:"required,len=3,alpha"`
+	Amount   float64 `json:"amount" validate:"required,number"`
+	Reason   string  `json:"reason" validate:"required,max=500"`
+}
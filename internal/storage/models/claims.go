@@ -7,5 +7,23 @@ import (
 type Claims struct {
 	UserID   string `json:"user_id"`
 	Username string `json:"username"`
+	Role     string `json:"role"`
 	jwt.RegisteredClaims
 }
+
+// JWK публичный ключ для проверки подписи токенов (RFC 7517)
+type JWK struct {
+	Kty string `json:"kty"`
+	KID string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+// JWKS набор публичных ключей, отдаётся на /.well-known/jwks.json
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
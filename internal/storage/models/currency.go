@@ -0,0 +1,8 @@
+package models
+
+// Currency валюта из реестра currencies
+type Currency struct {
+	Code       string `json:"code"`
+	MinorUnits int32  `json:"minor_units"`
+	Enabled    bool   `json:"enabled"`
+}
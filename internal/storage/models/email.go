@@ -0,0 +1,39 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Назначения одноразовых токенов из писем
+const (
+	TokenEmailVerification = "email_verification"
+	TokenPasswordReset     = "password_reset"
+)
+
+// UserToken токен из письма. Хранится хэш; Email — адрес, на который ушло письмо
+type UserToken struct {
+	UserID    uuid.UUID
+	Purpose   string
+	TokenHash string
+	Email     string
+	ExpiresAt time.Time
+}
+
+type VerifyEmailRequest struct {
+	Token string `json:"token" validate:"required,max=128"`
+}
+
+type ForgotPasswordRequest struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+type ResetPasswordRequest struct {
+	Token    string `json:"token" validate:"required,max=128"`
+	Password string `json:"password" validate:"required,min=8,max=16"`
+}
+
+type EmailResponse struct {
+	Message string `json:"message"`
+}
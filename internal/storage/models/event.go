@@ -0,0 +1,75 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// Типы доменных событий для outbox и webhooks
+const (
+	EventUserRegistered    = "user.registered"
+	EventWalletDeposited   = "wallet.deposited"
+	EventWalletWithdrawn   = "wallet.withdrawn"
+	EventWalletExchanged   = "wallet.exchanged"
+	EventWalletAdjusted    = "wallet.adjusted"
+	EventWalletTransferred = "wallet.transferred"
+)
+
+// OutboxEvent событие из таблицы outbox. AggregateID — ключ сообщения в Kafka
+type OutboxEvent struct {
+	ID          int64           `json:"-"`
+	EventID     uuid.UUID       `json:"event_id"`
+	Type        string          `json:"event_type"`
+	AggregateID uuid.UUID       `json:"aggregate_id"`
+	Payload     json.RawMessage `json:"data"`
+	CreatedAt   time.Time       `json:"occurred_at"`
+	Attempts    int             `json:"-"`
+}
+
+// OutboxResult итог публикации пачки событий. События, не попавшие ни в один список, остаются в очереди без изменений
+type OutboxResult struct {
+	Published []int64
+	Failed    map[int64]error
+}
+
+// UserRegisteredEvent данные события user.registered
+type UserRegisteredEvent struct {
+	UserID   uuid.UUID `json:"user_id"`
+	Username string    `json:"username"`
+	Email    string    `json:"email"`
+}
+
+// WalletOperationEvent данные событий wallet.deposited, wallet.withdrawn и wallet.adjusted.
+// Для корректировки Amount со знаком: списание отрицательное
+type WalletOperationEvent struct {
+	UserID        uuid.UUID       `json:"user_id"`
+	TransactionID uuid.UUID       `json:"transaction_id"`
+	Currency      string          `json:"currency"`
+	Amount        decimal.Decimal `json:"amount"`
+	BalanceAfter  decimal.Decimal `json:"balance_after"`
+}
+
+// WalletTransferredEvent данные события wallet.transferred. Событие пишется для обоих участников:
+// у отправителя Amount отрицательный, у получателя положительный
+type WalletTransferredEvent struct {
+	UserID             uuid.UUID       `json:"user_id"`
+	TransactionID      uuid.UUID       `json:"transaction_id"`
+	CounterpartyUserID uuid.UUID       `json:"counterparty_user_id"`
+	Currency           string          `json:"currency"`
+	Amount             decimal.Decimal `json:"amount"`
+	BalanceAfter       decimal.Decimal `json:"balance_after"`
+}
+
+// WalletExchangedEvent данные события wallet.exchanged
+type WalletExchangedEvent struct {
+	UserID          uuid.UUID       `json:"user_id"`
+	TransactionID   uuid.UUID       `json:"transaction_id"`
+	FromCurrency    string          `json:"from_currency"`
+	ToCurrency      string          `json:"to_currency"`
+	Amount          decimal.Decimal `json:"amount"`
+	ExchangedAmount decimal.Decimal `json:"exchanged_amount"`
+	Fee             decimal.Decimal `json:"fee"`
+}
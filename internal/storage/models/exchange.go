@@ -1,20 +1,84 @@
 package models
 
-import "github.com/shopspring/decimal"
+import (
+	"strings"
+	"time"
 
-// ExchangeRequest структура запроса на обмен валют
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// ExchangeRequest структура запроса на обмен валют.
+// Если передан QuoteID, обмен выполняется по зафиксированному курсу, остальные поля можно не указывать
 type ExchangeRequest struct {
+	FromCurrency string  `json:"from_currency" validate:"required_without=QuoteID,omitempty,len=3,alpha"`
+	ToCurrency   string  `json:"to_currency" validate:"required_without=QuoteID,omitempty,len=3,alpha"`
+	Amount       float64 `json:"amount" validate:"required_without=QuoteID,omitempty,number,gt=0"`
+	QuoteID      string  `json:"quote_id,omitempty" validate:"omitempty,uuid"`
+}
+
+// QuoteRequest структура запроса на фиксацию курса обмена
+type QuoteRequest struct {
 	FromCurrency string  `json:"from_currency" validate:"required,len=3,alpha"`
 	ToCurrency   string  `json:"to_currency" validate:"required,len=3,alpha"`
 	Amount       float64 `json:"amount" validate:"required,number,gt=0"`
 }
 
+// ExchangeQuote расчёт обмена. ToAmount — сумма к зачислению за вычетом комиссии Fee (в валюте FeeCurrency).
+// Сохранённая котировка действует до ExpiresAt и только один раз
+type ExchangeQuote struct {
+	QuoteID      uuid.UUID       `json:"quote_id"`
+	FromCurrency string          `json:"from_currency"`
+	ToCurrency   string          `json:"to_currency"`
+	Rate         decimal.Decimal `json:"rate"`
+	FromAmount   decimal.Decimal `json:"from_amount"`
+	ToAmount     decimal.Decimal `json:"to_amount"`
+	Fee          decimal.Decimal `json:"fee"`
+	FeeCurrency  string          `json:"fee_currency"`
+	ExpiresAt    time.Time       `json:"expires_at"`
+}
+
+// Matches проверяет, что явно указанные в запросе параметры обмена совпадают с котировкой.
+// Сумма сравнивается после округления до minorUnits исходной валюты
+func (q ExchangeQuote) Matches(req ExchangeRequest, minorUnits int32) bool {
+	if req.FromCurrency != "" && !strings.EqualFold(req.FromCurrency, q.FromCurrency) {
+		return false
+	}
+	if req.ToCurrency != "" && !strings.EqualFold(req.ToCurrency, q.ToCurrency) {
+		return false
+	}
+	if req.Amount != 0 && !decimal.NewFromFloat(req.Amount).Round(minorUnits).Equal(q.FromAmount) {
+		return false
+	}
+	return true
+}
+
+// ExchangeRates курсы валют и момент их получения от gw-exchanger.
+// Stale — курсы старше срока свежести: gw-exchanger недоступен или обновление ещё не завершилось
+type ExchangeRates struct {
+	Rates map[string]string `json:"rates"`
+	AsOf  time.Time         `json:"as_of"`
+	Stale bool              `json:"stale"`
+}
+
+// ExchangeRate курс валютной пары и момент его получения от gw-exchanger
+type ExchangeRate struct {
+	Rate  string    `json:"rate"`
+	AsOf  time.Time `json:"as_of"`
+	Stale bool      `json:"stale"`
+}
+
 type ExchangeRatesResponse struct {
 	Rates map[string]string `json:"rates"`
+	AsOf  time.Time         `json:"as_of"`
+	Stale bool              `json:"stale"`
 }
 
 type ExchangeCurrencyResponse struct {
 	Message         string          `json:"message"`
+	Rate            decimal.Decimal `json:"rate"`
 	ExchangedAmount decimal.Decimal `json:"exchanged_amount"`
+	Fee             decimal.Decimal `json:"fee"`
+	FeeCurrency     string          `json:"fee_currency"`
 	NewBalance      WalletResponse  `json:"new_balance"`
 }
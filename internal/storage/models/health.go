@@ -0,0 +1,21 @@
+package models
+
+// Статусы проверки здоровья
+const (
+	HealthStatusOK       = "ok"
+	HealthStatusFail     = "fail"
+	HealthStatusDraining = "draining"
+)
+
+// HealthResponse Ответ /healthz и /readyz
+type HealthResponse struct {
+	Status string                      `json:"status"`
+	Checks map[string]DependencyStatus `json:"checks,omitempty"`
+	Info   map[string]string           `json:"info,omitempty"`
+}
+
+// DependencyStatus Состояние одной зависимости
+type DependencyStatus struct {
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
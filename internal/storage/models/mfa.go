@@ -0,0 +1,35 @@
+package models
+
+import "time"
+
+// UserMFA настройки TOTP пользователя. Secret зашифрован; EnabledAt пустой, пока секрет не подтверждён первым кодом
+type UserMFA struct {
+	Secret    *string
+	EnabledAt *time.Time
+}
+
+// MFALoginRequest второй шаг входа: токен из ответа /auth/login и код из приложения или код восстановления
+type MFALoginRequest struct {
+	MFAToken string `json:"mfa_token" validate:"required"`
+	Code     string `json:"code" validate:"required,max=32"`
+}
+
+// MFACodeRequest код из приложения-аутентификатора или код восстановления
+type MFACodeRequest struct {
+	Code string `json:"code" validate:"required,max=32"`
+}
+
+// MFAEnrollResponse секрет для ручного ввода и ссылка otpauth:// для QR-кода
+type MFAEnrollResponse struct {
+	Secret     string `json:"secret"`
+	OTPAuthURI string `json:"otpauth_uri"`
+}
+
+// MFARecoveryCodesResponse коды восстановления показываются один раз, в базе хранятся только их хэши
+type MFARecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+type MFADisableResponse struct {
+	Message string `json:"message"`
+}
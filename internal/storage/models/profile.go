@@ -0,0 +1,29 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// UserProfile профиль текущего пользователя для /users/me
+type UserProfile struct {
+	ID            uuid.UUID `json:"id"`
+	Username      string    `json:"username"`
+	Email         string    `json:"email"`
+	EmailVerified bool      `json:"email_verified"`
+	Role          string    `json:"role"`
+	MFAEnabled    bool      `json:"mfa_enabled"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// UpdateProfileRequest изменяемые поля профиля. Смена email требует текущий пароль
+type UpdateProfileRequest struct {
+	Email           *string `json:"email" validate:"omitempty,email"`
+	CurrentPassword string  `json:"current_password" validate:"max=64"`
+}
+
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password" validate:"required,max=64"`
+	NewPassword     string `json:"new_password" validate:"required,min=8,max=16"`
+}
@@ -0,0 +1,18 @@
+package models
+
+// События потока /stream (Server-Sent Events)
+const (
+	StreamEventBalance   = "balance"   // Данные: GetBalanceResponse
+	StreamEventRates     = "rates"     // Данные: ExchangeRatesResponse
+	StreamEventHeartbeat = "heartbeat" // Данные: StreamHeartbeat
+)
+
+// StreamEvent событие для отправки клиенту потока
+type StreamEvent struct {
+	Name string
+	Data interface{}
+}
+
+type StreamHeartbeat struct {
+	Time int64 `json:"time"` // Unix-время сервера
+}
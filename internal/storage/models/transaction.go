@@ -0,0 +1,62 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// Типы операций в журнале кошелька
+const (
+	TransactionDeposit    = "deposit"
+	TransactionWithdraw   = "withdraw"
+	TransactionExchange   = "exchange"
+	TransactionTransfer   = "transfer"
+	TransactionAdjustment = "adjustment" // Ручная корректировка баланса сотрудником
+)
+
+// Transaction запись журнала операций кошелька.
+// Amount хранится со знаком: списания отрицательные, зачисления положительные.
+// Для обменов заполняются Counter* и Fee*, для переводов — CounterpartyUserID
+type Transaction struct {
+	ID                 uuid.UUID        `json:"id"`
+	Type               string           `json:"type"`
+	Currency           string           `json:"currency"`
+	Amount             decimal.Decimal  `json:"amount"`
+	BalanceAfter       decimal.Decimal  `json:"balance_after"`
+	CounterCurrency    *string          `json:"counter_currency,omitempty"`
+	CounterAmount      *decimal.Decimal `json:"counter_amount,omitempty"`
+	CounterpartyUserID *uuid.UUID       `json:"counterparty_user_id,omitempty"`
+	Fee                *decimal.Decimal `json:"fee,omitempty"`
+	FeeCurrency        *string          `json:"fee_currency,omitempty"`
+	CreatedAt          time.Time        `json:"created_at"`
+}
+
+// TransactionsQuery параметры запроса истории операций
+type TransactionsQuery struct {
+	Currency string    `form:"currency" validate:"omitempty,len=3,alpha"`
+	Type     string    `form:"type" validate:"omitempty,oneof=deposit withdraw exchange transfer adjustment"`
+	From     time.Time `form:"from" time_format:"2006-01-02T15:04:05Z07:00"`
+	To       time.Time `form:"to" time_format:"2006-01-02T15:04:05Z07:00"`
+	Limit    int       `form:"limit" validate:"omitempty,min=1,max=100"`
+	Cursor   string    `form:"cursor"`
+}
+
+// TransactionFilter фильтр выборки журнала на уровне хранилища.
+// Если задан AfterID, выбираются записи строго старше позиции (AfterCreatedAt, AfterID)
+type TransactionFilter struct {
+	Currency       string
+	Type           string
+	From           time.Time
+	To             time.Time
+	Limit          int
+	AfterCreatedAt time.Time
+	AfterID        uuid.UUID
+}
+
+// TransactionsResponse страница истории операций
+type TransactionsResponse struct {
+	Transactions []Transaction `json:"transactions"`
+	NextCursor   string        `json:"next_cursor,omitempty"`
+}
@@ -6,6 +6,13 @@ import (
 	"github.com/google/uuid"
 )
 
+// Роли пользователей
+const (
+	RoleUser    = "user"
+	RoleSupport = "support"
+	RoleAdmin   = "admin"
+)
+
 type UserRegister struct {
 	Username string `json:"username" validate:"required,min=5,max=16"`
 	Password string `json:"password" validate:"required,min=8,max=16"`
@@ -18,17 +25,42 @@ type UserLogin struct {
 }
 
 type UserOutput struct {
-	ID           uuid.UUID `json:"id"`
-	Username     string    `json:"username"`
-	Email        string    `json:"email"`
-	PasswordHash string    `json:"password_hash"`
-	CreatedAt    time.Time `json:"created_at"`
+	ID            uuid.UUID  `json:"id"`
+	Username      string     `json:"username"`
+	Email         string     `json:"email"`
+	PasswordHash  string     `json:"password_hash"`
+	Role          string     `json:"role"`
+	LockedUntil   *time.Time `json:"locked_until"`
+	MFAEnabled    bool       `json:"mfa_enabled"`
+	EmailVerified bool       `json:"email_verified"`
+	CreatedAt     time.Time  `json:"created_at"`
+}
+
+// Результаты попыток входа в журнале login_attempts
+const (
+	LoginSuccess            = "success"
+	LoginInvalidCredentials = "invalid_credentials"
+	LoginLocked             = "locked"
+)
+
+// LoginAttempt попытка входа; UserID nil, если пользователь не найден
+type LoginAttempt struct {
+	Username string
+	UserID   *uuid.UUID
+	IP       string
+	Result   string
 }
 
 type RegisterSuccessResponse struct {
 	Message string `json:"message"`
 }
 
+// LoginSuccessResponse короткоживущий access-токен и refresh-токен для его обновления.
+// Если у пользователя включена 2FA, /auth/login вместо токенов возвращает mfa_required и mfa_token для /auth/login/mfa
 type LoginSuccessResponse struct {
-	Token string `json:"token"`
+	Token        string `json:"token,omitempty"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+	ExpiresIn    int64  `json:"expires_in,omitempty"` // Срок действия access-токена в секундах
+	MFARequired  bool   `json:"mfa_required,omitempty"`
+	MFAToken     string `json:"mfa_token,omitempty"`
 }
@@ -1,20 +1,41 @@
 package models
 
 import (
+	"github.com/google/uuid"
 	"github.com/shopspring/decimal"
 )
 
-type WalletResponse struct {
-	BalanceRub decimal.Decimal `json:"balance_rub"`
-	BalanceUsd decimal.Decimal `json:"balance_usd"`
-	BalanceEur decimal.Decimal `json:"balance_eur"`
-}
+// WalletResponse балансы кошелька по кодам валют (ISO 4217)
+type WalletResponse map[string]decimal.Decimal
 
 type WalletTransaction struct {
 	Currency string  `json:"currency" validate:"required,len=3,alpha"`
 	Amount   float64 `json:"amount" validate:"required,number,gt=0"`
 }
 
+// TransferRequest структура запроса на перевод другому пользователю
+type TransferRequest struct {
+	Recipient string  `json:"recipient" validate:"required,max=254"` // username или email получателя
+	Currency  string  `json:"currency" validate:"required,len=3,alpha"`
+	Amount    float64 `json:"amount" validate:"required,number,gt=0"`
+}
+
+// OperationResult итог пополнения, списания или корректировки: код валюты из реестра,
+// сумма после округления (для корректировки со знаком) и новый баланс
+type OperationResult struct {
+	Currency string
+	Amount   decimal.Decimal
+	Balance  WalletResponse
+}
+
+// TransferResult итог перевода: код валюты из реестра, сумма после округления, баланс отправителя и ID получателя
+type TransferResult struct {
+	Currency    string
+	Amount      decimal.Decimal
+	Balance     WalletResponse
+	RecipientID uuid.UUID
+}
+
 type GetBalanceResponse struct {
 	Balance WalletResponse `json:"balance"`
 }
@@ -0,0 +1,94 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// Статусы доставки webhook
+const (
+	WebhookDeliveryPending   = "pending"
+	WebhookDeliveryDelivered = "delivered"
+	WebhookDeliveryDead      = "dead" // Попытки исчерпаны, доставку можно повторить вручную
+)
+
+// WebhookSubscription подписка пользователя на события кошелька.
+// Secret возвращается только при создании подписки
+type WebhookSubscription struct {
+	ID         uuid.UUID `json:"id"`
+	UserID     uuid.UUID `json:"-"`
+	URL        string    `json:"url"`
+	EventTypes []string  `json:"event_types"`
+	Secret     string    `json:"secret,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// WebhookRequest регистрация подписки
+type WebhookRequest struct {
+	URL        string   `json:"url" validate:"required,http_url,max=2048"`
+	EventTypes []string `json:"event_types" validate:"required,min=1,unique,dive,oneof=wallet.deposited wallet.withdrawn wallet.exchanged wallet.transferred wallet.adjusted"`
+}
+
+type WebhooksResponse struct {
+	Webhooks []WebhookSubscription `json:"webhooks"`
+}
+
+// WebhookEvent событие для рассылки подписчикам
+type WebhookEvent struct {
+	ID         uuid.UUID       `json:"event_id"`
+	Type       string          `json:"event_type"`
+	Data       json.RawMessage `json:"data"`
+	OccurredAt time.Time       `json:"occurred_at"`
+}
+
+// WebhookDelivery доставка события по подписке. URL и Secret заполняются только для отправки
+type WebhookDelivery struct {
+	ID             uuid.UUID       `json:"id"`
+	SubscriptionID uuid.UUID       `json:"subscription_id"`
+	EventID        uuid.UUID       `json:"event_id"`
+	EventType      string          `json:"event_type"`
+	Payload        json.RawMessage `json:"payload" swaggertype:"object"`
+	Status         string          `json:"status"`
+	Attempts       int             `json:"attempts"`
+	NextAttemptAt  time.Time       `json:"next_attempt_at"`
+	LastStatusCode *int            `json:"last_status_code,omitempty"`
+	LastError      *string         `json:"last_error,omitempty"`
+	CreatedAt      time.Time       `json:"created_at"`
+	DeliveredAt    *time.Time      `json:"delivered_at,omitempty"`
+	URL            string          `json:"-"`
+	Secret         string          `json:"-"`
+}
+
+// WebhookDeliveriesQuery фильтр истории доставок
+type WebhookDeliveriesQuery struct {
+	Status string `form:"status" validate:"omitempty,oneof=pending delivered dead"`
+}
+
+type WebhookDeliveriesResponse struct {
+	Deliveries []WebhookDelivery `json:"deliveries"`
+}
+
+// WalletChangedWebhook данные событий wallet.deposited, wallet.withdrawn, wallet.transferred и wallet.adjusted.
+// Перевод получают оба участника: отправитель — с полем Recipient, получатель — с полем Sender
+type WalletChangedWebhook struct {
+	UserID    uuid.UUID       `json:"user_id"`
+	Currency  string          `json:"currency"`
+	Amount    decimal.Decimal `json:"amount"`
+	Recipient string          `json:"recipient,omitempty"`
+	Sender    string          `json:"sender,omitempty"`
+	Balance   WalletResponse  `json:"balance"`
+}
+
+// ExchangeWebhook данные события wallet.exchanged
+type ExchangeWebhook struct {
+	UserID          uuid.UUID       `json:"user_id"`
+	FromCurrency    string          `json:"from_currency"`
+	ToCurrency      string          `json:"to_currency"`
+	Amount          decimal.Decimal `json:"amount"`
+	ExchangedAmount decimal.Decimal `json:"exchanged_amount"`
+	Fee             decimal.Decimal `json:"fee"`
+	Balance         WalletResponse  `json:"balance"`
+}
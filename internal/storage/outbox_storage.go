@@ -0,0 +1,110 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/sirupsen/logrus"
+
+	"gw-currency-wallet/internal/storage/models"
+)
+
+// outboxLockID ключ advisory-блокировки: публикацией занимается только один экземпляр сервиса,
+// иначе события одного пользователя могли бы уйти в Kafka не по порядку
+const outboxLockID = 7_420_011
+
+type Outbox struct {
+	db     *pgxpool.Pool
+	logger *logrus.Logger
+}
+
+func NewOutboxStorage(db *pgxpool.Pool, logger *logrus.Logger) *Outbox {
+	return &Outbox{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// ProcessOutbox выбирает до limit неопубликованных событий в порядке записи, передаёт их publish
+// и сохраняет результат. Возвращает число опубликованных событий.
+// Если блокировку держит другой экземпляр, ничего не делает
+func (o *Outbox) ProcessOutbox(c context.Context, limit int, publish func([]models.OutboxEvent) models.OutboxResult) (int, error) {
+	tx, err := o.db.Begin(c)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(c)
+
+	var locked bool
+	if err := tx.QueryRow(c, `SELECT pg_try_advisory_xact_lock($1)`, outboxLockID).Scan(&locked); err != nil {
+		return 0, err
+	}
+	if !locked {
+		return 0, nil
+	}
+
+	rows, err := tx.Query(c, `
+		SELECT id, event_id, event_type, aggregate_id, payload, created_at, attempts
+		FROM outbox
+		WHERE published_at IS NULL
+		ORDER BY id
+		LIMIT $1`, limit,
+	)
+	if err != nil {
+		return 0, err
+	}
+	events, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (models.OutboxEvent, error) {
+		var e models.OutboxEvent
+		err := row.Scan(&e.ID, &e.EventID, &e.Type, &e.AggregateID, &e.Payload, &e.CreatedAt, &e.Attempts)
+		return e, err
+	})
+	if err != nil {
+		return 0, err
+	}
+	if len(events) == 0 {
+		return 0, nil
+	}
+
+	result := publish(events)
+
+	if len(result.Published) > 0 {
+		_, err = tx.Exec(c, `UPDATE outbox SET published_at = NOW(), last_error = NULL WHERE id = ANY($1)`, result.Published)
+		if err != nil {
+			return 0, err
+		}
+	}
+	for id, publishErr := range result.Failed {
+		_, err = tx.Exec(c, `UPDATE outbox SET attempts = attempts + 1, last_error = $2 WHERE id = $1`, id, publishErr.Error())
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	// Если коммит не пройдёт, события будут отправлены повторно: доставка at-least-once
+	if err := tx.Commit(c); err != nil {
+		return 0, err
+	}
+	return len(result.Published), nil
+}
+
+// insertOutboxEvent записывает событие в outbox в рамках уже открытой транзакции
+func insertOutboxEvent(c context.Context, tx pgx.Tx, eventType string, aggregateID uuid.UUID, data interface{}) error {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(c, `
+		INSERT INTO outbox (event_type, aggregate_id, payload)
+		VALUES ($1, $2, $3)`,
+		eventType, aggregateID, payload,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to write outbox event: %w", err)
+	}
+	return nil
+}
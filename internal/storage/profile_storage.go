@@ -0,0 +1,48 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"gw-currency-wallet/internal/errs"
+	"gw-currency-wallet/internal/storage/models"
+)
+
+// UpdateEmail меняет email и снимает его подтверждение. Ссылки из писем на прежний адрес перестают действовать
+func (s *Auth) UpdateEmail(c context.Context, userID uuid.UUID, email string) error {
+	tag, err := s.db.Exec(c, `UPDATE users SET email = $2, email_verified_at = NULL WHERE id = $1`, userID, email)
+	if err != nil {
+		return handlePgError(err)
+	}
+	if tag.RowsAffected() == 0 {
+		return errs.ErrUserNotFound
+	}
+	return nil
+}
+
+// ChangePassword меняет пароль, отзывает все refresh-токены пользователя и сохраняет refresh-токен
+// новой сессии в одной транзакции
+func (s *Auth) ChangePassword(c context.Context, userID uuid.UUID, passwordHash string, session models.RefreshToken) error {
+	tx, err := s.db.Begin(c)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(c)
+
+	tag, err := tx.Exec(c, `UPDATE users SET password_hash = $2 WHERE id = $1`, userID, passwordHash)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return errs.ErrUserNotFound
+	}
+	if err := revokeUserRefreshTokens(c, tx, userID); err != nil {
+		return err
+	}
+	if err := insertRefreshToken(c, tx, session); err != nil {
+		return err
+	}
+	return tx.Commit(c)
+}
@@ -2,6 +2,7 @@ package storage
 
 import (
 	"context"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5/pgxpool"
@@ -12,25 +13,80 @@ import (
 )
 
 type AuthStorage interface {
-	CreateUser(c context.Context, username, email, passwordHash string) error
+	CreateUser(c context.Context, username, email, passwordHash string) (uuid.UUID, error)
 	GetUserByUsername(c context.Context, username string) (*models.UserOutput, error)
+	GetUserByEmail(c context.Context, email string) (*models.UserOutput, error)
+	GetUserByID(c context.Context, userID uuid.UUID) (*models.UserOutput, error)
+	GetUserTier(c context.Context, userID uuid.UUID) (string, error)
+	CreateRefreshToken(c context.Context, token models.RefreshToken) error
+	GetRefreshToken(c context.Context, tokenHash string) (models.RefreshToken, error)
+	RotateRefreshToken(c context.Context, oldID uuid.UUID, newToken models.RefreshToken) error
+	RevokeRefreshTokenFamily(c context.Context, familyID uuid.UUID) error
+	RecordLoginAttempt(c context.Context, attempt models.LoginAttempt) error
+	CountIPLoginFailures(c context.Context, ip string, since time.Time) (int, time.Time, error)
+	RegisterLoginFailure(c context.Context, userID uuid.UUID, since time.Time) (int, int, error)
+	LockUser(c context.Context, userID uuid.UUID, until time.Time) error
+	ResetLoginFailures(c context.Context, userID uuid.UUID) error
+	GetMFA(c context.Context, userID uuid.UUID) (models.UserMFA, error)
+	SetPendingMFASecret(c context.Context, userID uuid.UUID, secret string) error
+	EnableMFA(c context.Context, userID uuid.UUID, recoveryCodeHashes []string) error
+	DisableMFA(c context.Context, userID uuid.UUID) error
+	UseTOTPStep(c context.Context, userID uuid.UUID, step int64) (bool, error)
+	UseRecoveryCode(c context.Context, userID uuid.UUID, codeHash string) (bool, error)
+	CreateUserToken(c context.Context, token models.UserToken) error
+	VerifyEmail(c context.Context, tokenHash string) (uuid.UUID, error)
+	ResetPassword(c context.Context, tokenHash, passwordHash string) (uuid.UUID, error)
+	UpdateEmail(c context.Context, userID uuid.UUID, email string) error
+	ChangePassword(c context.Context, userID uuid.UUID, passwordHash string, session models.RefreshToken) error
 }
 
 type WalletStorage interface {
 	GetBalance(c context.Context, userID uuid.UUID) (models.WalletResponse, error)
-	Deposit(ctx context.Context, userID uuid.UUID, currency string, amount decimal.Decimal) (models.WalletResponse, error)
-	Withdraw(ctx context.Context, userID uuid.UUID, currency string, amount decimal.Decimal) (models.WalletResponse, error)
-	Exchange(c context.Context, userID uuid.UUID, fromCurrency string, toCurrency string, amount decimal.Decimal, exchangedAmount decimal.Decimal) (models.WalletResponse, error)
+	Deposit(ctx context.Context, userID uuid.UUID, currency string, amount decimal.Decimal) (models.OperationResult, error)
+	Withdraw(ctx context.Context, userID uuid.UUID, currency string, amount decimal.Decimal) (models.OperationResult, error)
+	Exchange(c context.Context, userID uuid.UUID, fromCurrency string, toCurrency string, amount decimal.Decimal, exchangedAmount decimal.Decimal, fee decimal.Decimal) (models.WalletResponse, error)
+	Transfer(c context.Context, userID uuid.UUID, recipient string, currency string, amount decimal.Decimal) (models.TransferResult, error)
+	GetTransactions(c context.Context, userID uuid.UUID, filter models.TransactionFilter) ([]models.Transaction, error)
+	GetCurrency(c context.Context, code string) (models.Currency, error)
+}
+
+type AdminStorage interface {
+	SearchUsers(c context.Context, query string, limit int) ([]models.AdminUser, error)
+	GetUser(c context.Context, userID uuid.UUID) (models.AdminUser, error)
+	SetFrozen(c context.Context, actorID, userID uuid.UUID, frozen bool, reason string) (models.AdminUser, error)
+	Unlock(c context.Context, actorID, userID uuid.UUID) (models.AdminUser, error)
+	AdjustBalance(c context.Context, actorID uuid.UUID, userID uuid.UUID, currency string, amount decimal.Decimal, reason string) (models.OperationResult, error)
+}
+
+type OutboxStorage interface {
+	ProcessOutbox(c context.Context, limit int, publish func([]models.OutboxEvent) models.OutboxResult) (int, error)
+}
+
+type WebhookStorage interface {
+	CreateWebhook(c context.Context, sub models.WebhookSubscription) (models.WebhookSubscription, error)
+	GetWebhooks(c context.Context, userID uuid.UUID) ([]models.WebhookSubscription, error)
+	DeleteWebhook(c context.Context, userID, webhookID uuid.UUID) error
+	ClaimWebhookDeliveries(c context.Context, limit int, lease time.Duration) ([]models.WebhookDelivery, error)
+	CompleteWebhookDelivery(c context.Context, deliveryID uuid.UUID, statusCode int) error
+	FailWebhookDelivery(c context.Context, deliveryID uuid.UUID, statusCode *int, reason string, nextAttemptAt *time.Time) error
+	GetWebhookDeliveries(c context.Context, userID, webhookID uuid.UUID, status string, limit int) ([]models.WebhookDelivery, error)
+	ReplayWebhookDelivery(c context.Context, userID, webhookID, deliveryID uuid.UUID) (models.WebhookDelivery, error)
 }
 
 type Storage struct {
 	AuthStorage
 	WalletStorage
+	AdminStorage
+	OutboxStorage
+	WebhookStorage
 }
 
 func NewStorage(db *pgxpool.Pool, logger *logrus.Logger) *Storage {
 	return &Storage{
-		AuthStorage:   NewAuthStorage(db, logger),
-		WalletStorage: NewWalletStorage(db, logger),
+		AuthStorage:    NewAuthStorage(db, logger),
+		WalletStorage:  NewWalletStorage(db, logger),
+		AdminStorage:   NewAdminStorage(db, logger),
+		OutboxStorage:  NewOutboxStorage(db, logger),
+		WebhookStorage: NewWebhookStorage(db, logger),
 	}
 }
@@ -0,0 +1,85 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+
+	"gw-currency-wallet/internal/errs"
+	"gw-currency-wallet/internal/storage/models"
+)
+
+// GetUserByID возвращает пользователя по id
+func (s *Auth) GetUserByID(c context.Context, userID uuid.UUID) (*models.UserOutput, error) {
+	return scanUser(s.db.QueryRow(c, `SELECT `+userColumns+` FROM users WHERE id = $1`, userID))
+}
+
+// CreateRefreshToken сохраняет хэш нового refresh-токена
+func (s *Auth) CreateRefreshToken(c context.Context, token models.RefreshToken) error {
+	return insertRefreshToken(c, s.db, token)
+}
+
+// GetRefreshToken ищет refresh-токен по хэшу, в том числе отозванный
+func (s *Auth) GetRefreshToken(c context.Context, tokenHash string) (models.RefreshToken, error) {
+	var token models.RefreshToken
+	err := s.db.QueryRow(c, `
+		SELECT id, user_id, family_id, token_hash, expires_at, revoked_at
+		FROM refresh_tokens
+		WHERE token_hash = $1`, tokenHash,
+	).Scan(&token.ID, &token.UserID, &token.FamilyID, &token.TokenHash, &token.ExpiresAt, &token.RevokedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.RefreshToken{}, errs.ErrInvalidRefresh
+		}
+		return models.RefreshToken{}, err
+	}
+	return token, nil
+}
+
+// RotateRefreshToken отзывает использованный refresh-токен и сохраняет новый в одной транзакции.
+// Если токен уже отозван параллельным запросом, возвращается ErrInvalidRefresh
+func (s *Auth) RotateRefreshToken(c context.Context, oldID uuid.UUID, newToken models.RefreshToken) error {
+	tx, err := s.db.Begin(c)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(c)
+
+	tag, err := tx.Exec(c, `UPDATE refresh_tokens SET revoked_at = NOW() WHERE id = $1 AND revoked_at IS NULL`, oldID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return errs.ErrInvalidRefresh
+	}
+
+	if err := insertRefreshToken(c, tx, newToken); err != nil {
+		return err
+	}
+
+	return tx.Commit(c)
+}
+
+// RevokeRefreshTokenFamily отзывает все токены, полученные из одного входа
+func (s *Auth) RevokeRefreshTokenFamily(c context.Context, familyID uuid.UUID) error {
+	_, err := s.db.Exec(c,
+		`UPDATE refresh_tokens SET revoked_at = NOW() WHERE family_id = $1 AND revoked_at IS NULL`,
+		familyID,
+	)
+	return err
+}
+
+func insertRefreshToken(c context.Context, q querier, token models.RefreshToken) error {
+	_, err := q.Exec(c, `
+		INSERT INTO refresh_tokens (user_id, family_id, token_hash, expires_at)
+		VALUES ($1, $2, $3, $4)`,
+		token.UserID, token.FamilyID, token.TokenHash, token.ExpiresAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save refresh token: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,107 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+
+	"gw-currency-wallet/internal/storage/models"
+)
+
+const transactionColumns = `id, type, currency, amount, balance_after, counter_currency, counter_amount, counterparty_user_id, fee, fee_currency, created_at`
+
+// insertTransaction записывает операцию в журнал в рамках уже открытой транзакции
+func insertTransaction(c context.Context, tx pgx.Tx, walletID, userID uuid.UUID, t models.Transaction) (uuid.UUID, error) {
+	var id uuid.UUID
+	err := tx.QueryRow(c, `
+		INSERT INTO wallet_transactions (
+			wallet_id, user_id, type, currency, amount, balance_after,
+			counter_currency, counter_amount, counterparty_user_id, fee, fee_currency
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		RETURNING id`,
+		walletID, userID, t.Type, t.Currency, t.Amount, t.BalanceAfter,
+		t.CounterCurrency, t.CounterAmount, t.CounterpartyUserID, t.Fee, t.FeeCurrency,
+	).Scan(&id)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("failed to insert wallet transaction: %w", err)
+	}
+	return id, nil
+}
+
+// scanTransaction читает строку журнала в структуру
+func scanTransaction(row pgx.Row) (models.Transaction, error) {
+	var t models.Transaction
+	err := row.Scan(
+		&t.ID,
+		&t.Type,
+		&t.Currency,
+		&t.Amount,
+		&t.BalanceAfter,
+		&t.CounterCurrency,
+		&t.CounterAmount,
+		&t.CounterpartyUserID,
+		&t.Fee,
+		&t.FeeCurrency,
+		&t.CreatedAt,
+	)
+	return t, err
+}
+
+// GetTransactions возвращает операции пользователя по фильтру, новые первыми
+func (w *Wallet) GetTransactions(c context.Context, userID uuid.UUID, filter models.TransactionFilter) ([]models.Transaction, error) {
+	conditions := []string{"user_id = $1"}
+	args := []interface{}{userID}
+
+	// addCondition добавляет условие с очередным плейсхолдером
+	addCondition := func(condition string, value interface{}) {
+		args = append(args, value)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+
+	if filter.Currency != "" {
+		addCondition("currency = $%d", strings.ToUpper(filter.Currency))
+	}
+	if filter.Type != "" {
+		addCondition("type = $%d", filter.Type)
+	}
+	if !filter.From.IsZero() {
+		addCondition("created_at >= $%d", filter.From)
+	}
+	if !filter.To.IsZero() {
+		addCondition("created_at < $%d", filter.To)
+	}
+	if filter.AfterID != uuid.Nil {
+		args = append(args, filter.AfterCreatedAt, filter.AfterID)
+		conditions = append(conditions, fmt.Sprintf("(created_at, id) < ($%d, $%d)", len(args)-1, len(args)))
+	}
+
+	args = append(args, filter.Limit)
+	query := fmt.Sprintf(`SELECT %s
+		FROM wallet_transactions
+		WHERE %s
+		ORDER BY created_at DESC, id DESC
+		LIMIT $%d`,
+		transactionColumns, strings.Join(conditions, " AND "), len(args),
+	)
+
+	rows, err := w.db.Query(c, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	transactions := make([]models.Transaction, 0, filter.Limit)
+	for rows.Next() {
+		t, err := scanTransaction(rows)
+		if err != nil {
+			return nil, err
+		}
+		transactions = append(transactions, t)
+	}
+
+	return transactions, rows.Err()
+}
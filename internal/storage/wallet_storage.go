@@ -4,7 +4,6 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"strings"
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
@@ -28,91 +27,161 @@ func NewWalletStorage(db *pgxpool.Pool, logger *logrus.Logger) *Wallet {
 	}
 }
 
-var validCurrencies = map[string]bool{
-	"RUB": true,
-	"USD": true,
-	"EUR": true,
-}
-
 // GetBalance извлекает информацию о балансе пользователя
 func (w *Wallet) GetBalance(c context.Context, userID uuid.UUID) (models.WalletResponse, error) {
-	// SQL запрос для получения данных баланса по userID
-	query := `
-		SELECT balance_rub, balance_usd, balance_eur
-		FROM wallets
-		WHERE user_id = $1
-		LIMIT 1
-	`
-
-	// Выполняем запрос и извлекаем данные в структуру
-	var response models.WalletResponse
-	err := w.db.QueryRow(c, query, userID).Scan(
-		&response.BalanceRub,
-		&response.BalanceUsd,
-		&response.BalanceEur,
-	)
-
+	var walletID uuid.UUID
+	err := w.db.QueryRow(c, `SELECT id FROM wallets WHERE user_id = $1`, userID).Scan(&walletID)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			// Если не нашли кошелек для пользователя, возвращаем ошибку
-			return models.WalletResponse{}, errs.ErrWalletNotFound
+			return nil, errs.ErrWalletNotFound
 		}
-		return models.WalletResponse{}, err
+		return nil, err
 	}
 
-	return response, nil
+	return loadBalances(c, w.db, walletID)
 }
 
-// Deposit Пополнение баланса и возврат нового состояния кошелька
-func (w *Wallet) Deposit(c context.Context, userID uuid.UUID, currency string, amount decimal.Decimal) (models.WalletResponse, error) {
-	currency = strings.ToUpper(currency)
+// Deposit Пополнение баланса. Возвращает код валюты из реестра, зачисленную сумму после округления и новый баланс
+func (w *Wallet) Deposit(c context.Context, userID uuid.UUID, currency string, amount decimal.Decimal) (models.OperationResult, error) {
+	tx, err := w.db.Begin(c)
+	if err != nil {
+		return models.OperationResult{}, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(c)
 
 	// Проверяем, что валюта поддерживается
-	if !validCurrencies[currency] {
-		return models.WalletResponse{}, errs.ErrUnsupportedCurrency
+	cur, err := getCurrency(c, tx, currency)
+	if err != nil {
+		return models.OperationResult{}, err
+	}
+	amount, err = roundAmount(cur, amount)
+	if err != nil {
+		return models.OperationResult{}, err
 	}
 
-	query := fmt.Sprintf(
-		`UPDATE wallets 
-		SET balance_%s = balance_%s + $1 
-		WHERE user_id = $2 
-		RETURNING balance_rub, balance_usd, balance_eur`,
-		strings.ToLower(currency), strings.ToLower(currency),
-	)
+	walletID, err := lockWallet(c, tx, userID)
+	if err != nil {
+		return models.OperationResult{}, err
+	}
 
-	var response models.WalletResponse
-	err := w.db.QueryRow(c, query, amount, userID).Scan(&response.BalanceRub, &response.BalanceUsd, &response.BalanceEur)
+	balanceAfter, err := creditBalance(c, tx, walletID, cur.Code, amount)
 	if err != nil {
-		return models.WalletResponse{}, err
+		return models.OperationResult{}, err
 	}
-	return response, nil
+
+	// Записываем операцию в журнал
+	transactionID, err := insertTransaction(c, tx, walletID, userID, models.Transaction{
+		Type:         models.TransactionDeposit,
+		Currency:     cur.Code,
+		Amount:       amount,
+		BalanceAfter: balanceAfter,
+	})
+	if err != nil {
+		return models.OperationResult{}, err
+	}
+
+	err = insertOutboxEvent(c, tx, models.EventWalletDeposited, userID, models.WalletOperationEvent{
+		UserID:        userID,
+		TransactionID: transactionID,
+		Currency:      cur.Code,
+		Amount:        amount,
+		BalanceAfter:  balanceAfter,
+	})
+	if err != nil {
+		return models.OperationResult{}, err
+	}
+
+	response, err := loadBalances(c, tx, walletID)
+	if err != nil {
+		return models.OperationResult{}, err
+	}
+
+	err = insertWebhookEvent(c, tx, models.EventWalletDeposited, userID, models.WalletChangedWebhook{
+		UserID:   userID,
+		Currency: cur.Code,
+		Amount:   amount,
+		Balance:  response,
+	})
+	if err != nil {
+		return models.OperationResult{}, err
+	}
+
+	if err := tx.Commit(c); err != nil {
+		return models.OperationResult{}, err
+	}
+	return models.OperationResult{Currency: cur.Code, Amount: amount, Balance: response}, nil
 }
 
-// Withdraw Списание средств и возврат нового состояния кошелька
-func (w *Wallet) Withdraw(c context.Context, userID uuid.UUID, currency string, amount decimal.Decimal) (models.WalletResponse, error) {
-	currency = strings.ToUpper(currency)
+// Withdraw Списание средств. Возвращает код валюты из реестра, списанную сумму после округления и новый баланс
+func (w *Wallet) Withdraw(c context.Context, userID uuid.UUID, currency string, amount decimal.Decimal) (models.OperationResult, error) {
+	tx, err := w.db.Begin(c)
+	if err != nil {
+		return models.OperationResult{}, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(c)
 
 	// Проверяем, что валюта поддерживается
-	if !validCurrencies[currency] {
-		return models.WalletResponse{}, errs.ErrUnsupportedCurrency
-	}
-	query := fmt.Sprintf(
-		`UPDATE wallets 
-		SET balance_%s = balance_%s - $1 
-		WHERE user_id = $2 AND balance_%s >= $1 
-		RETURNING balance_rub, balance_usd, balance_eur`,
-		strings.ToLower(currency), strings.ToLower(currency), strings.ToLower(currency),
-	)
+	cur, err := getCurrency(c, tx, currency)
+	if err != nil {
+		return models.OperationResult{}, err
+	}
+	amount, err = roundAmount(cur, amount)
+	if err != nil {
+		return models.OperationResult{}, err
+	}
 
-	var response models.WalletResponse
-	err := w.db.QueryRow(c, query, amount, userID).Scan(&response.BalanceRub, &response.BalanceUsd, &response.BalanceEur)
+	walletID, err := lockWallet(c, tx, userID)
 	if err != nil {
-		if errors.Is(err, pgx.ErrNoRows) {
-			return models.WalletResponse{}, errs.ErrInsufficientFunds
-		}
-		return models.WalletResponse{}, err
+		return models.OperationResult{}, err
+	}
+
+	balanceAfter, err := debitBalance(c, tx, walletID, cur.Code, amount)
+	if err != nil {
+		return models.OperationResult{}, err
 	}
-	return response, nil
+
+	// Записываем операцию в журнал
+	transactionID, err := insertTransaction(c, tx, walletID, userID, models.Transaction{
+		Type:         models.TransactionWithdraw,
+		Currency:     cur.Code,
+		Amount:       amount.Neg(),
+		BalanceAfter: balanceAfter,
+	})
+	if err != nil {
+		return models.OperationResult{}, err
+	}
+
+	err = insertOutboxEvent(c, tx, models.EventWalletWithdrawn, userID, models.WalletOperationEvent{
+		UserID:        userID,
+		TransactionID: transactionID,
+		Currency:      cur.Code,
+		Amount:        amount,
+		BalanceAfter:  balanceAfter,
+	})
+	if err != nil {
+		return models.OperationResult{}, err
+	}
+
+	response, err := loadBalances(c, tx, walletID)
+	if err != nil {
+		return models.OperationResult{}, err
+	}
+
+	err = insertWebhookEvent(c, tx, models.EventWalletWithdrawn, userID, models.WalletChangedWebhook{
+		UserID:   userID,
+		Currency: cur.Code,
+		Amount:   amount,
+		Balance:  response,
+	})
+	if err != nil {
+		return models.OperationResult{}, err
+	}
+
+	if err := tx.Commit(c); err != nil {
+		return models.OperationResult{}, err
+	}
+	return models.OperationResult{Currency: cur.Code, Amount: amount, Balance: response}, nil
 }
 
 func (w *Wallet) Exchange(
@@ -122,34 +191,415 @@ func (w *Wallet) Exchange(
 	toCurrency string,
 	amount decimal.Decimal,
 	exchangedAmount decimal.Decimal,
+	fee decimal.Decimal,
 ) (models.WalletResponse, error) {
-	var newBalance models.WalletResponse
+	tx, err := w.db.Begin(c)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(c)
+
+	// Проверяем, что валюты поддерживаются
+	from, err := getCurrency(c, tx, fromCurrency)
+	if err != nil {
+		return nil, err
+	}
+	to, err := getCurrency(c, tx, toCurrency)
+	if err != nil {
+		return nil, err
+	}
+	amount, err = roundAmount(from, amount)
+	if err != nil {
+		return nil, err
+	}
+	// Зачисление и комиссию округляет PriceExchange так, чтобы вместе они давали сумму обмена;
+	// повторное округление здесь нарушило бы это равенство, поэтому суммы только проверяются
+	if !exchangedAmount.IsPositive() || fee.IsNegative() ||
+		!exchangedAmount.Equal(exchangedAmount.Round(to.MinorUnits)) || !fee.Equal(fee.Round(to.MinorUnits)) {
+		return nil, errs.ErrInvalidAmount
+	}
+
+	walletID, err := lockWallet(c, tx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	balanceAfter, err := debitBalance(c, tx, walletID, from.Code, amount)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := creditBalance(c, tx, walletID, to.Code, exchangedAmount); err != nil {
+		return nil, err
+	}
+
+	// Комиссия зачисляется на счёт дохода сервиса
+	if fee.IsPositive() {
+		if err := creditRevenue(c, tx, to.Code, fee); err != nil {
+			return nil, err
+		}
+	}
+
+	// Записываем обмен в журнал: списание в исходной валюте, зачисление во второй и удержанная комиссия
+	transactionID, err := insertTransaction(c, tx, walletID, userID, models.Transaction{
+		Type:            models.TransactionExchange,
+		Currency:        from.Code,
+		Amount:          amount.Neg(),
+		BalanceAfter:    balanceAfter,
+		CounterCurrency: &to.Code,
+		CounterAmount:   &exchangedAmount,
+		Fee:             &fee,
+		FeeCurrency:     &to.Code,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = insertOutboxEvent(c, tx, models.EventWalletExchanged, userID, models.WalletExchangedEvent{
+		UserID:          userID,
+		TransactionID:   transactionID,
+		FromCurrency:    from.Code,
+		ToCurrency:      to.Code,
+		Amount:          amount,
+		ExchangedAmount: exchangedAmount,
+		Fee:             fee,
+	})
+	if err != nil {
+		return nil, err
+	}
 
-	fromCurrency = strings.ToUpper(fromCurrency)
-	toCurrency = strings.ToUpper(toCurrency)
+	newBalance, err := loadBalances(c, tx, walletID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = insertWebhookEvent(c, tx, models.EventWalletExchanged, userID, models.ExchangeWebhook{
+		UserID:          userID,
+		FromCurrency:    from.Code,
+		ToCurrency:      to.Code,
+		Amount:          amount,
+		ExchangedAmount: exchangedAmount,
+		Fee:             fee,
+		Balance:         newBalance,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(c); err != nil {
+		return nil, err
+	}
+	return newBalance, nil
+}
+
+// Transfer Перевод средств другому пользователю (по username или email) в одной транзакции.
+// Возвращает баланс отправителя и ID получателя
+func (w *Wallet) Transfer(
+	c context.Context,
+	userID uuid.UUID,
+	recipient string,
+	currency string,
+	amount decimal.Decimal,
+) (models.TransferResult, error) {
+	tx, err := w.db.Begin(c)
+	if err != nil {
+		return models.TransferResult{}, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(c)
 
 	// Проверяем, что валюта поддерживается
-	if !validCurrencies[fromCurrency] || !validCurrencies[toCurrency] {
-		return models.WalletResponse{}, errs.ErrUnsupportedCurrency
-	}
-
-	query := fmt.Sprintf(`
-		UPDATE wallets 
-		SET balance_%s = balance_%s - $1, balance_%s = balance_%s + $2
-		WHERE user_id = $3 AND balance_%s >= $1 
-		RETURNING balance_rub, balance_usd, balance_eur`,
-		strings.ToLower(fromCurrency), strings.ToLower(fromCurrency),
-		strings.ToLower(toCurrency), strings.ToLower(toCurrency),
-		strings.ToLower(fromCurrency),
+	cur, err := getCurrency(c, tx, currency)
+	if err != nil {
+		return models.TransferResult{}, err
+	}
+	amount, err = roundAmount(cur, amount)
+	if err != nil {
+		return models.TransferResult{}, err
+	}
+
+	// Ищем получателя, совпадение по username приоритетнее совпадения по email
+	var recipientID uuid.UUID
+	err = tx.QueryRow(c, `
+		SELECT id FROM users
+		WHERE username = $1 OR email = $1
+		ORDER BY (username = $1) DESC
+		LIMIT 1`, recipient,
+	).Scan(&recipientID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.TransferResult{}, errs.ErrRecipientNotFound
+		}
+		return models.TransferResult{}, err
+	}
+
+	if recipientID == userID {
+		return models.TransferResult{}, errs.ErrSelfTransfer
+	}
+
+	// Блокируем оба кошелька в фиксированном порядке, чтобы встречные переводы не давали дедлок
+	wallets := make(map[uuid.UUID]uuid.UUID, 2)
+	frozen := make(map[uuid.UUID]bool, 2)
+	var senderVerified bool
+	var senderUsername string
+	rows, err := tx.Query(c, `
+		SELECT w.id, w.user_id, u.username, u.frozen_at IS NOT NULL, u.email_verified_at IS NOT NULL
+		FROM wallets w
+		JOIN users u ON u.id = w.user_id
+		WHERE w.user_id IN ($1, $2)
+		ORDER BY w.id
+		FOR UPDATE OF w`, userID, recipientID,
 	)
+	if err != nil {
+		return models.TransferResult{}, err
+	}
+	for rows.Next() {
+		var walletID, ownerID uuid.UUID
+		var username string
+		var isFrozen, verified bool
+		if err := rows.Scan(&walletID, &ownerID, &username, &isFrozen, &verified); err != nil {
+			rows.Close()
+			return models.TransferResult{}, err
+		}
+		wallets[ownerID] = walletID
+		frozen[ownerID] = isFrozen
+		if ownerID == userID {
+			senderVerified, senderUsername = verified, username
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return models.TransferResult{}, err
+	}
+
+	senderWalletID, ok := wallets[userID]
+	if !ok {
+		return models.TransferResult{}, errs.ErrWalletNotFound
+	}
+	recipientWalletID, ok := wallets[recipientID]
+	if !ok {
+		return models.TransferResult{}, errs.ErrRecipientNotFound
+	}
+	if frozen[userID] {
+		return models.TransferResult{}, errs.ErrAccountFrozen
+	}
+	if !senderVerified {
+		return models.TransferResult{}, errs.ErrEmailNotVerified
+	}
+	if frozen[recipientID] {
+		return models.TransferResult{}, errs.ErrRecipientFrozen
+	}
+
+	// Списываем у отправителя и зачисляем получателю
+	senderBalance, err := debitBalance(c, tx, senderWalletID, cur.Code, amount)
+	if err != nil {
+		return models.TransferResult{}, err
+	}
+	recipientBalance, err := creditBalance(c, tx, recipientWalletID, cur.Code, amount)
+	if err != nil {
+		return models.TransferResult{}, err
+	}
+
+	// Записываем перевод в журнал и outbox обоих участников
+	senderTransactionID, err := insertTransaction(c, tx, senderWalletID, userID, models.Transaction{
+		Type:               models.TransactionTransfer,
+		Currency:           cur.Code,
+		Amount:             amount.Neg(),
+		BalanceAfter:       senderBalance,
+		CounterpartyUserID: &recipientID,
+	})
+	if err != nil {
+		return models.TransferResult{}, err
+	}
+
+	recipientTransactionID, err := insertTransaction(c, tx, recipientWalletID, recipientID, models.Transaction{
+		Type:               models.TransactionTransfer,
+		Currency:           cur.Code,
+		Amount:             amount,
+		BalanceAfter:       recipientBalance,
+		CounterpartyUserID: &userID,
+	})
+	if err != nil {
+		return models.TransferResult{}, err
+	}
+
+	err = insertOutboxEvent(c, tx, models.EventWalletTransferred, userID, models.WalletTransferredEvent{
+		UserID:             userID,
+		TransactionID:      senderTransactionID,
+		CounterpartyUserID: recipientID,
+		Currency:           cur.Code,
+		Amount:             amount.Neg(),
+		BalanceAfter:       senderBalance,
+	})
+	if err != nil {
+		return models.TransferResult{}, err
+	}
+	err = insertOutboxEvent(c, tx, models.EventWalletTransferred, recipientID, models.WalletTransferredEvent{
+		UserID:             recipientID,
+		TransactionID:      recipientTransactionID,
+		CounterpartyUserID: userID,
+		Currency:           cur.Code,
+		Amount:             amount,
+		BalanceAfter:       recipientBalance,
+	})
+	if err != nil {
+		return models.TransferResult{}, err
+	}
 
-	row := w.db.QueryRow(c, query, amount, exchangedAmount, userID)
-	if err := row.Scan(&newBalance.BalanceRub, &newBalance.BalanceUsd, &newBalance.BalanceEur); err != nil {
+	response, err := loadBalances(c, tx, senderWalletID)
+	if err != nil {
+		return models.TransferResult{}, err
+	}
+	recipientResponse, err := loadBalances(c, tx, recipientWalletID)
+	if err != nil {
+		return models.TransferResult{}, err
+	}
+
+	// Перевод виден подписчикам обоих участников: отправителю указывается получатель, получателю — отправитель
+	err = insertWebhookEvent(c, tx, models.EventWalletTransferred, userID, models.WalletChangedWebhook{
+		UserID:    userID,
+		Currency:  cur.Code,
+		Amount:    amount,
+		Recipient: recipient,
+		Balance:   response,
+	})
+	if err != nil {
+		return models.TransferResult{}, err
+	}
+	err = insertWebhookEvent(c, tx, models.EventWalletTransferred, recipientID, models.WalletChangedWebhook{
+		UserID:   recipientID,
+		Currency: cur.Code,
+		Amount:   amount,
+		Sender:   senderUsername,
+		Balance:  recipientResponse,
+	})
+	if err != nil {
+		return models.TransferResult{}, err
+	}
+
+	if err := tx.Commit(c); err != nil {
+		return models.TransferResult{}, err
+	}
+	return models.TransferResult{
+		Currency:    cur.Code,
+		Amount:      amount,
+		Balance:     response,
+		RecipientID: recipientID,
+	}, nil
+}
+
+// lockWallet блокирует кошелёк пользователя до конца транзакции и возвращает его ID.
+// Операции по замороженному аккаунту и до подтверждения email запрещены
+func lockWallet(c context.Context, tx pgx.Tx, userID uuid.UUID) (uuid.UUID, error) {
+	wallet, err := lockWalletForUpdate(c, tx, userID)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	if wallet.frozen {
+		return uuid.Nil, errs.ErrAccountFrozen
+	}
+	if !wallet.emailVerified {
+		return uuid.Nil, errs.ErrEmailNotVerified
+	}
+	return wallet.id, nil
+}
+
+// lockedWallet кошелёк, заблокированный lockWalletForUpdate, и состояние аккаунта владельца
+type lockedWallet struct {
+	id            uuid.UUID
+	frozen        bool
+	emailVerified bool
+}
+
+// lockWalletForUpdate блокирует кошелёк пользователя и возвращает его ID и состояние аккаунта
+func lockWalletForUpdate(c context.Context, tx pgx.Tx, userID uuid.UUID) (lockedWallet, error) {
+	var wallet lockedWallet
+	err := tx.QueryRow(c, `
+		SELECT w.id, u.frozen_at IS NOT NULL, u.email_verified_at IS NOT NULL
+		FROM wallets w
+		JOIN users u ON u.id = w.user_id
+		WHERE w.user_id = $1
+		FOR UPDATE OF w`, userID,
+	).Scan(&wallet.id, &wallet.frozen, &wallet.emailVerified)
+	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
-			return models.WalletResponse{}, errs.ErrInsufficientFunds
+			return lockedWallet{}, errs.ErrWalletNotFound
 		}
-		return models.WalletResponse{}, err
+		return lockedWallet{}, err
 	}
+	return wallet, nil
+}
 
-	return newBalance, nil
+// creditBalance зачисляет сумму на баланс в валюте и возвращает новый баланс
+func creditBalance(c context.Context, tx pgx.Tx, walletID uuid.UUID, currency string, amount decimal.Decimal) (decimal.Decimal, error) {
+	var balance decimal.Decimal
+	err := tx.QueryRow(c, `
+		INSERT INTO wallet_balances (wallet_id, currency, amount)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (wallet_id, currency) DO UPDATE SET amount = wallet_balances.amount + EXCLUDED.amount
+		RETURNING amount`,
+		walletID, currency, amount,
+	).Scan(&balance)
+	return balance, err
+}
+
+// debitBalance списывает сумму с баланса в валюте и возвращает новый баланс
+func debitBalance(c context.Context, tx pgx.Tx, walletID uuid.UUID, currency string, amount decimal.Decimal) (decimal.Decimal, error) {
+	var balance decimal.Decimal
+	err := tx.QueryRow(c, `
+		UPDATE wallet_balances
+		SET amount = amount - $3
+		WHERE wallet_id = $1 AND currency = $2 AND amount >= $3
+		RETURNING amount`,
+		walletID, currency, amount,
+	).Scan(&balance)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return decimal.Zero, errs.ErrInsufficientFunds
+		}
+		return decimal.Zero, err
+	}
+	return balance, nil
+}
+
+// creditRevenue зачисляет комиссию на счёт дохода сервиса
+func creditRevenue(c context.Context, tx pgx.Tx, currency string, amount decimal.Decimal) error {
+	_, err := tx.Exec(c, `
+		INSERT INTO revenue_balances (currency, amount)
+		VALUES ($1, $2)
+		ON CONFLICT (currency) DO UPDATE
+		SET amount = revenue_balances.amount + EXCLUDED.amount, updated_at = NOW()`,
+		currency, amount,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to credit revenue: %w", err)
+	}
+	return nil
+}
+
+// loadBalances возвращает балансы кошелька во всех включённых валютах,
+// а также в отключённых, если на них остались средства
+func loadBalances(c context.Context, q querier, walletID uuid.UUID) (models.WalletResponse, error) {
+	rows, err := q.Query(c, `
+		SELECT cur.code, COALESCE(b.amount, 0)
+		FROM currencies cur
+		LEFT JOIN wallet_balances b ON b.currency = cur.code AND b.wallet_id = $1
+		WHERE cur.enabled OR b.amount > 0
+		ORDER BY cur.code`,
+		walletID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	balances := make(models.WalletResponse)
+	for rows.Next() {
+		var code string
+		var amount decimal.Decimal
+		if err := rows.Scan(&code, &amount); err != nil {
+			return nil, err
+		}
+		balances[code] = amount
+	}
+
+	return balances, rows.Err()
 }
@@ -0,0 +1,267 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/sirupsen/logrus"
+
+	"gw-currency-wallet/internal/errs"
+	"gw-currency-wallet/internal/storage/models"
+)
+
+const webhookDeliveryColumns = `d.id, d.subscription_id, d.event_id, d.event_type, d.payload, d.status, d.attempts,
+	d.next_attempt_at, d.last_status_code, d.last_error, d.created_at, d.delivered_at`
+
+type Webhook struct {
+	db     *pgxpool.Pool
+	logger *logrus.Logger
+}
+
+func NewWebhookStorage(db *pgxpool.Pool, logger *logrus.Logger) *Webhook {
+	return &Webhook{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (w *Webhook) CreateWebhook(c context.Context, sub models.WebhookSubscription) (models.WebhookSubscription, error) {
+	err := w.db.QueryRow(c, `
+		INSERT INTO webhook_subscriptions (user_id, url, event_types, secret)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, created_at`,
+		sub.UserID, sub.URL, sub.EventTypes, sub.Secret,
+	).Scan(&sub.ID, &sub.CreatedAt)
+	if err != nil {
+		return models.WebhookSubscription{}, fmt.Errorf("failed to create webhook: %w", err)
+	}
+	return sub, nil
+}
+
+// GetWebhooks подписки пользователя без секретов
+func (w *Webhook) GetWebhooks(c context.Context, userID uuid.UUID) ([]models.WebhookSubscription, error) {
+	rows, err := w.db.Query(c, `
+		SELECT id, user_id, url, event_types, created_at
+		FROM webhook_subscriptions
+		WHERE user_id = $1
+		ORDER BY created_at`, userID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	subs := make([]models.WebhookSubscription, 0)
+	for rows.Next() {
+		var sub models.WebhookSubscription
+		if err := rows.Scan(&sub.ID, &sub.UserID, &sub.URL, &sub.EventTypes, &sub.CreatedAt); err != nil {
+			return nil, err
+		}
+		subs = append(subs, sub)
+	}
+	return subs, rows.Err()
+}
+
+// DeleteWebhook удаляет подписку вместе с историей доставок
+func (w *Webhook) DeleteWebhook(c context.Context, userID, webhookID uuid.UUID) error {
+	tag, err := w.db.Exec(c, `DELETE FROM webhook_subscriptions WHERE id = $1 AND user_id = $2`, webhookID, userID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return errs.ErrWebhookNotFound
+	}
+	return nil
+}
+
+// insertWebhookEvent ставит событие в очередь для всех подписок пользователя на этот тип в рамках уже открытой
+// транзакции: доставки фиксируются вместе с изменением баланса и не теряются при сбое после коммита
+func insertWebhookEvent(c context.Context, tx pgx.Tx, eventType string, userID uuid.UUID, data interface{}) error {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	event := models.WebhookEvent{
+		ID:         uuid.New(),
+		Type:       eventType,
+		Data:       payload,
+		OccurredAt: time.Now().UTC(),
+	}
+	body, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(c, `
+		INSERT INTO webhook_deliveries (subscription_id, event_id, event_type, payload)
+		SELECT id, $2, $3, $4
+		FROM webhook_subscriptions
+		WHERE user_id = $1 AND $3 = ANY(event_types)`,
+		userID, event.ID, event.Type, body,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to enqueue webhook deliveries: %w", err)
+	}
+	return nil
+}
+
+// ClaimWebhookDeliveries забирает доставки, время которых наступило, и откладывает их на lease,
+// чтобы другие экземпляры не отправили их одновременно. Если экземпляр упадёт, доставка вернётся в очередь после lease
+func (w *Webhook) ClaimWebhookDeliveries(c context.Context, limit int, lease time.Duration) ([]models.WebhookDelivery, error) {
+	rows, err := w.db.Query(c, `
+		WITH due AS (
+			SELECT id FROM webhook_deliveries
+			WHERE status = 'pending' AND next_attempt_at <= NOW()
+			ORDER BY next_attempt_at
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		UPDATE webhook_deliveries d
+		SET next_attempt_at = NOW() + make_interval(secs => $2)
+		FROM due, webhook_subscriptions s
+		WHERE d.id = due.id AND s.id = d.subscription_id
+		RETURNING `+webhookDeliveryColumns+`, s.url, s.secret`,
+		limit, lease.Seconds(),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	deliveries := make([]models.WebhookDelivery, 0)
+	for rows.Next() {
+		d, err := scanWebhookDelivery(rows, true)
+		if err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, d)
+	}
+	return deliveries, rows.Err()
+}
+
+// CompleteWebhookDelivery отмечает доставку успешной
+func (w *Webhook) CompleteWebhookDelivery(c context.Context, deliveryID uuid.UUID, statusCode int) error {
+	_, err := w.db.Exec(c, `
+		UPDATE webhook_deliveries
+		SET status = 'delivered', attempts = attempts + 1, last_status_code = $2, last_error = NULL, delivered_at = NOW()
+		WHERE id = $1`,
+		deliveryID, statusCode,
+	)
+	return err
+}
+
+// FailWebhookDelivery записывает неудачную попытку. Без nextAttemptAt доставка переходит в статус dead
+func (w *Webhook) FailWebhookDelivery(c context.Context, deliveryID uuid.UUID, statusCode *int, reason string, nextAttemptAt *time.Time) error {
+	status := models.WebhookDeliveryPending
+	next := time.Now()
+	if nextAttemptAt == nil {
+		status = models.WebhookDeliveryDead
+	} else {
+		next = *nextAttemptAt
+	}
+
+	_, err := w.db.Exec(c, `
+		UPDATE webhook_deliveries
+		SET status = $2, attempts = attempts + 1, last_status_code = $3, last_error = $4, next_attempt_at = $5
+		WHERE id = $1`,
+		deliveryID, status, statusCode, reason, next,
+	)
+	return err
+}
+
+// GetWebhookDeliveries последние доставки по подписке пользователя
+func (w *Webhook) GetWebhookDeliveries(c context.Context, userID, webhookID uuid.UUID, status string, limit int) ([]models.WebhookDelivery, error) {
+	if err := w.checkOwner(c, userID, webhookID); err != nil {
+		return nil, err
+	}
+
+	rows, err := w.db.Query(c, `
+		SELECT `+webhookDeliveryColumns+`
+		FROM webhook_deliveries d
+		WHERE d.subscription_id = $1 AND ($2 = '' OR d.status = $2)
+		ORDER BY d.created_at DESC
+		LIMIT $3`,
+		webhookID, status, limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	deliveries := make([]models.WebhookDelivery, 0)
+	for rows.Next() {
+		d, err := scanWebhookDelivery(rows, false)
+		if err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, d)
+	}
+	return deliveries, rows.Err()
+}
+
+// ReplayWebhookDelivery возвращает доставку в очередь с обнулённым счётчиком попыток
+func (w *Webhook) ReplayWebhookDelivery(c context.Context, userID, webhookID, deliveryID uuid.UUID) (models.WebhookDelivery, error) {
+	if err := w.checkOwner(c, userID, webhookID); err != nil {
+		return models.WebhookDelivery{}, err
+	}
+
+	delivery, err := scanWebhookDelivery(w.db.QueryRow(c, `
+		UPDATE webhook_deliveries d
+		SET status = 'pending', attempts = 0, next_attempt_at = NOW(), delivered_at = NULL
+		WHERE d.id = $1 AND d.subscription_id = $2
+		RETURNING `+webhookDeliveryColumns,
+		deliveryID, webhookID,
+	), false)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.WebhookDelivery{}, errs.ErrWebhookDeliveryNotFound
+		}
+		return models.WebhookDelivery{}, err
+	}
+	return delivery, nil
+}
+
+// checkOwner проверяет, что подписка принадлежит пользователю
+func (w *Webhook) checkOwner(c context.Context, userID, webhookID uuid.UUID) error {
+	var exists bool
+	err := w.db.QueryRow(c, `
+		SELECT EXISTS (SELECT 1 FROM webhook_subscriptions WHERE id = $1 AND user_id = $2)`,
+		webhookID, userID,
+	).Scan(&exists)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return errs.ErrWebhookNotFound
+	}
+	return nil
+}
+
+func scanWebhookDelivery(row pgx.Row, withTarget bool) (models.WebhookDelivery, error) {
+	var d models.WebhookDelivery
+	dest := []interface{}{
+		&d.ID,
+		&d.SubscriptionID,
+		&d.EventID,
+		&d.EventType,
+		&d.Payload,
+		&d.Status,
+		&d.Attempts,
+		&d.NextAttemptAt,
+		&d.LastStatusCode,
+		&d.LastError,
+		&d.CreatedAt,
+		&d.DeliveredAt,
+	}
+	if withTarget {
+		dest = append(dest, &d.URL, &d.Secret)
+	}
+	err := row.Scan(dest...)
+	return d, err
+}
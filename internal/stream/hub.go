@@ -0,0 +1,247 @@
+package stream
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+
+	config "gw-currency-wallet/internal/config"
+	"gw-currency-wallet/internal/metrics"
+	"gw-currency-wallet/internal/storage/models"
+)
+
+// Время на чтение баланса или курсов для рассылки
+const loadTimeout = 5 * time.Second
+
+// BalanceLoader читает текущий баланс пользователя
+type BalanceLoader interface {
+	GetBalance(c context.Context, userID uuid.UUID) (models.WalletResponse, error)
+}
+
+// RatesLoader получает курсы, при необходимости обновляя их у gw-exchanger
+type RatesLoader interface {
+	GetRates(c context.Context) (models.ExchangeRates, error)
+}
+
+// Subscription события одного подключения
+type Subscription struct {
+	UserID uuid.UUID
+	events chan models.StreamEvent
+}
+
+// Events закрывается при отписке, остановке хаба или если клиент не успевает читать события
+func (s *Subscription) Events() <-chan models.StreamEvent {
+	return s.events
+}
+
+// Hub держит одну подписку Redis pub/sub на реплику и раздаёт события локальным подключениям
+type Hub struct {
+	cache    *redis.Client
+	balances BalanceLoader
+	rates    RatesLoader
+	cfg      *config.StreamConfig
+	logger   *logrus.Logger
+
+	mu          sync.Mutex
+	subscribers map[uuid.UUID]map[*Subscription]struct{}
+	count       int
+	closed      bool
+}
+
+func NewHub(
+	cache *redis.Client,
+	balances BalanceLoader,
+	rates RatesLoader,
+	cfg *config.StreamConfig,
+	logger *logrus.Logger,
+) *Hub {
+	return &Hub{
+		cache:       cache,
+		balances:    balances,
+		rates:       rates,
+		cfg:         cfg,
+		logger:      logger,
+		subscribers: make(map[uuid.UUID]map[*Subscription]struct{}),
+	}
+}
+
+// Subscribe подключает клиента. После Close возвращается уже закрытая подписка
+func (h *Hub) Subscribe(userID uuid.UUID) *Subscription {
+	sub := &Subscription{
+		UserID: userID,
+		events: make(chan models.StreamEvent, h.cfg.BufferSize),
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.closed {
+		close(sub.events)
+		return sub
+	}
+	if h.subscribers[userID] == nil {
+		h.subscribers[userID] = make(map[*Subscription]struct{})
+	}
+	h.subscribers[userID][sub] = struct{}{}
+	h.count++
+	metrics.SetStreamClients(h.count)
+	return sub
+}
+
+// Unsubscribe отключает клиента; повторный вызов безопасен
+func (h *Hub) Unsubscribe(sub *Subscription) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.remove(sub)
+}
+
+// Close закрывает все подключения, чтобы обработчики завершились до остановки HTTP-сервера
+func (h *Hub) Close() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.closed = true
+	for _, subs := range h.subscribers {
+		for sub := range subs {
+			h.remove(sub)
+		}
+	}
+}
+
+// Run слушает Redis до отмены ctx. Пока есть подключения, раз в cfg.RatesInterval запрашивает курсы:
+// устаревшие курсы обновляются, и новые рассылаются всем репликам через Publisher
+func (h *Hub) Run(ctx context.Context) {
+	// Подписка восстанавливается клиентом Redis сама после разрыва соединения
+	pubsub := h.cache.PSubscribe(ctx, balanceChannelPrefix+"*")
+	defer pubsub.Close()
+	if err := pubsub.Subscribe(ctx, ratesChannel); err != nil {
+		h.logger.Errorf("Failed to subscribe to %s: %v", ratesChannel, err)
+	}
+	messages := pubsub.Channel()
+
+	ticker := time.NewTicker(h.cfg.RatesInterval)
+	defer ticker.Stop()
+
+	h.logger.Info("Stream hub started")
+	for {
+		select {
+		case <-ctx.Done():
+			h.logger.Info("Stream hub stopped")
+			return
+		case msg, ok := <-messages:
+			if !ok {
+				return
+			}
+			h.dispatch(ctx, msg)
+		case <-ticker.C:
+			if h.connected() {
+				h.refreshRates(ctx)
+			}
+		}
+	}
+}
+
+func (h *Hub) dispatch(ctx context.Context, msg *redis.Message) {
+	if msg.Channel == ratesChannel {
+		var rates models.ExchangeRates
+		if err := json.Unmarshal([]byte(msg.Payload), &rates); err != nil {
+			h.logger.Errorf("Invalid rates update: %v", err)
+			return
+		}
+		h.broadcast(models.StreamEvent{
+			Name: models.StreamEventRates,
+			Data: models.ExchangeRatesResponse{Rates: rates.Rates, AsOf: rates.AsOf, Stale: rates.Stale},
+		})
+		return
+	}
+
+	userID, err := uuid.Parse(strings.TrimPrefix(msg.Channel, balanceChannelPrefix))
+	if err != nil {
+		h.logger.Errorf("Invalid balance channel %s", msg.Channel)
+		return
+	}
+	// Баланс читаем, только если пользователь подключён к этой реплике
+	if !h.connected(userID) {
+		return
+	}
+
+	loadCtx, cancel := context.WithTimeout(ctx, loadTimeout)
+	defer cancel()
+	balance, err := h.balances.GetBalance(loadCtx, userID)
+	if err != nil {
+		h.logger.Warnf("⚠️ Failed to load balance of %s for stream: %v", userID, err)
+		return
+	}
+	h.send(userID, models.StreamEvent{
+		Name: models.StreamEventBalance,
+		Data: models.GetBalanceResponse{Balance: balance},
+	})
+}
+
+func (h *Hub) refreshRates(ctx context.Context) {
+	loadCtx, cancel := context.WithTimeout(ctx, loadTimeout)
+	defer cancel()
+	if _, err := h.rates.GetRates(loadCtx); err != nil {
+		h.logger.Warnf("⚠️ Failed to refresh rates for stream: %v", err)
+	}
+}
+
+// connected есть ли подключения пользователя или, без userID, хоть какие-то подключения
+func (h *Hub) connected(userID ...uuid.UUID) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if len(userID) == 0 {
+		return h.count > 0
+	}
+	return len(h.subscribers[userID[0]]) > 0
+}
+
+func (h *Hub) send(userID uuid.UUID, event models.StreamEvent) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for sub := range h.subscribers[userID] {
+		h.deliver(sub, event)
+	}
+}
+
+func (h *Hub) broadcast(event models.StreamEvent) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for _, subs := range h.subscribers {
+		for sub := range subs {
+			h.deliver(sub, event)
+		}
+	}
+}
+
+// deliver не блокирует рассылку: клиент с заполненной очередью отключается и переподключится сам
+func (h *Hub) deliver(sub *Subscription, event models.StreamEvent) {
+	select {
+	case sub.events <- event:
+	default:
+		h.logger.Warnf("⚠️ Stream client of user %s is too slow, disconnecting", sub.UserID)
+		h.remove(sub)
+	}
+}
+
+// remove вызывается под h.mu
+func (h *Hub) remove(sub *Subscription) {
+	subs, ok := h.subscribers[sub.UserID]
+	if !ok {
+		return
+	}
+	if _, ok := subs[sub]; !ok {
+		return
+	}
+	delete(subs, sub)
+	if len(subs) == 0 {
+		delete(h.subscribers, sub.UserID)
+	}
+	close(sub.events)
+	h.count--
+	metrics.SetStreamClients(h.count)
+}
@@ -0,0 +1,62 @@
+package stream
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+
+	"gw-currency-wallet/internal/storage/models"
+)
+
+// Каналы Redis pub/sub
+const (
+	balanceChannelPrefix = "stream:balance:" // + ID пользователя, сообщение пустое
+	ratesChannel         = "stream:rates"    // Сообщение: models.ExchangeRates в JSON
+)
+
+// Publisher рассылает изменения всем репликам через Redis pub/sub.
+// Публикация не влияет на результат операции, ошибки только логируются. Методы nil-получателя ничего не делают
+type Publisher struct {
+	cache  *redis.Client
+	logger *logrus.Logger
+}
+
+func NewPublisher(cache *redis.Client, logger *logrus.Logger) *Publisher {
+	return &Publisher{
+		cache:  cache,
+		logger: logger,
+	}
+}
+
+// BalanceChanged сообщает об изменении баланса пользователей.
+// Новый баланс читают реплики, к которым подключены эти пользователи
+func (p *Publisher) BalanceChanged(c context.Context, userIDs ...uuid.UUID) {
+	if p == nil {
+		return
+	}
+	// Запрос клиента мог уже завершиться, операция при этом выполнена
+	ctx := context.WithoutCancel(c)
+	for _, userID := range userIDs {
+		if err := p.cache.Publish(ctx, balanceChannelPrefix+userID.String(), "").Err(); err != nil {
+			p.logger.WithContext(c).Warnf("⚠️ Failed to publish balance change for user %s: %v", userID, err)
+		}
+	}
+}
+
+// RatesUpdated рассылает курсы, только что полученные от gw-exchanger
+func (p *Publisher) RatesUpdated(c context.Context, rates models.ExchangeRates) {
+	if p == nil {
+		return
+	}
+	payload, err := json.Marshal(rates)
+	if err != nil {
+		p.logger.WithContext(c).Errorf("Failed to encode rates update: %v", err)
+		return
+	}
+	if err := p.cache.Publish(context.WithoutCancel(c), ratesChannel, payload).Err(); err != nil {
+		p.logger.WithContext(c).Warnf("⚠️ Failed to publish rates update: %v", err)
+	}
+}
@@ -0,0 +1,108 @@
+package utils
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"os"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	config "gw-currency-wallet/internal/config"
+	"gw-currency-wallet/internal/storage/models"
+)
+
+// signingKey ключ из конфигурации. У ключа, оставленного только для проверки, private == nil
+type signingKey struct {
+	kid     string
+	method  jwt.SigningMethod
+	private crypto.Signer
+	public  crypto.PublicKey
+}
+
+// loadSigningKey читает PEM-файлы ключа и определяет алгоритм по типу ключа
+func loadSigningKey(cfg config.SigningKey) (*signingKey, error) {
+	if cfg.KID == "" {
+		return nil, fmt.Errorf("signing key without kid")
+	}
+
+	key := &signingKey{kid: cfg.KID}
+	switch {
+	case cfg.PrivateKeyFile != "":
+		block, err := readPEM(cfg.PrivateKeyFile)
+		if err != nil {
+			return nil, err
+		}
+		private, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			// openssl genrsa по умолчанию сохраняет RSA-ключ в PKCS#1
+			if private, err = x509.ParsePKCS1PrivateKey(block.Bytes); err != nil {
+				return nil, fmt.Errorf("key %s: failed to parse private key: %w", cfg.KID, err)
+			}
+		}
+		signer, ok := private.(crypto.Signer)
+		if !ok {
+			return nil, fmt.Errorf("key %s: unsupported private key type %T", cfg.KID, private)
+		}
+		key.private = signer
+		key.public = signer.Public()
+	case cfg.PublicKeyFile != "":
+		block, err := readPEM(cfg.PublicKeyFile)
+		if err != nil {
+			return nil, err
+		}
+		if key.public, err = x509.ParsePKIXPublicKey(block.Bytes); err != nil {
+			return nil, fmt.Errorf("key %s: failed to parse public key: %w", cfg.KID, err)
+		}
+	default:
+		return nil, fmt.Errorf("key %s: private_key_file or public_key_file is required", cfg.KID)
+	}
+
+	switch key.public.(type) {
+	case *rsa.PublicKey:
+		key.method = jwt.SigningMethodRS256
+	case ed25519.PublicKey:
+		key.method = jwt.SigningMethodEdDSA
+	default:
+		return nil, fmt.Errorf("key %s: unsupported key type %T, expected RSA or Ed25519", cfg.KID, key.public)
+	}
+	return key, nil
+}
+
+func readPEM(path string) (*pem.Block, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read key file: %w", err)
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("no PEM data in %s", path)
+	}
+	return block, nil
+}
+
+// jwk публичная часть ключа в формате JWK (RFC 7517)
+func (k *signingKey) jwk() models.JWK {
+	jwk := models.JWK{
+		KID: k.kid,
+		Use: "sig",
+		Alg: k.method.Alg(),
+	}
+
+	switch public := k.public.(type) {
+	case *rsa.PublicKey:
+		jwk.Kty = "RSA"
+		jwk.N = base64.RawURLEncoding.EncodeToString(public.N.Bytes())
+		jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(public.E)).Bytes())
+	case ed25519.PublicKey:
+		jwk.Kty = "OKP"
+		jwk.Crv = "Ed25519"
+		jwk.X = base64.RawURLEncoding.EncodeToString(public)
+	}
+	return jwk
+}
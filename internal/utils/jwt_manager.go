@@ -1,7 +1,13 @@
 package utils
 
 import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
 	"errors"
+	"fmt"
+	"sort"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
@@ -12,38 +18,134 @@ import (
 	"gw-currency-wallet/internal/storage/models"
 )
 
+// Время в токенах хранится с точностью до микросекунд, чтобы при смене пароля отзывались
+// и токены, выпущенные в ту же секунду до неё
+func init() {
+	jwt.TimePrecision = time.Microsecond
+}
+
 type JWTManager struct {
-	cfg *config.Config
+	cfg    *config.Config
+	active *signingKey
+	keys   map[string]*signingKey
 }
 
-func NewJWTManager(cfg *config.Config) *JWTManager {
-	return &JWTManager{cfg: cfg}
+// NewJWTManager загружает ключи подписи. Новые токены подписываются ключом active_kid,
+// остальные ключи из конфигурации принимаются при проверке, пока их не уберут после ротации.
+// Без ключей подпись HS256 на secret_key допускается только с явным auth.allow_hs256
+func NewJWTManager(cfg *config.Config) (*JWTManager, error) {
+	m := &JWTManager{cfg: cfg, keys: make(map[string]*signingKey)}
+
+	for _, keyCfg := range cfg.Auth.SigningKeys {
+		key, err := loadSigningKey(keyCfg)
+		if err != nil {
+			return nil, err
+		}
+		if _, exists := m.keys[key.kid]; exists {
+			return nil, fmt.Errorf("duplicate signing key kid %q", key.kid)
+		}
+		m.keys[key.kid] = key
+	}
+
+	if len(m.keys) > 0 {
+		active, ok := m.keys[cfg.Auth.ActiveKID]
+		if !ok || active.private == nil {
+			return nil, fmt.Errorf("active signing key %q must be configured with a private key", cfg.Auth.ActiveKID)
+		}
+		m.active = active
+		return m, nil
+	}
+
+	if !cfg.Auth.AllowHS256 {
+		return nil, errors.New("auth.signing_keys is empty: configure an RS256/EdDSA signing key or set auth.allow_hs256 for local development")
+	}
+	if cfg.Auth.SecretKey == "" {
+		return nil, errors.New("auth.secret_key is required when auth.allow_hs256 is enabled")
+	}
+	return m, nil
 }
 
-func (m *JWTManager) GenerateToken(userID uuid.UUID, username string) (string, error) {
+// GenerateToken выпускает access-токен. Уникальный jti позволяет отозвать токен до истечения срока
+func (m *JWTManager) GenerateToken(userID uuid.UUID, username, role string) (string, error) {
+	now := time.Now()
 	claims := models.Claims{
 		UserID:   userID.String(),
 		Username: username,
+		Role:     role,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(m.cfg.Auth.TokenTTl)), // Срок действия
+			ID:        uuid.NewString(),
+			Subject:   userID.String(),
+			Issuer:    m.cfg.Auth.Issuer,
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(m.cfg.Auth.TokenTTl)), // Срок действия
 		},
 	}
+	if m.cfg.Auth.Audience != "" {
+		claims.Audience = jwt.ClaimStrings{m.cfg.Auth.Audience}
+	}
+
+	// Без настроенных ключей подписываем общим секретом (auth.allow_hs256)
+	if m.active == nil {
+		token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+		return token.SignedString([]byte(m.cfg.Auth.SecretKey))
+	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(m.cfg.Auth.SecretKey))
+	token := jwt.NewWithClaims(m.active.method, claims)
+	token.Header["kid"] = m.active.kid
+	return token.SignedString(m.active.private)
 }
 
-// ParseJWT парсит и проверяет токен
+// JWKS возвращает публичные ключи всех настроенных ключей подписи
+func (m *JWTManager) JWKS() models.JWKS {
+	jwks := models.JWKS{Keys: make([]models.JWK, 0, len(m.keys))}
+	for _, key := range m.keys {
+		jwks.Keys = append(jwks.Keys, key.jwk())
+	}
+	sort.Slice(jwks.Keys, func(i, j int) bool { return jwks.Keys[i].KID < jwks.Keys[j].KID })
+	return jwks
+}
+
+// TokenTTL срок действия access-токена
+func (m *JWTManager) TokenTTL() time.Duration {
+	return m.cfg.Auth.TokenTTl
+}
+
+// GenerateRefreshToken создаёт случайный refresh-токен и момент его истечения
+func (m *JWTManager) GenerateRefreshToken() (string, time.Time, error) {
+	token, err := RandomToken()
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	return token, time.Now().Add(m.cfg.Auth.RefreshTokenTTL), nil
+}
+
+// RandomToken случайный 256-битный токен в base64url
+func RandomToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// HashToken хэш refresh-токена или другого случайного токена для хранения в базе
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// ParseJWT парсит и проверяет токен: подпись, exp, nbf, iat, а также iss и aud, если они заданы в конфигурации
 func (m *JWTManager) ParseJWT(tokenString string) (*models.Claims, error) {
-	secret := []byte(m.cfg.Auth.SecretKey)
-	token, err := jwt.ParseWithClaims(tokenString, &models.Claims{}, func(token *jwt.Token) (interface{}, error) {
-		// Проверяем, что алгоритм подписи корректен
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, errors.New("invalid signing method")
-		}
-		return secret, nil
-	})
+	options := []jwt.ParserOption{jwt.WithIssuedAt(), jwt.WithExpirationRequired()}
+	if m.cfg.Auth.Issuer != "" {
+		options = append(options, jwt.WithIssuer(m.cfg.Auth.Issuer))
+	}
+	if m.cfg.Auth.Audience != "" {
+		options = append(options, jwt.WithAudience(m.cfg.Auth.Audience))
+	}
 
+	token, err := jwt.ParseWithClaims(tokenString, &models.Claims{}, m.verificationKey, options...)
 	if err != nil {
 		return nil, err
 	}
@@ -57,6 +159,26 @@ func (m *JWTManager) ParseJWT(tokenString string) (*models.Claims, error) {
 	return claims, nil
 }
 
+// verificationKey подбирает ключ проверки по kid и сверяет алгоритм подписи с типом ключа
+func (m *JWTManager) verificationKey(token *jwt.Token) (interface{}, error) {
+	if len(m.keys) == 0 {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, errors.New("invalid signing method")
+		}
+		return []byte(m.cfg.Auth.SecretKey), nil
+	}
+
+	kid, _ := token.Header["kid"].(string)
+	key, ok := m.keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("unknown signing key %q", kid)
+	}
+	if token.Method.Alg() != key.method.Alg() {
+		return nil, errors.New("invalid signing method")
+	}
+	return key.public, nil
+}
+
 func HashPassword(password string) (string, error) {
 	bytes, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
 	return string(bytes), err
@@ -0,0 +1,50 @@
+package utils
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+)
+
+// SecretCipher шифрует секреты, которые нужно хранить в базе в обратимом виде (AES-256-GCM)
+type SecretCipher struct {
+	aead cipher.AEAD
+}
+
+// NewSecretCipher ключ шифрования выводится из key через SHA-256, поэтому подходит строка любой длины
+func NewSecretCipher(key string) *SecretCipher {
+	sum := sha256.Sum256([]byte(key))
+	// Для 32-байтного ключа AES и GCM ошибок не возвращают
+	block, _ := aes.NewCipher(sum[:])
+	aead, _ := cipher.NewGCM(block)
+	return &SecretCipher{aead: aead}
+}
+
+// Encrypt возвращает base64(nonce || ciphertext)
+func (s *SecretCipher) Encrypt(plaintext string) (string, error) {
+	nonce := make([]byte, s.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	sealed := s.aead.Seal(nonce, nonce, []byte(plaintext), nil)
+	return base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+func (s *SecretCipher) Decrypt(encrypted string) (string, error) {
+	data, err := base64.StdEncoding.DecodeString(encrypted)
+	if err != nil {
+		return "", err
+	}
+	if len(data) < s.aead.NonceSize() {
+		return "", errors.New("encrypted secret is too short")
+	}
+	nonce, ciphertext := data[:s.aead.NonceSize()], data[s.aead.NonceSize():]
+	plaintext, err := s.aead.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return "", err
+	}
+	return string(plaintext), nil
+}
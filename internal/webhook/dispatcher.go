@@ -0,0 +1,163 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	config "gw-currency-wallet/internal/config"
+	"gw-currency-wallet/internal/storage"
+	"gw-currency-wallet/internal/storage/models"
+)
+
+// Заголовки доставки
+const (
+	HeaderDeliveryID = "X-Webhook-Id"
+	HeaderEvent      = "X-Webhook-Event"
+	HeaderTimestamp  = "X-Webhook-Timestamp"
+	HeaderSignature  = "X-Webhook-Signature"
+)
+
+// Sign подпись доставки: hex(HMAC-SHA256(secret, timestamp + "." + body)).
+// Метка времени входит в подпись, чтобы подписчик мог отклонять старые повторы
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Dispatcher периодически забирает доставки из очереди и отправляет их подписчикам
+type Dispatcher struct {
+	stor   storage.WebhookStorage
+	client *http.Client
+	cfg    *config.WebhookConfig
+	logger *logrus.Logger
+}
+
+func NewDispatcher(stor storage.WebhookStorage, cfg *config.WebhookConfig, logger *logrus.Logger) *Dispatcher {
+	dialer := &net.Dialer{Timeout: cfg.Timeout}
+	if !cfg.AllowPrivateNetworks {
+		dialer.Control = dialControl
+	}
+	return &Dispatcher{
+		stor: stor,
+		client: &http.Client{
+			Timeout: cfg.Timeout,
+			// Прокси из окружения не используется: проверка адреса должна видеть адрес подписчика
+			Transport: &http.Transport{DialContext: dialer.DialContext},
+		},
+		cfg:    cfg,
+		logger: logger,
+	}
+}
+
+// Run отправляет доставки до отмены ctx
+func (d *Dispatcher) Run(ctx context.Context) {
+	d.logger.Info("Webhook dispatcher started")
+	for {
+		n, err := d.Flush(ctx)
+		if err != nil {
+			d.logger.Errorf("Webhook dispatcher: %v", err)
+		}
+		// Очередь не разобрана, продолжаем без паузы
+		if err == nil && n == d.cfg.BatchSize {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			d.logger.Info("Webhook dispatcher stopped")
+			return
+		case <-time.After(d.cfg.PollInterval):
+		}
+	}
+}
+
+// Flush выполняет один проход по очереди и возвращает число обработанных доставок
+func (d *Dispatcher) Flush(ctx context.Context) (int, error) {
+	// Доставка откладывается на время с запасом больше таймаута запроса
+	deliveries, err := d.stor.ClaimWebhookDeliveries(ctx, d.cfg.BatchSize, 2*d.cfg.Timeout+time.Minute)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, delivery := range deliveries {
+		if err := d.deliver(ctx, delivery); err != nil {
+			return 0, err
+		}
+	}
+	return len(deliveries), nil
+}
+
+// deliver отправляет одну доставку и сохраняет результат. Успехом считается любой ответ 2xx
+func (d *Dispatcher) deliver(ctx context.Context, delivery models.WebhookDelivery) error {
+	statusCode, sendErr := d.send(ctx, delivery)
+	if sendErr == nil {
+		return d.stor.CompleteWebhookDelivery(ctx, delivery.ID, statusCode)
+	}
+
+	var code *int
+	if statusCode != 0 {
+		code = &statusCode
+	}
+
+	attempt := delivery.Attempts + 1
+	if attempt >= d.cfg.MaxAttempts {
+		d.logger.Warnf("Webhook delivery %s moved to dead-letter after %d attempts: %v", delivery.ID, attempt, sendErr)
+		return d.stor.FailWebhookDelivery(ctx, delivery.ID, code, sendErr.Error(), nil)
+	}
+
+	next := time.Now().Add(Backoff(attempt, d.cfg.BaseBackoff, d.cfg.MaxBackoff))
+	d.logger.Debugf("Webhook delivery %s failed (attempt %d), retry at %s: %v", delivery.ID, attempt, next.Format(time.RFC3339), sendErr)
+	return d.stor.FailWebhookDelivery(ctx, delivery.ID, code, sendErr.Error(), &next)
+}
+
+func (d *Dispatcher) send(ctx context.Context, delivery models.WebhookDelivery) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, delivery.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, err
+	}
+
+	timestamp := time.Now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderDeliveryID, delivery.ID.String())
+	req.Header.Set(HeaderEvent, delivery.EventType)
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(HeaderSignature, Sign(delivery.Secret, timestamp, delivery.Payload))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+// Backoff пауза перед повтором после attempt неудачных попыток: base * 2^(attempt-1), но не больше max
+func Backoff(attempt int, base, max time.Duration) time.Duration {
+	delay := base
+	for i := 1; i < attempt; i++ {
+		delay *= 2
+		if delay >= max {
+			return max
+		}
+	}
+	return min(delay, max)
+}
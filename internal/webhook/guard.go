@@ -0,0 +1,57 @@
+package webhook
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/url"
+	"syscall"
+
+	"gw-currency-wallet/internal/errs"
+)
+
+// PublicIP сообщает, можно ли отправлять webhooks на адрес. Loopback, частные, link-local,
+// multicast и неуказанные адреса запрещены, чтобы подписка не открывала доступ к внутренней сети
+func PublicIP(ip net.IP) bool {
+	return !(ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() || ip.IsMulticast())
+}
+
+// CheckURL проверяет при регистрации подписки, что хост URL разрешается только в публичные адреса
+func CheckURL(ctx context.Context, rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Hostname() == "" {
+		return errs.ErrWebhookURLNotAllowed
+	}
+
+	if ip := net.ParseIP(u.Hostname()); ip != nil {
+		if !PublicIP(ip) {
+			return errs.ErrWebhookURLNotAllowed
+		}
+		return nil
+	}
+
+	addrs, err := net.DefaultResolver.LookupIPAddr(ctx, u.Hostname())
+	if err != nil || len(addrs) == 0 {
+		return errs.ErrWebhookURLNotAllowed
+	}
+	for _, addr := range addrs {
+		if !PublicIP(addr.IP) {
+			return errs.ErrWebhookURLNotAllowed
+		}
+	}
+	return nil
+}
+
+// dialControl повторяет проверку при каждом подключении: DNS мог смениться после регистрации,
+// а редирект — увести запрос на другой хост
+func dialControl(_, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	if ip := net.ParseIP(host); ip == nil || !PublicIP(ip) {
+		return fmt.Errorf("webhook address %s is not allowed", host)
+	}
+	return nil
+}
@@ -0,0 +1,14 @@
+CREATE TABLE wallet_transactions (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    wallet_id UUID NOT NULL REFERENCES wallets(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    type TEXT NOT NULL CHECK (type IN ('deposit', 'withdraw', 'exchange')),
+    currency VARCHAR(3) NOT NULL,
+    amount DECIMAL(20, 2) NOT NULL,
+    balance_after DECIMAL(20, 2) NOT NULL,
+    counter_currency VARCHAR(3),
+    counter_amount DECIMAL(20, 2),
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_wallet_transactions_user_created ON wallet_transactions (user_id, created_at DESC, id DESC);
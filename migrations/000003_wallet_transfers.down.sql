@@ -0,0 +1,7 @@
+DELETE FROM wallet_transactions WHERE type = 'transfer';
+
+ALTER TABLE wallet_transactions DROP COLUMN IF EXISTS counterparty_user_id;
+
+ALTER TABLE wallet_transactions DROP CONSTRAINT wallet_transactions_type_check;
+ALTER TABLE wallet_transactions
+    ADD CONSTRAINT wallet_transactions_type_check CHECK (type IN ('deposit', 'withdraw', 'exchange'));
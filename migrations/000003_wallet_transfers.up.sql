@@ -0,0 +1,6 @@
+ALTER TABLE wallet_transactions DROP CONSTRAINT wallet_transactions_type_check;
+ALTER TABLE wallet_transactions
+    ADD CONSTRAINT wallet_transactions_type_check CHECK (type IN ('deposit', 'withdraw', 'exchange', 'transfer'));
+
+ALTER TABLE wallet_transactions
+    ADD COLUMN counterparty_user_id UUID REFERENCES users(id) ON DELETE SET NULL;
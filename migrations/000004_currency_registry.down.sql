@@ -0,0 +1,21 @@
+ALTER TABLE wallet_transactions
+    DROP CONSTRAINT IF EXISTS wallet_transactions_currency_fkey,
+    DROP CONSTRAINT IF EXISTS wallet_transactions_counter_currency_fkey,
+    ALTER COLUMN amount TYPE DECIMAL(20, 2),
+    ALTER COLUMN balance_after TYPE DECIMAL(20, 2),
+    ALTER COLUMN counter_amount TYPE DECIMAL(20, 2);
+
+ALTER TABLE wallets
+    ADD COLUMN balance_rub DECIMAL(20, 2) NOT NULL DEFAULT 0,
+    ADD COLUMN balance_usd DECIMAL(20, 2) NOT NULL DEFAULT 0,
+    ADD COLUMN balance_eur DECIMAL(20, 2) NOT NULL DEFAULT 0;
+
+-- Балансы в валютах, отличных от RUB/USD/EUR, при откате теряются
+UPDATE wallets w
+SET balance_rub = COALESCE((SELECT amount FROM wallet_balances b WHERE b.wallet_id = w.id AND b.currency = 'RUB'), 0),
+    balance_usd = COALESCE((SELECT amount FROM wallet_balances b WHERE b.wallet_id = w.id AND b.currency = 'USD'), 0),
+    balance_eur = COALESCE((SELECT amount FROM wallet_balances b WHERE b.wallet_id = w.id AND b.currency = 'EUR'), 0);
+
+DROP TABLE IF EXISTS wallet_balances;
+DROP INDEX IF EXISTS wallets_user_id_key;
+DROP TABLE IF EXISTS currencies;
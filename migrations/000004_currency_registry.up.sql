@@ -0,0 +1,38 @@
+CREATE TABLE currencies (
+    code VARCHAR(3) PRIMARY KEY,
+    minor_units SMALLINT NOT NULL DEFAULT 2 CHECK (minor_units BETWEEN 0 AND 8),
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+INSERT INTO currencies (code, minor_units) VALUES ('RUB', 2), ('USD', 2), ('EUR', 2);
+
+CREATE UNIQUE INDEX IF NOT EXISTS wallets_user_id_key ON wallets (user_id);
+
+CREATE TABLE wallet_balances (
+    wallet_id UUID NOT NULL REFERENCES wallets(id) ON DELETE CASCADE,
+    currency VARCHAR(3) NOT NULL REFERENCES currencies(code),
+    amount NUMERIC(30, 8) NOT NULL DEFAULT 0 CHECK (amount >= 0),
+    PRIMARY KEY (wallet_id, currency)
+);
+
+-- Переносим балансы из фиксированных колонок
+INSERT INTO wallet_balances (wallet_id, currency, amount)
+SELECT id, 'RUB', balance_rub FROM wallets
+UNION ALL
+SELECT id, 'USD', balance_usd FROM wallets
+UNION ALL
+SELECT id, 'EUR', balance_eur FROM wallets;
+
+ALTER TABLE wallets
+    DROP COLUMN balance_rub,
+    DROP COLUMN balance_usd,
+    DROP COLUMN balance_eur;
+
+-- Журнал должен вмещать валюты с точностью больше двух знаков
+ALTER TABLE wallet_transactions
+    ALTER COLUMN amount TYPE NUMERIC(30, 8),
+    ALTER COLUMN balance_after TYPE NUMERIC(30, 8),
+    ALTER COLUMN counter_amount TYPE NUMERIC(30, 8),
+    ADD CONSTRAINT wallet_transactions_currency_fkey FOREIGN KEY (currency) REFERENCES currencies(code),
+    ADD CONSTRAINT wallet_transactions_counter_currency_fkey FOREIGN KEY (counter_currency) REFERENCES currencies(code);
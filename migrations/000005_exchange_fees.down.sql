@@ -0,0 +1,7 @@
+ALTER TABLE wallet_transactions
+    DROP COLUMN IF EXISTS fee_currency,
+    DROP COLUMN IF EXISTS fee;
+
+DROP TABLE IF EXISTS revenue_balances;
+
+ALTER TABLE users DROP COLUMN IF EXISTS tier;
@@ -0,0 +1,12 @@
+ALTER TABLE users ADD COLUMN tier TEXT NOT NULL DEFAULT 'standard';
+
+-- Счёт дохода сервиса: сюда зачисляются комиссии за обмен
+CREATE TABLE revenue_balances (
+    currency VARCHAR(3) PRIMARY KEY REFERENCES currencies(code),
+    amount NUMERIC(30, 8) NOT NULL DEFAULT 0,
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+ALTER TABLE wallet_transactions
+    ADD COLUMN fee NUMERIC(30, 8),
+    ADD COLUMN fee_currency VARCHAR(3) REFERENCES currencies(code);
@@ -0,0 +1,12 @@
+DROP TABLE IF EXISTS admin_audit_log;
+
+DELETE FROM wallet_transactions WHERE type = 'adjustment';
+
+ALTER TABLE wallet_transactions DROP CONSTRAINT wallet_transactions_type_check;
+ALTER TABLE wallet_transactions
+    ADD CONSTRAINT wallet_transactions_type_check CHECK (type IN ('deposit', 'withdraw', 'exchange', 'transfer'));
+
+ALTER TABLE users
+    DROP COLUMN IF EXISTS frozen_reason,
+    DROP COLUMN IF EXISTS frozen_at,
+    DROP COLUMN IF EXISTS role;
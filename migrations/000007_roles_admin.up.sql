@@ -0,0 +1,20 @@
+ALTER TABLE users
+    ADD COLUMN role TEXT NOT NULL DEFAULT 'user' CHECK (role IN ('user', 'support', 'admin')),
+    ADD COLUMN frozen_at TIMESTAMPTZ,
+    ADD COLUMN frozen_reason TEXT;
+
+ALTER TABLE wallet_transactions DROP CONSTRAINT wallet_transactions_type_check;
+ALTER TABLE wallet_transactions
+    ADD CONSTRAINT wallet_transactions_type_check CHECK (type IN ('deposit', 'withdraw', 'exchange', 'transfer', 'adjustment'));
+
+-- Журнал действий сотрудников
+CREATE TABLE admin_audit_log (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    actor_user_id UUID NOT NULL REFERENCES users(id),
+    action TEXT NOT NULL,
+    target_user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    details JSONB NOT NULL DEFAULT '{}',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_admin_audit_log_target ON admin_audit_log (target_user_id, created_at DESC);
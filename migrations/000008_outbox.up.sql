@@ -0,0 +1,14 @@
+-- События для публикации в Kafka. Пишутся в одной транзакции с изменением баланса
+CREATE TABLE outbox (
+    id BIGSERIAL PRIMARY KEY,
+    event_id UUID NOT NULL UNIQUE DEFAULT uuid_generate_v4(),
+    event_type TEXT NOT NULL,
+    aggregate_id UUID NOT NULL,              -- Ключ сообщения: события одного пользователя публикуются по порядку
+    payload JSONB NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    published_at TIMESTAMPTZ,
+    attempts INT NOT NULL DEFAULT 0,
+    last_error TEXT
+);
+
+CREATE INDEX idx_outbox_unpublished ON outbox (id) WHERE published_at IS NULL;
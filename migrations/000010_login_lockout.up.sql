@@ -0,0 +1,18 @@
+ALTER TABLE users
+    ADD COLUMN failed_logins INT NOT NULL DEFAULT 0,
+    ADD COLUMN last_failed_login_at TIMESTAMPTZ,
+    ADD COLUMN lockout_count INT NOT NULL DEFAULT 0,
+    ADD COLUMN locked_until TIMESTAMPTZ;
+
+-- Журнал попыток входа; user_id пустой, если пользователь не найден
+CREATE TABLE login_attempts (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    username TEXT NOT NULL,
+    user_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    ip TEXT NOT NULL,
+    result TEXT NOT NULL CHECK (result IN ('success', 'invalid_credentials', 'locked')),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_login_attempts_ip_failed ON login_attempts (ip, created_at) WHERE result = 'invalid_credentials';
+CREATE INDEX idx_login_attempts_user ON login_attempts (user_id, created_at DESC);
@@ -0,0 +1,15 @@
+-- totp_secret зашифрован ключом auth.mfa.encryption_key; пока totp_enabled_at пустой, секрет ждёт подтверждения первым кодом
+ALTER TABLE users
+    ADD COLUMN totp_secret TEXT,
+    ADD COLUMN totp_enabled_at TIMESTAMPTZ,
+    ADD COLUMN totp_last_step BIGINT;
+
+-- Одноразовые коды восстановления, хранятся только хэши
+CREATE TABLE mfa_recovery_codes (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    code_hash TEXT NOT NULL,
+    used_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (user_id, code_hash)
+);
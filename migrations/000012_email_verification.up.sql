@@ -0,0 +1,17 @@
+-- Пользователи, зарегистрированные до подтверждения email, считаются подтвердившими его
+ALTER TABLE users ADD COLUMN email_verified_at TIMESTAMPTZ;
+UPDATE users SET email_verified_at = created_at;
+
+-- Одноразовые токены из писем: подтверждение email и сброс пароля. Хранятся только хэши
+CREATE TABLE user_tokens (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    purpose TEXT NOT NULL CHECK (purpose IN ('email_verification', 'password_reset')),
+    token_hash TEXT UNIQUE NOT NULL,
+    email TEXT NOT NULL,
+    expires_at TIMESTAMPTZ NOT NULL,
+    used_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_user_tokens_user ON user_tokens (user_id, purpose);
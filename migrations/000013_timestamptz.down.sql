@@ -0,0 +1,5 @@
+ALTER TABLE revenue_balances ALTER COLUMN updated_at TYPE TIMESTAMP;
+ALTER TABLE currencies ALTER COLUMN created_at TYPE TIMESTAMP;
+ALTER TABLE wallet_transactions ALTER COLUMN created_at TYPE TIMESTAMP;
+ALTER TABLE wallets ALTER COLUMN created_at TYPE TIMESTAMP;
+ALTER TABLE users ALTER COLUMN created_at TYPE TIMESTAMP;
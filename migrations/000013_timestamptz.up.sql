@@ -0,0 +1,6 @@
+-- Все метки времени хранятся с часовым поясом; значения без пояса трактуются в поясе сессии, как их записал NOW()
+ALTER TABLE users ALTER COLUMN created_at TYPE TIMESTAMPTZ;
+ALTER TABLE wallets ALTER COLUMN created_at TYPE TIMESTAMPTZ;
+ALTER TABLE wallet_transactions ALTER COLUMN created_at TYPE TIMESTAMPTZ;
+ALTER TABLE currencies ALTER COLUMN created_at TYPE TIMESTAMPTZ;
+ALTER TABLE revenue_balances ALTER COLUMN updated_at TYPE TIMESTAMPTZ;
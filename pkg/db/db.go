@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/sirupsen/logrus"
 
 	_ "github.com/golang-migrate/migrate/v4/database/postgres"
@@ -24,9 +25,10 @@ func ConnectPostgres(dsn string, logger *logrus.Logger) (*pgxpool.Pool, error) {
 	}
 
 	// Применяем дополнительные настройки пула
-	poolConfig.MaxConns = 50                       // Максимальное количество соединений
-	poolConfig.MinConns = 5                        // Минимальное количество соединений
-	poolConfig.HealthCheckPeriod = 1 * time.Minute // Период проверки соединений
+	poolConfig.MaxConns = 50                        // Максимальное количество соединений
+	poolConfig.MinConns = 5                         // Минимальное количество соединений
+	poolConfig.HealthCheckPeriod = 1 * time.Minute  // Период проверки соединений
+	poolConfig.ConnConfig.Tracer = newQueryTracer() // Span OpenTelemetry на каждый запрос
 
 	pool, err := pgxpool.NewWithConfig(ctx, poolConfig)
 	if err != nil {
@@ -40,6 +42,11 @@ func ConnectPostgres(dsn string, logger *logrus.Logger) (*pgxpool.Pool, error) {
 		return nil, err
 	}
 
+	// Статистика пула для /metrics
+	if err := prometheus.Register(NewPoolCollector(pool)); err != nil {
+		logger.Warnf("Failed to register pgxpool metrics: %v", err)
+	}
+
 	logger.Debug("Successfully connected to PostgreSQL")
 	return pool, nil
 }
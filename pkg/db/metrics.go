@@ -0,0 +1,53 @@
+package db
+
+import (
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// PoolCollector отдаёт статистику пула соединений pgxpool в формате Prometheus
+type PoolCollector struct {
+	pool *pgxpool.Pool
+
+	acquiredConns   *prometheus.Desc
+	idleConns       *prometheus.Desc
+	totalConns      *prometheus.Desc
+	maxConns        *prometheus.Desc
+	acquireCount    *prometheus.Desc
+	acquireDuration *prometheus.Desc
+	emptyAcquire    *prometheus.Desc
+	canceledAcquire *prometheus.Desc
+}
+
+func NewPoolCollector(pool *pgxpool.Pool) *PoolCollector {
+	desc := func(name, help string) *prometheus.Desc {
+		return prometheus.NewDesc("wallet_pgxpool_"+name, help, nil, nil)
+	}
+	return &PoolCollector{
+		pool:            pool,
+		acquiredConns:   desc("acquired_conns", "Connections currently in use."),
+		idleConns:       desc("idle_conns", "Idle connections in the pool."),
+		totalConns:      desc("total_conns", "Total connections in the pool."),
+		maxConns:        desc("max_conns", "Maximum size of the pool."),
+		acquireCount:    desc("acquire_total", "Successful connection acquisitions."),
+		acquireDuration: desc("acquire_duration_seconds_total", "Total time spent waiting for a connection."),
+		emptyAcquire:    desc("empty_acquire_total", "Acquisitions that had to wait because the pool was empty."),
+		canceledAcquire: desc("canceled_acquire_total", "Acquisitions canceled by context."),
+	}
+}
+
+func (p *PoolCollector) Describe(ch chan<- *prometheus.Desc) {
+	prometheus.DescribeByCollect(p, ch)
+}
+
+func (p *PoolCollector) Collect(ch chan<- prometheus.Metric) {
+	stat := p.pool.Stat()
+	ch <- prometheus.MustNewConstMetric(p.acquiredConns, prometheus.GaugeValue, float64(stat.AcquiredConns()))
+	ch <- prometheus.MustNewConstMetric(p.idleConns, prometheus.GaugeValue, float64(stat.IdleConns()))
+	ch <- prometheus.MustNewConstMetric(p.totalConns, prometheus.GaugeValue, float64(stat.TotalConns()))
+	ch <- prometheus.MustNewConstMetric(p.maxConns, prometheus.GaugeValue, float64(stat.MaxConns()))
+	ch <- prometheus.MustNewConstMetric(p.acquireCount, prometheus.CounterValue, float64(stat.AcquireCount()))
+	ch <- prometheus.MustNewConstMetric(p.acquireDuration, prometheus.CounterValue, stat.AcquireDuration().Seconds())
+	ch <- prometheus.MustNewConstMetric(p.emptyAcquire, prometheus.CounterValue, float64(stat.EmptyAcquireCount()))
+	ch <- prometheus.MustNewConstMetric(p.canceledAcquire, prometheus.CounterValue, float64(stat.CanceledAcquireCount()))
+}
@@ -11,6 +11,7 @@ import (
 	rotatelogs "github.com/lestrrat-go/file-rotatelogs"
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/trace"
 )
 
 func SetupLogger(level, format, outputFile, kafkaTopic string, kafkaBrokers []string) (*logrus.Logger, error) {
@@ -59,6 +60,9 @@ func SetupLogger(level, format, outputFile, kafkaTopic string, kafkaBrokers []st
 	// Добавляем хук для stack trace
 	logger.AddHook(&StackTraceHook{})
 
+	// Добавляем trace_id и span_id из контекста записи
+	logger.AddHook(&TraceHook{})
+
 	// Настроим Kafka логирование
 	if len(kafkaBrokers) > 0 && kafkaTopic != "" {
 		producer, err := newKafkaProducer(kafkaBrokers)
@@ -89,6 +93,25 @@ func (h *StackTraceHook) Fire(entry *logrus.Entry) error {
 	return nil
 }
 
+// TraceHook - добавляет идентификаторы трассировки OpenTelemetry к записям, созданным через WithContext
+type TraceHook struct{}
+
+func (h *TraceHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+func (h *TraceHook) Fire(entry *logrus.Entry) error {
+	if entry.Context == nil {
+		return nil
+	}
+	spanContext := trace.SpanContextFromContext(entry.Context)
+	if spanContext.IsValid() {
+		entry.Data["trace_id"] = spanContext.TraceID().String()
+		entry.Data["span_id"] = spanContext.SpanID().String()
+	}
+	return nil
+}
+
 func GetStackTrace() string {
 	buf := make([]byte, 1024)
 	n := runtime.Stack(buf, false)
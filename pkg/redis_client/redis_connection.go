@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/redis/go-redis/extra/redisotel/v9"
 	"github.com/redis/go-redis/v9"
 	logger "github.com/sirupsen/logrus"
 )
@@ -18,6 +19,11 @@ func InitRedisClient(addr, password string, db int) (*redis.Client, error) {
 		PoolSize: 10,
 	})
 
+	// Span OpenTelemetry на каждую команду
+	if err := redisotel.InstrumentTracing(client); err != nil {
+		return nil, fmt.Errorf("could not instrument Redis tracing: %w", err)
+	}
+
 	// Проверяем соединение с Redis
 	err := client.Ping(context.Background()).Err()
 	if err != nil {
@@ -0,0 +1,289 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/sirupsen/logrus"
+
+	"gw-currency-wallet/internal/delivery/middleware"
+	"gw-currency-wallet/internal/errs"
+	"gw-currency-wallet/internal/service"
+	"gw-currency-wallet/internal/service/mocks"
+	"gw-currency-wallet/internal/storage"
+	"gw-currency-wallet/internal/storage/models"
+)
+
+func TestAdminAPI(t *testing.T) {
+	router, mockCtrl, mockSvc, validator, handler, cfg := SetupTestEnv(t)
+	defer mockCtrl.Finish()
+
+	jwtManager := newJWTManager(t, cfg)
+	allowTokens(mockSvc)
+	mockAdminService := mockSvc.AdminService.(*mocks.MockAdminService)
+
+	// Маршруты повторяют группу /admin из InitRoutes
+	admin := router.Group("/admin",
+		middleware.AuthMiddleware(jwtManager, mockSvc),
+		middleware.RequireRole(models.RoleSupport, models.RoleAdmin),
+	)
+	adminOnly := middleware.RequireRole(models.RoleAdmin)
+	admin.GET("/users/:id", handler.AdminHandler.GetUser)
+	admin.POST("/users/:id/freeze", adminOnly, middleware.ValidationMiddleware[models.FreezeRequest](validator), handler.AdminHandler.FreezeUser)
+	admin.POST("/users/:id/unlock", adminOnly, handler.AdminHandler.UnlockUser)
+	admin.POST("/users/:id/adjustments", adminOnly, middleware.ValidationMiddleware[models.AdjustmentRequest](validator), handler.AdminHandler.AdjustBalance)
+
+	actorID := uuid.MustParse("3a7c0b43-5a34-4b8e-9f0e-2a8d1f0c6e11")
+	targetID := uuid.MustParse("11ff6680-c604-4231-9453-6e2fbc2c30dc")
+	targetUser := models.AdminUser{ID: targetID, Username: "testuser", Role: models.RoleUser, Tier: "standard"}
+
+	tests := []struct {
+		name            string
+		role            string
+		method          string
+		path            string
+		body            interface{}
+		setupMock       func()
+		expectedStatus  int
+		expectedMessage string
+	}{
+		{
+			name:            "Error - Regular user has no access",
+			role:            models.RoleUser,
+			method:          "GET",
+			path:            "/admin/users/" + targetID.String(),
+			expectedStatus:  http.StatusForbidden,
+			expectedMessage: "Insufficient permissions",
+		},
+		{
+			name:   "Success - Support looks up user",
+			role:   models.RoleSupport,
+			method: "GET",
+			path:   "/admin/users/" + targetID.String(),
+			setupMock: func() {
+				mockAdminService.EXPECT().GetUser(gomock.Any(), targetID).Return(targetUser, nil).Times(1)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:            "Error - Invalid user id",
+			role:            models.RoleSupport,
+			method:          "GET",
+			path:            "/admin/users/not-a-uuid",
+			expectedStatus:  http.StatusBadRequest,
+			expectedMessage: "Invalid user ID",
+		},
+		{
+			name:            "Error - Support cannot freeze accounts",
+			role:            models.RoleSupport,
+			method:          "POST",
+			path:            "/admin/users/" + targetID.String() + "/freeze",
+			body:            models.FreezeRequest{Reason: "fraud investigation"},
+			expectedStatus:  http.StatusForbidden,
+			expectedMessage: "Insufficient permissions",
+		},
+		{
+			name:   "Success - Admin freezes account",
+			role:   models.RoleAdmin,
+			method: "POST",
+			path:   "/admin/users/" + targetID.String() + "/freeze",
+			body:   models.FreezeRequest{Reason: "fraud investigation"},
+			setupMock: func() {
+				mockAdminService.EXPECT().
+					FreezeUser(gomock.Any(), actorID, targetID, "fraud investigation").
+					Return(targetUser, nil).Times(1)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:            "Error - Support cannot unlock login",
+			role:            models.RoleSupport,
+			method:          "POST",
+			path:            "/admin/users/" + targetID.String() + "/unlock",
+			expectedStatus:  http.StatusForbidden,
+			expectedMessage: "Insufficient permissions",
+		},
+		{
+			name:   "Success - Admin unlocks login",
+			role:   models.RoleAdmin,
+			method: "POST",
+			path:   "/admin/users/" + targetID.String() + "/unlock",
+			setupMock: func() {
+				mockAdminService.EXPECT().UnlockUser(gomock.Any(), actorID, targetID).Return(targetUser, nil).Times(1)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:   "Error - Unlock unknown account",
+			role:   models.RoleAdmin,
+			method: "POST",
+			path:   "/admin/users/" + targetID.String() + "/unlock",
+			setupMock: func() {
+				mockAdminService.EXPECT().UnlockUser(gomock.Any(), actorID, targetID).Return(models.AdminUser{}, errs.ErrAccountNotFound).Times(1)
+			},
+			expectedStatus:  http.StatusNotFound,
+			expectedMessage: "Account not found",
+		},
+		{
+			name:   "Success - Admin debits balance",
+			role:   models.RoleAdmin,
+			method: "POST",
+			path:   "/admin/users/" + targetID.String() + "/adjustments",
+			body:   models.AdjustmentRequest{Currency: "USD", Amount: -50, Reason: "chargeback"},
+			setupMock: func() {
+				mockAdminService.EXPECT().
+					AdjustBalance(gomock.Any(), actorID, targetID, "USD", decimal.NewFromFloat(-50), "chargeback").
+					Return(models.WalletResponse{"USD": decimal.NewFromInt(50)}, nil).Times(1)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:   "Error - Admin adjusts own balance",
+			role:   models.RoleAdmin,
+			method: "POST",
+			path:   "/admin/users/" + actorID.String() + "/adjustments",
+			body:   models.AdjustmentRequest{Currency: "USD", Amount: 1000, Reason: "bonus"},
+			setupMock: func() {
+				mockAdminService.EXPECT().
+					AdjustBalance(gomock.Any(), actorID, actorID, "USD", gomock.Any(), "bonus").
+					Return(nil, errs.ErrSelfAdjustment).Times(1)
+			},
+			expectedStatus:  http.StatusForbidden,
+			expectedMessage: "Cannot adjust your own balance",
+		},
+		{
+			name:            "Error - Adjustment without reason",
+			role:            models.RoleAdmin,
+			method:          "POST",
+			path:            "/admin/users/" + targetID.String() + "/adjustments",
+			body:            models.AdjustmentRequest{Currency: "USD", Amount: 10},
+			expectedStatus:  http.StatusBadRequest,
+			expectedMessage: "Validation failed",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.setupMock != nil {
+				tt.setupMock()
+			}
+
+			var body bytes.Buffer
+			if tt.body != nil {
+				json.NewEncoder(&body).Encode(tt.body)
+			}
+			req, _ := http.NewRequest(tt.method, tt.path, &body)
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("Authorization", "Bearer "+generateTokenWithRole(t, jwtManager, actorID.String(), "staff", tt.role))
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			t.Logf("HTTP статус: %d", w.Code)
+			t.Logf("Ответ сервера: %s", w.Body.String())
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("Ожидался статус %d, но получили: %d", tt.expectedStatus, w.Code)
+			}
+
+			if tt.expectedMessage != "" {
+				var errorResponse middleware.ValidationErrorResponse
+				if err := json.NewDecoder(w.Body).Decode(&errorResponse); err != nil {
+					t.Fatalf("Ошибка декодирования ответа с ошибкой: %v. Тело ответа: %s", err, w.Body.String())
+				}
+
+				if errorResponse.Error.Message != tt.expectedMessage {
+					t.Fatalf("Ожидалось сообщение ошибки '%s', но получили: '%s'", tt.expectedMessage, errorResponse.Error.Message)
+				}
+			}
+
+			t.Logf("✅ Тест '%s' прошел успешно", tt.name)
+		})
+	}
+}
+
+func TestFrozenAccountOperations(t *testing.T) {
+	router, mockCtrl, mockSvc, validator, handler, cfg := SetupTestEnv(t)
+	defer mockCtrl.Finish()
+
+	jwtManager := newJWTManager(t, cfg)
+	allowTokens(mockSvc)
+	router.POST("/wallet/withdraw",
+		middleware.AuthMiddleware(jwtManager, mockSvc),
+		middleware.ValidationMiddleware[models.WalletTransaction](validator),
+		handler.Withdraw,
+	)
+
+	userID := "11ff6680-c604-4231-9453-6e2fbc2c30dc"
+	mockSvc.AuthService.(*mocks.MockAuthService).EXPECT().
+		VerifyWithdrawal(gomock.Any(), uuid.MustParse(userID), "USD", gomock.Any(), "").
+		Return(nil).Times(1)
+	mockSvc.WalletService.(*mocks.MockWalletService).EXPECT().
+		Withdraw(gomock.Any(), uuid.MustParse(userID), "USD", gomock.Any()).
+		Return(nil, errs.ErrAccountFrozen).Times(1)
+
+	reqBody, _ := json.Marshal(models.WalletTransaction{Currency: "USD", Amount: 10})
+	req, _ := http.NewRequest("POST", "/wallet/withdraw", bytes.NewReader(reqBody))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+generateToken(t, jwtManager, userID, "testuser"))
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	t.Logf("HTTP статус: %d, ответ сервера: %s", w.Code, w.Body.String())
+
+	if w.Code != http.StatusForbidden {
+		t.Fatalf("Ожидался статус %d, но получили: %d", http.StatusForbidden, w.Code)
+	}
+
+	t.Logf("✅ Тест операций замороженного аккаунта прошел успешно")
+}
+
+// adjustmentStub корректировка баланса без обращения к базе
+type adjustmentStub struct {
+	storage.AdminStorage
+	adjusted int
+}
+
+func (s *adjustmentStub) AdjustBalance(_ context.Context, _, _ uuid.UUID, _ string, amount decimal.Decimal, _ string) (models.OperationResult, error) {
+	s.adjusted++
+	return models.OperationResult{
+		Currency: "USD",
+		Amount:   amount.Round(2),
+		Balance:  models.WalletResponse{"USD": decimal.NewFromInt(150)},
+	}, nil
+}
+
+func TestAdjustBalanceService(t *testing.T) {
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel)
+	stub := &adjustmentStub{}
+	svc := service.NewAdminService(&storage.Storage{AdminStorage: stub}, logger, nil)
+
+	ctx := context.Background()
+	actorID, userID := uuid.New(), uuid.New()
+
+	// Администратор не может начислить средства самому себе
+	if _, err := svc.AdjustBalance(ctx, actorID, actorID, "USD", decimal.NewFromInt(1000), "bonus"); !errors.Is(err, errs.ErrSelfAdjustment) {
+		t.Fatalf("Ожидалась ошибка %v, но получили: %v", errs.ErrSelfAdjustment, err)
+	}
+	if stub.adjusted != 0 {
+		t.Fatalf("Корректировка своего баланса не должна доходить до хранилища")
+	}
+
+	// Корректировка другого пользователя возвращает баланс после проведения
+	balance, err := svc.AdjustBalance(ctx, actorID, userID, "usd", decimal.RequireFromString("50.004"), "refund")
+	if err != nil {
+		t.Fatalf("Ошибка корректировки: %v", err)
+	}
+	if stub.adjusted != 1 || !balance["USD"].Equal(decimal.NewFromInt(150)) {
+		t.Fatalf("Неожиданный результат корректировки: %v", balance)
+	}
+}
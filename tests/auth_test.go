@@ -2,6 +2,7 @@ package tests
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
@@ -39,6 +40,8 @@ func SetupTestEnv(t *testing.T) (
 		AuthService:     mocks.NewMockAuthService(mockCtrl),
 		ExchangeService: mocks.NewMockExchangeService(mockCtrl),
 		WalletService:   mocks.NewMockWalletService(mockCtrl),
+		AdminService:    mocks.NewMockAdminService(mockCtrl),
+		WebhookService:  mocks.NewMockWebhookService(mockCtrl),
 	}
 
 	logger := logrus.New()
@@ -47,14 +50,15 @@ func SetupTestEnv(t *testing.T) (
 		Logging:  config.LoggerConfig{},
 		Database: config.PostgresConfig{},
 		Auth: config.AuthConfig{
-			SecretKey: "secret",
-			TokenTTl:  time.Second * 30,
+			SecretKey:  "secret",
+			TokenTTl:   time.Second * 30,
+			AllowHS256: true,
 		},
 		Redis:           config.RedisConfig{},
 		ExchangeService: config.ExchangeService{},
 	}
 	validator := validate.NewValidator()
-	handler := rest.NewHandler(mockSvc, logger, &cfg.Auth, validator)
+	handler := rest.NewHandler(mockSvc, logger, &cfg.Auth, validator, nil, &cfg.Stream)
 
 	// Настройка тестового роутера
 	router := gin.New()
@@ -64,6 +68,13 @@ func SetupTestEnv(t *testing.T) (
 	return router, mockCtrl, mockSvc, validator, handler, cfg
 }
 
+// allowTokens разрешает все токены в denylist-проверке AuthMiddleware
+func allowTokens(mockSvc *service.Service) {
+	mockSvc.AuthService.(*mocks.MockAuthService).EXPECT().
+		IsTokenRevoked(gomock.Any(), gomock.Any()).
+		Return(false, nil).AnyTimes()
+}
+
 func TestUserRegister(t *testing.T) {
 	router, mockCtrl, mockSvc, validator, handler, _ := SetupTestEnv(t)
 	defer mockCtrl.Finish()
@@ -201,7 +212,7 @@ func TestUserLogin(t *testing.T) {
 	tests := []struct {
 		name            string
 		input           models.UserLogin
-		mockServiceResp string
+		mockServiceResp models.LoginSuccessResponse
 		mockServiceErr  error
 		expectedStatus  int
 		expectedMessage string
@@ -212,7 +223,7 @@ func TestUserLogin(t *testing.T) {
 				Username: "username",
 				Password: "password123",
 			},
-			mockServiceResp: "valid-token",
+			mockServiceResp: models.LoginSuccessResponse{Token: "valid-token", RefreshToken: "refresh-token", ExpiresIn: 900},
 			mockServiceErr:  nil,
 			expectedStatus:  http.StatusOK,
 			expectedMessage: "valid-token",
@@ -223,7 +234,7 @@ func TestUserLogin(t *testing.T) {
 				Username: "username",
 				Password: "wrongpassword",
 			},
-			mockServiceResp: "",
+			mockServiceResp: models.LoginSuccessResponse{},
 			mockServiceErr:  errs.ErrInvalidPassword,
 			expectedStatus:  http.StatusUnauthorized,
 			expectedMessage: "invalid credentials",
@@ -234,18 +245,29 @@ func TestUserLogin(t *testing.T) {
 				Username: "username",
 				Password: "password123",
 			},
-			mockServiceResp: "",
+			mockServiceResp: models.LoginSuccessResponse{},
 			mockServiceErr:  errs.ErrUserNotFound,
 			expectedStatus:  http.StatusUnauthorized,
 			expectedMessage: "invalid credentials",
 		},
+		{
+			name: "Error - Login locked",
+			input: models.UserLogin{
+				Username: "username",
+				Password: "password123",
+			},
+			mockServiceResp: models.LoginSuccessResponse{},
+			mockServiceErr:  &errs.LoginLockedError{Until: time.Now().Add(time.Minute)},
+			expectedStatus:  http.StatusTooManyRequests,
+			expectedMessage: "Too many failed login attempts, try again later",
+		},
 		{
 			name: "Error - Empty password",
 			input: models.UserLogin{
 				Username: "username",
 				Password: "",
 			},
-			mockServiceResp: "",
+			mockServiceResp: models.LoginSuccessResponse{},
 			mockServiceErr:  nil,
 			expectedStatus:  http.StatusBadRequest,
 			expectedMessage: "Validation failed",
@@ -255,7 +277,7 @@ func TestUserLogin(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			mockCall := mockSvc.AuthService.(*mocks.MockAuthService).EXPECT().
-				Login(gomock.Any(), gomock.Any()).
+				Login(gomock.Any(), gomock.Any(), gomock.Any()).
 				Return(tt.mockServiceResp, tt.mockServiceErr)
 
 			// Если тест на валидацию (не передается в сервис), ожидаем 0 вызовов
@@ -301,3 +323,190 @@ func TestUserLogin(t *testing.T) {
 		})
 	}
 }
+
+func TestLoginLockedRetryAfter(t *testing.T) {
+	router, mockCtrl, mockSvc, validator, handler, _ := SetupTestEnv(t)
+	defer mockCtrl.Finish()
+
+	router.POST("/auth/login", middleware.ValidationMiddleware[models.UserLogin](validator), handler.Login)
+
+	// Сервис получает IP клиента, по которому считаются неудачные входы
+	mockSvc.AuthService.(*mocks.MockAuthService).EXPECT().
+		Login(gomock.Any(), gomock.Any(), "10.0.0.7").
+		Return(models.LoginSuccessResponse{}, &errs.LoginLockedError{Until: time.Now().Add(90 * time.Second)})
+
+	reqBody, _ := json.Marshal(models.UserLogin{Username: "username", Password: "password123"})
+	req, _ := http.NewRequest("POST", "/auth/login", bytes.NewReader(reqBody))
+	req.Header.Set("Content-Type", "application/json")
+	req.RemoteAddr = "10.0.0.7:4321"
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("Ожидался статус %d, но получили: %d", http.StatusTooManyRequests, w.Code)
+	}
+	if retryAfter := w.Header().Get(middleware.RetryAfterHeader); retryAfter != "90" {
+		t.Fatalf("Ожидался Retry-After 90, но получили: %q", retryAfter)
+	}
+}
+
+func TestLockoutDuration(t *testing.T) {
+	cfg := &config.LockoutConfig{BaseDuration: time.Minute, MaxDuration: 10 * time.Minute}
+
+	expected := []time.Duration{time.Minute, 2 * time.Minute, 4 * time.Minute, 8 * time.Minute, 10 * time.Minute, 10 * time.Minute}
+	for lockouts, want := range expected {
+		if got := service.LockoutDuration(cfg, lockouts); got != want {
+			t.Fatalf("После %d блокировок ожидалось %s, но получили: %s", lockouts, want, got)
+		}
+	}
+	// Большое число блокировок не переполняет длительность
+	if got := service.LockoutDuration(cfg, 100); got != cfg.MaxDuration {
+		t.Fatalf("Ожидалось %s, но получили: %s", cfg.MaxDuration, got)
+	}
+}
+
+func TestRefreshToken(t *testing.T) {
+	router, mockCtrl, mockSvc, validator, handler, _ := SetupTestEnv(t)
+	defer mockCtrl.Finish()
+
+	router.POST("/auth/refresh", middleware.ValidationMiddleware[models.RefreshRequest](validator), handler.Refresh)
+
+	tests := []struct {
+		name            string
+		input           models.RefreshRequest
+		mockServiceResp models.LoginSuccessResponse
+		mockServiceErr  error
+		expectCall      bool
+		expectedStatus  int
+		expectedMessage string
+	}{
+		{
+			name:            "Success - Tokens rotated",
+			input:           models.RefreshRequest{RefreshToken: "refresh-token"},
+			mockServiceResp: models.LoginSuccessResponse{Token: "new-token", RefreshToken: "new-refresh-token", ExpiresIn: 900},
+			expectCall:      true,
+			expectedStatus:  http.StatusOK,
+		},
+		{
+			name:            "Error - Revoked or unknown refresh token",
+			input:           models.RefreshRequest{RefreshToken: "stolen-token"},
+			mockServiceErr:  errs.ErrInvalidRefresh,
+			expectCall:      true,
+			expectedStatus:  http.StatusUnauthorized,
+			expectedMessage: "Invalid or expired refresh token",
+		},
+		{
+			name:            "Error - Empty refresh token",
+			input:           models.RefreshRequest{},
+			expectedStatus:  http.StatusBadRequest,
+			expectedMessage: "Validation failed",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.expectCall {
+				mockSvc.AuthService.(*mocks.MockAuthService).EXPECT().
+					Refresh(gomock.Any(), tt.input.RefreshToken).
+					Return(tt.mockServiceResp, tt.mockServiceErr).Times(1)
+			}
+
+			reqBody, _ := json.Marshal(tt.input)
+			req, _ := http.NewRequest("POST", "/auth/refresh", bytes.NewReader(reqBody))
+			req.Header.Set("Content-Type", "application/json")
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			t.Logf("HTTP статус: %d", w.Code)
+			t.Logf("Ответ сервера: %s", w.Body.String())
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("Ожидался статус %d, но получили: %d", tt.expectedStatus, w.Code)
+			}
+
+			if tt.expectedStatus == http.StatusOK {
+				var successResponse models.LoginSuccessResponse
+				if err := json.NewDecoder(w.Body).Decode(&successResponse); err != nil {
+					t.Fatalf("Ошибка декодирования успешного ответа: %v. Тело ответа: %s", err, w.Body.String())
+				}
+
+				if successResponse != tt.mockServiceResp {
+					t.Fatalf("Ожидались токены %+v, но получили: %+v", tt.mockServiceResp, successResponse)
+				}
+			} else {
+				var errorResponse middleware.ValidationErrorResponse
+				if err := json.NewDecoder(w.Body).Decode(&errorResponse); err != nil {
+					t.Fatalf("Ошибка декодирования ответа с ошибкой: %v. Тело ответа: %s", err, w.Body.String())
+				}
+
+				if errorResponse.Error.Message != tt.expectedMessage {
+					t.Fatalf("Ожидалось сообщение ошибки '%s', но получили: '%s'", tt.expectedMessage, errorResponse.Error.Message)
+				}
+			}
+
+			t.Logf("✅ Тест '%s' прошел успешно", tt.name)
+		})
+	}
+}
+
+func TestLogout(t *testing.T) {
+	router, mockCtrl, mockSvc, validator, handler, cfg := SetupTestEnv(t)
+	defer mockCtrl.Finish()
+
+	jwtManager := newJWTManager(t, cfg)
+	mockAuthService := mockSvc.AuthService.(*mocks.MockAuthService)
+
+	router.POST("/auth/logout",
+		middleware.AuthMiddleware(jwtManager, mockSvc),
+		middleware.ValidationMiddleware[models.LogoutRequest](validator),
+		handler.Logout,
+	)
+
+	userID := "11ff6680-c604-4231-9453-6e2fbc2c30dc"
+	token := generateToken(t, jwtManager, userID, "testuser")
+
+	send := func() *httptest.ResponseRecorder {
+		reqBody, _ := json.Marshal(models.LogoutRequest{RefreshToken: "refresh-token"})
+		req, _ := http.NewRequest("POST", "/auth/logout", bytes.NewReader(reqBody))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+token)
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		t.Logf("HTTP статус: %d, ответ сервера: %s", w.Code, w.Body.String())
+		return w
+	}
+
+	// Выход отзывает refresh-токен и вносит jti access-токена в denylist
+	var revokedJTI string
+	gomock.InOrder(
+		mockAuthService.EXPECT().IsTokenRevoked(gomock.Any(), gomock.Any()).Return(false, nil),
+		mockAuthService.EXPECT().
+			Logout(gomock.Any(), gomock.Any(), "refresh-token").
+			DoAndReturn(func(_ context.Context, claims *models.Claims, _ string) error {
+				if claims.UserID != userID {
+					t.Fatalf("Ожидался user_id %s, но получили: %s", userID, claims.UserID)
+				}
+				revokedJTI = claims.ID
+				return nil
+			}),
+		mockAuthService.EXPECT().
+			IsTokenRevoked(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, claims *models.Claims) (bool, error) {
+				return claims.ID == revokedJTI, nil
+			}),
+	)
+
+	if w := send(); w.Code != http.StatusOK {
+		t.Fatalf("Ожидался статус %d, но получили: %d", http.StatusOK, w.Code)
+	}
+
+	// Отозванный access-токен больше не принимается
+	if w := send(); w.Code != http.StatusUnauthorized {
+		t.Fatalf("Ожидался статус %d, но получили: %d", http.StatusUnauthorized, w.Code)
+	}
+
+	t.Logf("✅ Тест выхода из системы прошел успешно")
+}
@@ -0,0 +1,271 @@
+package tests
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+
+	config "gw-currency-wallet/internal/config"
+	"gw-currency-wallet/internal/delivery/middleware"
+	"gw-currency-wallet/internal/errs"
+	"gw-currency-wallet/internal/infrastructure/mail"
+	"gw-currency-wallet/internal/service/mocks"
+	"gw-currency-wallet/internal/storage/models"
+)
+
+func TestEmailVerification(t *testing.T) {
+	router, mockCtrl, mockSvc, validator, handler, cfg := SetupTestEnv(t)
+	defer mockCtrl.Finish()
+	mockAuthService := mockSvc.AuthService.(*mocks.MockAuthService)
+
+	jwtManager := newJWTManager(t, cfg)
+	allowTokens(mockSvc)
+	router.POST("/auth/verify-email", middleware.ValidationMiddleware[models.VerifyEmailRequest](validator), handler.VerifyEmail)
+	router.POST("/auth/verify-email/resend", middleware.AuthMiddleware(jwtManager, mockSvc), handler.ResendVerification)
+
+	userID := uuid.MustParse("11ff6680-c604-4231-9453-6e2fbc2c30dc")
+	token := generateToken(t, jwtManager, userID.String(), "testuser")
+	send := func(path string, body interface{}) *httptest.ResponseRecorder {
+		var reqBody bytes.Buffer
+		if body != nil {
+			json.NewEncoder(&reqBody).Encode(body)
+		}
+		req, _ := http.NewRequest("POST", path, &reqBody)
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	gomock.InOrder(
+		mockAuthService.EXPECT().VerifyEmail(gomock.Any(), "valid").Return(nil),
+		mockAuthService.EXPECT().VerifyEmail(gomock.Any(), "valid").Return(errs.ErrInvalidEmailToken),
+	)
+	if w := send("/auth/verify-email", models.VerifyEmailRequest{Token: "valid"}); w.Code != http.StatusOK {
+		t.Fatalf("Ожидался статус %d, но получили: %d %s", http.StatusOK, w.Code, w.Body.String())
+	}
+	// Токен одноразовый
+	w := send("/auth/verify-email", models.VerifyEmailRequest{Token: "valid"})
+	var errorResponse middleware.ValidationErrorResponse
+	json.Unmarshal(w.Body.Bytes(), &errorResponse)
+	if w.Code != http.StatusBadRequest || errorResponse.Error.Message != "Invalid or expired token" {
+		t.Fatalf("Ожидалась ошибка токена, но получили: %d %s", w.Code, w.Body.String())
+	}
+
+	if w := send("/auth/verify-email", models.VerifyEmailRequest{}); w.Code != http.StatusBadRequest {
+		t.Fatalf("Ожидался статус %d, но получили: %d", http.StatusBadRequest, w.Code)
+	}
+
+	gomock.InOrder(
+		mockAuthService.EXPECT().ResendVerification(gomock.Any(), userID).Return(nil),
+		mockAuthService.EXPECT().ResendVerification(gomock.Any(), userID).Return(errs.ErrEmailAlreadyVerified),
+	)
+	if w := send("/auth/verify-email/resend", nil); w.Code != http.StatusOK {
+		t.Fatalf("Ожидался статус %d, но получили: %d %s", http.StatusOK, w.Code, w.Body.String())
+	}
+	if w := send("/auth/verify-email/resend", nil); w.Code != http.StatusConflict {
+		t.Fatalf("Ожидался статус %d, но получили: %d", http.StatusConflict, w.Code)
+	}
+}
+
+func TestPasswordReset(t *testing.T) {
+	router, mockCtrl, mockSvc, validator, handler, _ := SetupTestEnv(t)
+	defer mockCtrl.Finish()
+	mockAuthService := mockSvc.AuthService.(*mocks.MockAuthService)
+
+	router.POST("/auth/password/forgot", middleware.ValidationMiddleware[models.ForgotPasswordRequest](validator), handler.ForgotPassword)
+	router.POST("/auth/password/reset", middleware.ValidationMiddleware[models.ResetPasswordRequest](validator), handler.ResetPassword)
+
+	send := func(path string, body interface{}) *httptest.ResponseRecorder {
+		reqBody, _ := json.Marshal(body)
+		req, _ := http.NewRequest("POST", path, bytes.NewReader(reqBody))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	// Ответ не зависит от того, зарегистрирован ли адрес: это решает сервис
+	mockAuthService.EXPECT().ForgotPassword(gomock.Any(), "user@example.com").Return(nil)
+	if w := send("/auth/password/forgot", models.ForgotPasswordRequest{Email: "user@example.com"}); w.Code != http.StatusOK {
+		t.Fatalf("Ожидался статус %d, но получили: %d %s", http.StatusOK, w.Code, w.Body.String())
+	}
+	if w := send("/auth/password/forgot", models.ForgotPasswordRequest{Email: "not-an-email"}); w.Code != http.StatusBadRequest {
+		t.Fatalf("Ожидался статус %d, но получили: %d", http.StatusBadRequest, w.Code)
+	}
+
+	gomock.InOrder(
+		mockAuthService.EXPECT().
+			ResetPassword(gomock.Any(), models.ResetPasswordRequest{Token: "reset", Password: "newpassword"}).
+			Return(nil),
+		mockAuthService.EXPECT().
+			ResetPassword(gomock.Any(), gomock.Any()).
+			Return(errs.ErrInvalidEmailToken),
+	)
+	if w := send("/auth/password/reset", models.ResetPasswordRequest{Token: "reset", Password: "newpassword"}); w.Code != http.StatusOK {
+		t.Fatalf("Ожидался статус %d, но получили: %d %s", http.StatusOK, w.Code, w.Body.String())
+	}
+	if w := send("/auth/password/reset", models.ResetPasswordRequest{Token: "reset", Password: "newpassword"}); w.Code != http.StatusBadRequest {
+		t.Fatalf("Ожидался статус %d, но получили: %d", http.StatusBadRequest, w.Code)
+	}
+
+	// Слабый пароль отклоняется до сервиса
+	if w := send("/auth/password/reset", models.ResetPasswordRequest{Token: "reset", Password: "short"}); w.Code != http.StatusBadRequest {
+		t.Fatalf("Ожидался статус %d, но получили: %d", http.StatusBadRequest, w.Code)
+	}
+}
+
+func TestDepositRequiresVerifiedEmail(t *testing.T) {
+	router, mockCtrl, mockSvc, validator, handler, cfg := SetupTestEnv(t)
+	defer mockCtrl.Finish()
+
+	jwtManager := newJWTManager(t, cfg)
+	allowTokens(mockSvc)
+	router.POST("/wallet/deposit",
+		middleware.AuthMiddleware(jwtManager, mockSvc),
+		middleware.ValidationMiddleware[models.WalletTransaction](validator),
+		handler.Deposit,
+	)
+
+	userID := uuid.MustParse("11ff6680-c604-4231-9453-6e2fbc2c30dc")
+	mockSvc.WalletService.(*mocks.MockWalletService).EXPECT().
+		Deposit(gomock.Any(), userID, "USD", gomock.Any()).
+		Return(nil, errs.ErrEmailNotVerified)
+
+	reqBody, _ := json.Marshal(models.WalletTransaction{Currency: "USD", Amount: 100})
+	req, _ := http.NewRequest("POST", "/wallet/deposit", bytes.NewReader(reqBody))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+generateToken(t, jwtManager, userID.String(), "testuser"))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	var errorResponse middleware.ValidationErrorResponse
+	json.Unmarshal(w.Body.Bytes(), &errorResponse)
+	if w.Code != http.StatusForbidden || errorResponse.Error.Message != "Email is not verified" {
+		t.Fatalf("Ожидался отказ до подтверждения email, но получили: %d %s", w.Code, w.Body.String())
+	}
+}
+
+func TestLogMailerWritesFiles(t *testing.T) {
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel)
+	dir := t.TempDir()
+	mailer, err := mail.NewMailer(&config.MailConfig{Driver: "log", From: "wallet@example.com", Dir: dir}, logger)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	msg := mail.Message{To: "user@example.com", Subject: "Подтвердите email", Body: "Token: abc\n"}
+	if err := mailer.Send(context.Background(), msg); err != nil {
+		t.Fatal(err)
+	}
+
+	files, _ := filepath.Glob(filepath.Join(dir, "*.eml"))
+	if len(files) != 1 {
+		t.Fatalf("Ожидался один файл письма, но получили: %v", files)
+	}
+	data, _ := os.ReadFile(files[0])
+	for _, want := range []string{"From: wallet@example.com\r\n", "To: user@example.com\r\n", "=?utf-8?q?", "\r\n\r\nToken: abc\r\n"} {
+		if !strings.Contains(string(data), want) {
+			t.Fatalf("В письме нет %q:\n%s", want, data)
+		}
+	}
+
+	// Перевод строки в адресе позволил бы дописать заголовки
+	msg.To = "user@example.com\r\nBcc: attacker@example.com"
+	if err := mailer.Send(context.Background(), msg); err == nil {
+		t.Fatal("Ожидалась ошибка для адреса с переводом строки")
+	}
+}
+
+func TestSMTPMailer(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+
+	received := make(chan string, 1)
+	go serveSMTP(t, listener, received)
+
+	addr := listener.Addr().(*net.TCPAddr)
+	mailer := mail.NewSMTPMailer(&config.MailConfig{
+		Driver:  "smtp",
+		From:    "Wallet <wallet@example.com>",
+		Timeout: 2 * time.Second,
+		SMTP:    config.SMTPConfig{Host: "127.0.0.1", Port: addr.Port},
+	})
+	err = mailer.Send(context.Background(), mail.Message{To: "user@example.com", Subject: "Reset your password", Body: "Token: abc"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case transcript := <-received:
+		for _, want := range []string{"MAIL FROM:<wallet@example.com>", "RCPT TO:<user@example.com>", "Subject: Reset your password", "Token: abc"} {
+			if !strings.Contains(transcript, want) {
+				t.Fatalf("В сессии SMTP нет %q:\n%s", want, transcript)
+			}
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("SMTP-сервер не получил письмо")
+	}
+}
+
+// serveSMTP минимальный SMTP-сервер без STARTTLS и авторизации: принимает одно письмо и возвращает запись сессии
+func serveSMTP(t *testing.T, listener net.Listener, received chan<- string) {
+	conn, err := listener.Accept()
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	var transcript strings.Builder
+	reader := bufio.NewReader(conn)
+	reply := func(line string) { conn.Write([]byte(line + "\r\n")) }
+	reply("220 localhost ESMTP")
+	inData := false
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			t.Errorf("SMTP: %v", err)
+			return
+		}
+		transcript.WriteString(line)
+		command := strings.ToUpper(strings.TrimSpace(line))
+		switch {
+		case inData:
+			if command == "." {
+				inData = false
+				reply("250 OK")
+			}
+		case strings.HasPrefix(command, "EHLO"):
+			reply("250 localhost")
+		case strings.HasPrefix(command, "MAIL"), strings.HasPrefix(command, "RCPT"):
+			reply("250 OK")
+		case command == "DATA":
+			inData = true
+			reply("354 Go ahead")
+		case command == "QUIT":
+			reply("221 Bye")
+			received <- transcript.String()
+			return
+		default:
+			reply("502 Not implemented")
+		}
+	}
+}
@@ -0,0 +1,184 @@
+package tests
+
+import (
+	"context"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	exchange "github.com/AndrewTarev/proto-repo/gen/exchange"
+	"github.com/sirupsen/logrus"
+	grpclib "google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	config "gw-currency-wallet/internal/config"
+	"gw-currency-wallet/internal/infrastructure/grpc"
+)
+
+// scriptedExchanger отвечает ошибками из очереди, затем успешно; считает вызовы
+type scriptedExchanger struct {
+	exchange.UnimplementedExchangeServiceServer
+
+	mu    sync.Mutex
+	errs  []error
+	delay time.Duration
+	calls int
+	down  bool
+}
+
+func (s *scriptedExchanger) script(delay time.Duration, down bool, errs ...error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.delay, s.down, s.errs, s.calls = delay, down, errs, 0
+}
+
+func (s *scriptedExchanger) callCount() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.calls
+}
+
+func (s *scriptedExchanger) GetExchangeRates(ctx context.Context, _ *exchange.Empty) (*exchange.ExchangeRatesResponse, error) {
+	s.mu.Lock()
+	s.calls++
+	delay, down := s.delay, s.down
+	var err error
+	if len(s.errs) > 0 {
+		err, s.errs = s.errs[0], s.errs[1:]
+	}
+	s.mu.Unlock()
+
+	if delay > 0 {
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	if down {
+		return nil, status.Error(codes.Unavailable, "exchanger is down")
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &exchange.ExchangeRatesResponse{Rates: map[string]string{"USD": "0.013"}}, nil
+}
+
+func setupExchangeClient(t *testing.T, cfg config.ExchangeService) (*grpc.ExchangeClient, *scriptedExchanger) {
+	t.Helper()
+
+	fake := &scriptedExchanger{}
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Не удалось открыть порт: %v", err)
+	}
+	server := grpclib.NewServer()
+	exchange.RegisterExchangeServiceServer(server, fake)
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	cfg.Addr = listener.Addr().String()
+	return grpc.NewUserServiceClient(&cfg, logrus.New()), fake
+}
+
+func TestExchangeClientRetries(t *testing.T) {
+	client, fake := setupExchangeClient(t, config.ExchangeService{
+		Timeout:          time.Second,
+		MaxRetries:       2,
+		RetryBackoff:     time.Millisecond,
+		RetryMaxBackoff:  5 * time.Millisecond,
+		BreakerThreshold: 10,
+		BreakerCooldown:  time.Minute,
+	})
+	ctx := context.Background()
+
+	// Временные ошибки повторяются
+	fake.script(0, false, status.Error(codes.Unavailable, "restarting"), status.Error(codes.ResourceExhausted, "busy"))
+	if _, err := client.GetExchangeRates(ctx); err != nil {
+		t.Fatalf("Ожидался успех после повторов, но получили: %v", err)
+	}
+	if calls := fake.callCount(); calls != 3 {
+		t.Fatalf("Ожидалось 3 вызова, но получили: %d", calls)
+	}
+
+	// Ошибки запроса не повторяются
+	fake.script(0, false, status.Error(codes.InvalidArgument, "bad request"))
+	if _, err := client.GetExchangeRates(ctx); status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("Ожидалась ошибка InvalidArgument, но получили: %v", err)
+	}
+	if calls := fake.callCount(); calls != 1 {
+		t.Fatalf("Ошибка запроса не должна повторяться, но вызовов: %d", calls)
+	}
+
+	// Повторов не больше max_retries
+	fake.script(0, true)
+	if _, err := client.GetExchangeRates(ctx); status.Code(err) != codes.Unavailable {
+		t.Fatalf("Ожидалась ошибка Unavailable, но получили: %v", err)
+	}
+	if calls := fake.callCount(); calls != 3 {
+		t.Fatalf("Ожидалось 3 вызова, но получили: %d", calls)
+	}
+
+	t.Logf("✅ Временные ошибки повторяются не больше max_retries")
+}
+
+func TestExchangeClientDeadline(t *testing.T) {
+	client, fake := setupExchangeClient(t, config.ExchangeService{
+		Timeout:          50 * time.Millisecond,
+		BreakerThreshold: 10,
+		BreakerCooldown:  time.Minute,
+	})
+
+	fake.script(time.Second, false)
+	start := time.Now()
+	_, err := client.GetExchangeRates(context.Background())
+	if status.Code(err) != codes.DeadlineExceeded {
+		t.Fatalf("Ожидалась ошибка DeadlineExceeded, но получили: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Fatalf("Вызов не ограничен таймаутом: %s", elapsed)
+	}
+
+	t.Logf("✅ Вызов ограничен дедлайном из конфига")
+}
+
+func TestExchangeClientCircuitBreaker(t *testing.T) {
+	client, fake := setupExchangeClient(t, config.ExchangeService{
+		Timeout:          time.Second,
+		BreakerThreshold: 2,
+		BreakerCooldown:  100 * time.Millisecond,
+	})
+	ctx := context.Background()
+
+	// Две неудачи подряд размыкают цепь
+	fake.script(0, true)
+	for i := 0; i < 2; i++ {
+		client.GetExchangeRates(ctx)
+	}
+	if state := client.BreakerState(); state != grpc.BreakerOpen {
+		t.Fatalf("Ожидалась разомкнутая цепь, но получили: %s", state)
+	}
+
+	// Пока цепь разомкнута, вызовы отклоняются без обращения к сервису
+	_, err := client.GetExchangeRates(ctx)
+	if status.Code(err) != codes.Unavailable {
+		t.Fatalf("Ожидалась ошибка Unavailable, но получили: %v", err)
+	}
+	if calls := fake.callCount(); calls != 2 {
+		t.Fatalf("Разомкнутая цепь не должна пропускать вызовы, но вызовов: %d", calls)
+	}
+
+	// После cooldown пробный вызов замыкает цепь
+	fake.script(0, false)
+	time.Sleep(120 * time.Millisecond)
+	if _, err := client.GetExchangeRates(ctx); err != nil {
+		t.Fatalf("Ожидался успешный пробный вызов, но получили: %v", err)
+	}
+	if state := client.BreakerState(); state != grpc.BreakerClosed {
+		t.Fatalf("Ожидалась замкнутая цепь, но получили: %s", state)
+	}
+
+	t.Logf("✅ Circuit breaker отклоняет вызовы к недоступному сервису и восстанавливается")
+}
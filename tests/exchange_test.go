@@ -9,12 +9,14 @@ import (
 	"net/http/httptest"
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/golang/mock/gomock"
 	"github.com/google/uuid"
 	"github.com/shopspring/decimal"
 
 	"gw-currency-wallet/internal/delivery/middleware"
+	"gw-currency-wallet/internal/errs"
 	"gw-currency-wallet/internal/service/mocks"
 	"gw-currency-wallet/internal/storage/models"
 )
@@ -26,20 +28,26 @@ func TestGetExchangeRates(t *testing.T) {
 	// Регистрируем маршрут
 	router.GET("/exchange/rates", handler.GetExchangeRates)
 
+	asOf := time.Date(2025, 1, 15, 12, 0, 0, 0, time.UTC)
+
 	tests := []struct {
 		name            string
-		mockServiceResp map[string]string
+		mockServiceResp models.ExchangeRates
 		mockServiceErr  error
 		expectedStatus  int
 		expectedRates   map[string]string
+		expectedStale   bool
 		expectedMessage string
 	}{
 		{
 			name: "Success - Get exchange rates",
-			mockServiceResp: map[string]string{
-				"USD": "92.5",
-				"EUR": "100.3",
-				"RUB": "1.0",
+			mockServiceResp: models.ExchangeRates{
+				Rates: map[string]string{
+					"USD": "92.5",
+					"EUR": "100.3",
+					"RUB": "1.0",
+				},
+				AsOf: asOf,
 			},
 			mockServiceErr: nil,
 			expectedStatus: http.StatusOK,
@@ -50,9 +58,21 @@ func TestGetExchangeRates(t *testing.T) {
 			},
 			expectedMessage: "",
 		},
+		{
+			name: "Success - Stale rates while exchanger is down",
+			mockServiceResp: models.ExchangeRates{
+				Rates: map[string]string{"USD": "92.5"},
+				AsOf:  asOf,
+				Stale: true,
+			},
+			mockServiceErr: nil,
+			expectedStatus: http.StatusOK,
+			expectedRates:  map[string]string{"USD": "92.5"},
+			expectedStale:  true,
+		},
 		{
 			name:            "Error - Service failure",
-			mockServiceResp: nil,
+			mockServiceResp: models.ExchangeRates{},
 			mockServiceErr:  errors.New("Internal server error"),
 			expectedStatus:  http.StatusInternalServerError,
 			expectedRates:   nil,
@@ -93,6 +113,10 @@ func TestGetExchangeRates(t *testing.T) {
 				if !reflect.DeepEqual(successResponse.Rates, tt.expectedRates) {
 					t.Fatalf("Ожидались курсы валют %+v, но получили: %+v", tt.expectedRates, successResponse.Rates)
 				}
+				if successResponse.Stale != tt.expectedStale || !successResponse.AsOf.Equal(asOf) {
+					t.Fatalf("Ожидались stale=%v и as_of=%s, но получили: %v и %s",
+						tt.expectedStale, asOf, successResponse.Stale, successResponse.AsOf)
+				}
 			} else {
 				var errorResponse middleware.ValidationErrorResponse
 				if err := json.NewDecoder(w.Body).Decode(&errorResponse); err != nil {
@@ -136,17 +160,17 @@ func TestExchangeCurrency(t *testing.T) {
 			},
 			mockRate: "0.013",
 			mockExchangeResp: models.WalletResponse{
-				BalanceRub: decimal.NewFromFloat(9000.00),
-				BalanceUsd: decimal.NewFromFloat(13.00),
-				BalanceEur: decimal.NewFromFloat(0.00),
+				"RUB": decimal.NewFromFloat(9000.00),
+				"USD": decimal.NewFromFloat(13.00),
+				"EUR": decimal.NewFromFloat(0.00),
 			},
 			mockServiceResp: nil,
 			expectedStatus:  http.StatusOK,
 			expectedMessage: "Exchange successful",
 			expectedNewBalance: models.WalletResponse{
-				BalanceRub: decimal.NewFromFloat(9000.00),
-				BalanceUsd: decimal.NewFromFloat(13.00),
-				BalanceEur: decimal.NewFromFloat(0.00),
+				"RUB": decimal.NewFromFloat(9000.00),
+				"USD": decimal.NewFromFloat(13.00),
+				"EUR": decimal.NewFromFloat(0.00),
 			},
 			expectServiceCalls: true,
 		},
@@ -163,9 +187,9 @@ func TestExchangeCurrency(t *testing.T) {
 			expectedStatus:   http.StatusBadRequest,
 			expectedMessage:  "Validation failed",
 			expectedNewBalance: models.WalletResponse{
-				BalanceRub: decimal.Zero,
-				BalanceUsd: decimal.Zero,
-				BalanceEur: decimal.Zero,
+				"RUB": decimal.Zero,
+				"USD": decimal.Zero,
+				"EUR": decimal.Zero,
 			},
 			expectServiceCalls: false,
 		},
@@ -182,12 +206,19 @@ func TestExchangeCurrency(t *testing.T) {
 			if tt.expectServiceCalls {
 				// Мокаем вызовы сервисов, используем gomock.Any() для UUID
 				mockExchangeService.EXPECT().
-					ExchangeCurrency(gomock.Any(), gomock.Any(), tt.input.FromCurrency, tt.input.ToCurrency, gomock.Any(), gomock.Any()).
+					ExchangeCurrency(gomock.Any(), gomock.Any(), tt.input.FromCurrency, tt.input.ToCurrency, gomock.Any(), gomock.Any(), gomock.Any()).
 					Return(tt.mockExchangeResp, tt.mockServiceResp).Times(1)
 
+				rate := decimal.RequireFromString(tt.mockRate)
 				mockExchangeService.EXPECT().
-					GetRate(gomock.Any(), tt.input.FromCurrency, tt.input.ToCurrency).
-					Return(tt.mockRate, tt.mockServiceResp).Times(1)
+					PriceExchange(gomock.Any(), gomock.Any(), tt.input.FromCurrency, tt.input.ToCurrency, gomock.Any()).
+					Return(models.ExchangeQuote{
+						FromCurrency: tt.input.FromCurrency,
+						ToCurrency:   tt.input.ToCurrency,
+						Rate:         rate,
+						FromAmount:   decimal.NewFromFloat(tt.input.Amount),
+						ToAmount:     decimal.NewFromFloat(tt.input.Amount).Mul(rate),
+					}, tt.mockServiceResp).Times(1)
 			}
 
 			// Создаем запрос и вручную ставим userID в контекст запроса
@@ -222,19 +253,157 @@ func TestExchangeCurrency(t *testing.T) {
 				}
 
 				// Проверяем баланс
-				if !successResponse.NewBalance.BalanceRub.Equal(tt.expectedNewBalance.BalanceRub) {
+				if !successResponse.NewBalance["RUB"].Equal(tt.expectedNewBalance["RUB"]) {
 					t.Fatalf("Ожидался баланс RUB %s, но получили: %s",
-						tt.expectedNewBalance.BalanceRub.String(), successResponse.NewBalance.BalanceRub.String())
+						tt.expectedNewBalance["RUB"].String(), successResponse.NewBalance["RUB"].String())
 				}
-				if !successResponse.NewBalance.BalanceUsd.Equal(tt.expectedNewBalance.BalanceUsd) {
+				if !successResponse.NewBalance["USD"].Equal(tt.expectedNewBalance["USD"]) {
 					t.Fatalf("Ожидался баланс USD %s, но получили: %s",
-						tt.expectedNewBalance.BalanceUsd.String(), successResponse.NewBalance.BalanceUsd.String())
+						tt.expectedNewBalance["USD"].String(), successResponse.NewBalance["USD"].String())
 				}
-				if !successResponse.NewBalance.BalanceEur.Equal(tt.expectedNewBalance.BalanceEur) {
+				if !successResponse.NewBalance["EUR"].Equal(tt.expectedNewBalance["EUR"]) {
 					t.Fatalf("Ожидался баланс EUR %s, но получили: %s",
-						tt.expectedNewBalance.BalanceEur.String(), successResponse.NewBalance.BalanceEur.String())
+						tt.expectedNewBalance["EUR"].String(), successResponse.NewBalance["EUR"].String())
+				}
+
+			} else {
+				var errorResponse middleware.ValidationErrorResponse
+				if err := json.NewDecoder(w.Body).Decode(&errorResponse); err != nil {
+					t.Fatalf("Ошибка декодирования ошибки: %v. Тело ответа: %s", err, w.Body.String())
+				}
+
+				if errorResponse.Error.Message != tt.expectedMessage {
+					t.Fatalf("Ожидалось сообщение ошибки '%s', но получили: '%s'", tt.expectedMessage, errorResponse.Error.Message)
+				}
+			}
+
+			t.Logf("✅ Тест '%s' прошел успешно", tt.name)
+		})
+	}
+}
+
+func TestExchangeCurrencyWithQuote(t *testing.T) {
+	router, mockCtrl, mockSvc, validator, handler, cfg := SetupTestEnv(t)
+	defer mockCtrl.Finish()
+
+	jwtManager := newJWTManager(t, cfg)
+	allowTokens(mockSvc)
+	router.POST("/exchange",
+		middleware.AuthMiddleware(jwtManager, mockSvc),
+		middleware.ValidationMiddleware[models.ExchangeRequest](validator),
+		handler.ExchangeCurrency,
+	)
+
+	userID := uuid.Must(uuid.Parse("11ff6680-c604-4231-9453-6e2fbc2c30dc"))
+	token := generateToken(t, jwtManager, userID.String(), "testuser")
+
+	quote := models.ExchangeQuote{
+		QuoteID:      uuid.New(),
+		FromCurrency: "RUB",
+		ToCurrency:   "USD",
+		Rate:         decimal.RequireFromString("0.013"),
+		FromAmount:   decimal.NewFromFloat(1000),
+		ToAmount:     decimal.RequireFromString("12.87"),
+		Fee:          decimal.RequireFromString("0.13"),
+		FeeCurrency:  "USD",
+		ExpiresAt:    time.Now().Add(30 * time.Second),
+	}
+
+	tests := []struct {
+		name               string
+		input              models.ExchangeRequest
+		mockQuoteErr       error
+		expectExchangeCall bool
+		mockExchangeErr    error
+		expectRelease      bool
+		expectedStatus     int
+		expectedMessage    string
+	}{
+		{
+			name:               "Success - Exchange by quote",
+			input:              models.ExchangeRequest{QuoteID: quote.QuoteID.String()},
+			expectExchangeCall: true,
+			expectedStatus:     http.StatusOK,
+			expectedMessage:    "Exchange successful",
+		},
+		{
+			name:            "Error - Quote does not match request",
+			input:           models.ExchangeRequest{QuoteID: quote.QuoteID.String(), Amount: 500},
+			mockQuoteErr:    errs.ErrQuoteMismatch,
+			expectedStatus:  http.StatusBadRequest,
+			expectedMessage: "Exchange request does not match quote",
+		},
+		{
+			name:               "Error - Exchange failed, quote released",
+			input:              models.ExchangeRequest{QuoteID: quote.QuoteID.String()},
+			expectExchangeCall: true,
+			mockExchangeErr:    errs.ErrInsufficientFunds,
+			expectRelease:      true,
+			expectedStatus:     http.StatusBadRequest,
+			expectedMessage:    "Insufficient funds",
+		},
+		{
+			name:            "Error - Quote already used",
+			input:           models.ExchangeRequest{QuoteID: quote.QuoteID.String()},
+			mockQuoteErr:    errs.ErrQuoteAlreadyUsed,
+			expectedStatus:  http.StatusConflict,
+			expectedMessage: "Exchange quote already used",
+		},
+		{
+			name:            "Error - Quote expired",
+			input:           models.ExchangeRequest{QuoteID: quote.QuoteID.String()},
+			mockQuoteErr:    errs.ErrQuoteExpired,
+			expectedStatus:  http.StatusGone,
+			expectedMessage: "Exchange quote expired or not found",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockExchangeService := mockSvc.ExchangeService.(*mocks.MockExchangeService)
+
+			mockExchangeService.EXPECT().
+				UseQuote(gomock.Any(), userID, quote.QuoteID, tt.input).
+				Return(quote, tt.mockQuoteErr).Times(1)
+
+			if tt.expectExchangeCall {
+				// Обмен выполняется строго по сумме, курсу и комиссии из котировки
+				mockExchangeService.EXPECT().
+					ExchangeCurrency(gomock.Any(), userID, quote.FromCurrency, quote.ToCurrency, quote.FromAmount, quote.ToAmount, quote.Fee).
+					Return(models.WalletResponse{"RUB": decimal.Zero, "USD": quote.ToAmount}, tt.mockExchangeErr).Times(1)
+			}
+			if tt.expectRelease {
+				mockExchangeService.EXPECT().ReleaseQuote(gomock.Any(), userID, quote.QuoteID).Times(1)
+			}
+
+			reqBody, _ := json.Marshal(tt.input)
+			req, _ := http.NewRequest("POST", "/exchange", bytes.NewReader(reqBody))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("Authorization", "Bearer "+token)
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			t.Logf("HTTP статус: %d", w.Code)
+			t.Logf("Ответ сервера: %s", w.Body.String())
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("Ожидался статус %d, но получили: %d", tt.expectedStatus, w.Code)
+			}
+
+			if tt.expectedStatus == http.StatusOK {
+				var successResponse models.ExchangeCurrencyResponse
+				if err := json.NewDecoder(w.Body).Decode(&successResponse); err != nil {
+					t.Fatalf("Ошибка декодирования успешного ответа: %v. Тело ответа: %s", err, w.Body.String())
 				}
 
+				if !successResponse.Rate.Equal(quote.Rate) {
+					t.Fatalf("Ожидался курс %s, но получили: %s", quote.Rate, successResponse.Rate)
+				}
+				if !successResponse.Fee.Equal(quote.Fee) || successResponse.FeeCurrency != quote.FeeCurrency {
+					t.Fatalf("Ожидалась комиссия %s %s, но получили: %s %s",
+						quote.Fee, quote.FeeCurrency, successResponse.Fee, successResponse.FeeCurrency)
+				}
 			} else {
 				var errorResponse middleware.ValidationErrorResponse
 				if err := json.NewDecoder(w.Body).Decode(&errorResponse); err != nil {
@@ -0,0 +1,179 @@
+package tests
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	exchange "github.com/AndrewTarev/proto-repo/gen/exchange"
+	"github.com/sirupsen/logrus"
+	grpclib "google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+
+	config "gw-currency-wallet/internal/config"
+	"gw-currency-wallet/internal/infrastructure/grpc"
+)
+
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	pem  []byte
+}
+
+func newTestCA(t *testing.T, name string) *testCA {
+	t.Helper()
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: name},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("Не удалось создать CA: %v", err)
+	}
+	cert, _ := x509.ParseCertificate(der)
+	return &testCA{cert: cert, key: key, pem: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})}
+}
+
+// issue выпускает сертификат и ключ в PEM; для сервера — с DNS-именем
+func (ca *testCA) issue(t *testing.T, name string, server bool) ([]byte, []byte) {
+	t.Helper()
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	if server {
+		template.DNSNames = []string{name}
+		template.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth}
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, ca.cert, &key.PublicKey, ca.key)
+	if err != nil {
+		t.Fatalf("Не удалось выпустить сертификат: %v", err)
+	}
+	keyDER, _ := x509.MarshalECPrivateKey(key)
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+}
+
+// writeFile записывает файл и сдвигает время изменения, чтобы перезагрузка не зависела от точности часов ФС
+func writeFile(t *testing.T, path string, data []byte, modTime time.Time) {
+	t.Helper()
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatalf("Не удалось записать %s: %v", path, err)
+	}
+	os.Chtimes(path, modTime, modTime)
+}
+
+// startMTLSExchanger запускает gw-exchanger, который требует клиентский сертификат от clientCA
+func startMTLSExchanger(t *testing.T, serverCA, clientCA *testCA) string {
+	t.Helper()
+	certPEM, keyPEM := serverCA.issue(t, "exchanger.local", true)
+	serverCert, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		t.Fatalf("Ошибка сертификата сервера: %v", err)
+	}
+	clientCAs := x509.NewCertPool()
+	clientCAs.AddCert(clientCA.cert)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Не удалось открыть порт: %v", err)
+	}
+	server := grpclib.NewServer(grpclib.Creds(credentials.NewTLS(&tls.Config{
+		Certificates: []tls.Certificate{serverCert},
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		ClientCAs:    clientCAs,
+	})))
+	exchange.RegisterExchangeServiceServer(server, &scriptedExchanger{})
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+	return listener.Addr().String()
+}
+
+func TestExchangeClientMutualTLS(t *testing.T) {
+	serverCA, clientCA := newTestCA(t, "server-ca"), newTestCA(t, "client-ca")
+	addr := startMTLSExchanger(t, serverCA, clientCA)
+
+	dir := t.TempDir()
+	caFile, certFile, keyFile := filepath.Join(dir, "ca.pem"), filepath.Join(dir, "client.pem"), filepath.Join(dir, "client.key")
+	modTime := time.Now().Add(-time.Minute)
+	writeFile(t, caFile, serverCA.pem, modTime)
+
+	// Клиентский сертификат от CA, которому сервер не доверяет
+	rogueCert, rogueKey := newTestCA(t, "rogue-ca").issue(t, "wallet", false)
+	writeFile(t, certFile, rogueCert, modTime)
+	writeFile(t, keyFile, rogueKey, modTime)
+
+	client := grpc.NewUserServiceClient(&config.ExchangeService{
+		Addr:             addr,
+		Timeout:          time.Second,
+		BreakerThreshold: 100,
+		BreakerCooldown:  time.Second,
+		TLS: config.ExchangeTLSConfig{
+			Enabled:    true,
+			CAFile:     caFile,
+			CertFile:   certFile,
+			KeyFile:    keyFile,
+			ServerName: "exchanger.local",
+		},
+	}, logrus.New())
+
+	if _, err := client.GetExchangeRates(context.Background()); err == nil {
+		t.Fatalf("Сервер не должен принимать сертификат от чужого CA")
+	}
+
+	// Подменяем сертификат на выпущенный доверенным CA — новые соединения используют его без перезапуска
+	validCert, validKey := clientCA.issue(t, "wallet", false)
+	writeFile(t, certFile, validCert, time.Now())
+	writeFile(t, keyFile, validKey, time.Now())
+
+	deadline := time.Now().Add(10 * time.Second)
+	for {
+		_, err := client.GetExchangeRates(context.Background())
+		if err == nil {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Клиент не подхватил обновлённый сертификат: %v", err)
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+
+	t.Logf("✅ mTLS к gw-exchanger работает, сертификаты перечитываются при изменении")
+}
+
+func TestExchangeTLSCredentialsInvalidFiles(t *testing.T) {
+	dir := t.TempDir()
+	caFile := filepath.Join(dir, "ca.pem")
+	writeFile(t, caFile, []byte("not a certificate"), time.Now())
+
+	if _, err := grpc.NewTLSCredentials(&config.ExchangeTLSConfig{Enabled: true, CAFile: caFile}, logrus.New()); err == nil {
+		t.Fatalf("Ожидалась ошибка для некорректного CA")
+	}
+	if _, err := grpc.NewTLSCredentials(&config.ExchangeTLSConfig{Enabled: true, CAFile: filepath.Join(dir, "missing.pem")}, logrus.New()); err == nil {
+		t.Fatalf("Ожидалась ошибка для отсутствующего файла")
+	}
+
+	t.Logf("✅ Некорректные сертификаты обнаруживаются при запуске")
+}
@@ -0,0 +1,50 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/shopspring/decimal"
+
+	config "gw-currency-wallet/internal/config"
+	"gw-currency-wallet/internal/service"
+)
+
+func TestFeeEngineCalculate(t *testing.T) {
+	cfg := &config.FeeConfig{
+		FeeSchedule: config.FeeSchedule{
+			FeeRule: config.FeeRule{SpreadPercent: 0.5},
+			Pairs: map[string]config.FeeRule{
+				"rub_usd": {SpreadPercent: 1, MinFee: 0.1},
+			},
+		},
+		Tiers: map[string]config.FeeSchedule{
+			"vip": {FeeRule: config.FeeRule{SpreadPercent: 0.1, FixedFee: 0.05}},
+		},
+	}
+	engine := service.NewFeeEngine(cfg)
+
+	tests := []struct {
+		name        string
+		tier        string
+		from, to    string
+		gross       string
+		expectedFee string
+	}{
+		{name: "Default rule", tier: "standard", from: "USD", to: "EUR", gross: "100", expectedFee: "0.5"},
+		{name: "Pair rule", tier: "standard", from: "RUB", to: "USD", gross: "100", expectedFee: "1"},
+		{name: "Pair rule - minimum fee", tier: "standard", from: "RUB", to: "USD", gross: "5", expectedFee: "0.1"},
+		{name: "Tier rule with fixed fee", tier: "vip", from: "RUB", to: "USD", gross: "100", expectedFee: "0.15"},
+		{name: "Unknown tier uses default schedule", tier: "gold", from: "USD", to: "EUR", gross: "200", expectedFee: "1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fee := engine.Calculate(tt.tier, tt.from, tt.to, decimal.RequireFromString(tt.gross))
+			if !fee.Equal(decimal.RequireFromString(tt.expectedFee)) {
+				t.Fatalf("Ожидалась комиссия %s, но получили: %s", tt.expectedFee, fee)
+			}
+
+			t.Logf("✅ Тест '%s' прошел успешно", tt.name)
+		})
+	}
+}
@@ -0,0 +1,138 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"gw-currency-wallet/internal/delivery/rest"
+	"gw-currency-wallet/internal/health"
+	"gw-currency-wallet/internal/storage/models"
+)
+
+func setupHealthRouter(checker *health.Checker) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	probes := rest.NewHealthHandler(checker)
+	router.GET("/healthz", probes.Liveness)
+	router.GET("/readyz", probes.Readiness)
+	return router
+}
+
+func getHealth(t *testing.T, router *gin.Engine, path string) (int, models.HealthResponse) {
+	t.Helper()
+	req, _ := http.NewRequest("GET", path, nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	var response models.HealthResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Ошибка при декодировании ответа: %v", err)
+	}
+	return w.Code, response
+}
+
+func TestReadinessAllDependenciesUp(t *testing.T) {
+	checker := health.NewChecker(time.Second)
+	checker.Add("postgres", func(context.Context) error { return nil })
+	checker.Add("redis", func(context.Context) error { return nil })
+	router := setupHealthRouter(checker)
+
+	code, response := getHealth(t, router, "/readyz")
+	if code != http.StatusOK {
+		t.Fatalf("Ожидался статус %d, но получили: %d", http.StatusOK, code)
+	}
+	if response.Status != models.HealthStatusOK || len(response.Checks) != 2 {
+		t.Fatalf("Неожиданный ответ readiness: %+v", response)
+	}
+
+	t.Logf("✅ Readiness проходит, когда все зависимости доступны")
+}
+
+func TestReadinessDependencyDown(t *testing.T) {
+	checker := health.NewChecker(time.Second)
+	checker.Add("postgres", func(context.Context) error { return nil })
+	checker.Add("exchanger", func(context.Context) error { return errors.New("grpc connection is TRANSIENT_FAILURE") })
+	router := setupHealthRouter(checker)
+
+	code, response := getHealth(t, router, "/readyz")
+	if code != http.StatusServiceUnavailable {
+		t.Fatalf("Ожидался статус %d, но получили: %d", http.StatusServiceUnavailable, code)
+	}
+	if response.Status != models.HealthStatusFail {
+		t.Fatalf("Ожидался статус %s, но получили: %s", models.HealthStatusFail, response.Status)
+	}
+	if response.Checks["postgres"].Status != models.HealthStatusOK {
+		t.Fatalf("Доступная зависимость помечена как недоступная: %+v", response.Checks["postgres"])
+	}
+	exchanger := response.Checks["exchanger"]
+	if exchanger.Status != models.HealthStatusFail || exchanger.Error == "" {
+		t.Fatalf("Ожидалась ошибка exchanger, но получили: %+v", exchanger)
+	}
+
+	// Liveness не зависит от внешних сервисов
+	if code, _ := getHealth(t, router, "/healthz"); code != http.StatusOK {
+		t.Fatalf("Ожидался статус liveness %d, но получили: %d", http.StatusOK, code)
+	}
+
+	t.Logf("✅ Readiness показывает статус каждой зависимости")
+}
+
+func TestReadinessInfoDoesNotFail(t *testing.T) {
+	checker := health.NewChecker(time.Second)
+	checker.Add("postgres", func(context.Context) error { return nil })
+	checker.AddInfo("exchanger_breaker", func() string { return "open" })
+	router := setupHealthRouter(checker)
+
+	// Разомкнутая цепь видна в ответе, но сервис остаётся готовым
+	code, response := getHealth(t, router, "/readyz")
+	if code != http.StatusOK || response.Status != models.HealthStatusOK {
+		t.Fatalf("Ожидался статус %d, но получили: %d %+v", http.StatusOK, code, response)
+	}
+	if response.Info["exchanger_breaker"] != "open" {
+		t.Fatalf("Ожидалось состояние цепи в info, но получили: %+v", response.Info)
+	}
+}
+
+func TestReadinessCheckTimeout(t *testing.T) {
+	checker := health.NewChecker(50 * time.Millisecond)
+	checker.Add("redis", func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	router := setupHealthRouter(checker)
+
+	code, response := getHealth(t, router, "/readyz")
+	if code != http.StatusServiceUnavailable || response.Checks["redis"].Status != models.HealthStatusFail {
+		t.Fatalf("Зависшая проверка должна завершаться по таймауту: %d %+v", code, response)
+	}
+
+	t.Logf("✅ Проверки ограничены таймаутом")
+}
+
+func TestReadinessDraining(t *testing.T) {
+	checker := health.NewChecker(time.Second)
+	checker.Add("postgres", func(context.Context) error { return nil })
+	router := setupHealthRouter(checker)
+
+	checker.SetDraining()
+
+	code, response := getHealth(t, router, "/readyz")
+	if code != http.StatusServiceUnavailable {
+		t.Fatalf("Ожидался статус %d, но получили: %d", http.StatusServiceUnavailable, code)
+	}
+	if response.Status != models.HealthStatusDraining {
+		t.Fatalf("Ожидался статус %s, но получили: %s", models.HealthStatusDraining, response.Status)
+	}
+	if code, _ := getHealth(t, router, "/healthz"); code != http.StatusOK {
+		t.Fatalf("Во время остановки liveness должен проходить, но получили: %d", code)
+	}
+
+	t.Logf("✅ Во время остановки сервер не проходит readiness")
+}
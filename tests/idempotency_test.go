@@ -0,0 +1,83 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+
+	"gw-currency-wallet/internal/delivery/middleware"
+	"gw-currency-wallet/internal/storage/models"
+)
+
+func TestIdempotencyMiddleware(t *testing.T) {
+	router, mockCtrl, _, validator, _, _ := SetupTestEnv(t)
+	defer mockCtrl.Finish()
+
+	mr := miniredis.RunT(t)
+	cache := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+
+	// Хендлер считает реальные выполнения операции
+	executions := 0
+	router.POST("/wallet/deposit",
+		func(c *gin.Context) { c.Set("user_id", "11ff6680-c604-4231-9453-6e2fbc2c30dc") },
+		middleware.IdempotencyMiddleware(cache, time.Hour),
+		middleware.ValidationMiddleware[models.WalletTransaction](validator),
+		func(c *gin.Context) {
+			executions++
+			c.JSON(http.StatusOK, gin.H{"execution": executions})
+		},
+	)
+
+	send := func(key string, input models.WalletTransaction) *httptest.ResponseRecorder {
+		reqBody, _ := json.Marshal(input)
+		req, _ := http.NewRequest("POST", "/wallet/deposit", bytes.NewReader(reqBody))
+		req.Header.Set("Content-Type", "application/json")
+		if key != "" {
+			req.Header.Set(middleware.IdempotencyKeyHeader, key)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		t.Logf("HTTP статус: %d, ответ сервера: %s", w.Code, w.Body.String())
+		return w
+	}
+
+	deposit := models.WalletTransaction{Currency: "USD", Amount: 100}
+
+	first := send("key-1", deposit)
+	if first.Code != http.StatusOK {
+		t.Fatalf("Ожидался статус %d, но получили: %d", http.StatusOK, first.Code)
+	}
+
+	// Повтор с тем же ключом и телом возвращает сохранённый ответ без повторного выполнения
+	retry := send("key-1", deposit)
+	if retry.Code != http.StatusOK || retry.Body.String() != first.Body.String() {
+		t.Fatalf("Ожидался повтор ответа %q, но получили: %d %q", first.Body.String(), retry.Code, retry.Body.String())
+	}
+	if retry.Header().Get("Idempotent-Replayed") != "true" {
+		t.Fatalf("Ожидался заголовок Idempotent-Replayed")
+	}
+	if executions != 1 {
+		t.Fatalf("Ожидалось одно выполнение операции, но получили: %d", executions)
+	}
+
+	// Тот же ключ с другим телом отклоняется
+	reused := send("key-1", models.WalletTransaction{Currency: "USD", Amount: 200})
+	if reused.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("Ожидался статус %d, но получили: %d", http.StatusUnprocessableEntity, reused.Code)
+	}
+
+	// Без ключа запросы выполняются как обычно
+	send("", deposit)
+	if executions != 2 {
+		t.Fatalf("Ожидалось два выполнения операции, но получили: %d", executions)
+	}
+
+	t.Logf("✅ Тест Idempotency-Key прошел успешно")
+}
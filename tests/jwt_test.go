@@ -0,0 +1,109 @@
+package tests
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	config "gw-currency-wallet/internal/config"
+	"gw-currency-wallet/internal/utils"
+)
+
+// writeKey сохраняет приватный ключ в PKCS#8 PEM и возвращает путь к файлу
+func writeKey(t *testing.T, name string, key interface{}) string {
+	t.Helper()
+
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatalf("Ошибка кодирования ключа: %v", err)
+	}
+	path := filepath.Join(t.TempDir(), name+".pem")
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0o600); err != nil {
+		t.Fatalf("Ошибка записи ключа: %v", err)
+	}
+	return path
+}
+
+func TestJWTKeyRotation(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("Ошибка генерации RSA-ключа: %v", err)
+	}
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("Ошибка генерации Ed25519-ключа: %v", err)
+	}
+
+	authCfg := config.AuthConfig{
+		TokenTTl:  time.Minute,
+		Issuer:    "gw-currency-wallet",
+		Audience:  "gw-currency-wallet",
+		ActiveKID: "rsa-1",
+		SigningKeys: []config.SigningKey{
+			{KID: "rsa-1", PrivateKeyFile: writeKey(t, "rsa-1", rsaKey)},
+			{KID: "ed-2", PrivateKeyFile: writeKey(t, "ed-2", edKey)},
+		},
+	}
+	userID := uuid.New()
+
+	// Токен подписан RS256 старым ключом
+	oldManager := newJWTManager(t, &config.Config{Auth: authCfg})
+	oldToken := generateToken(t, oldManager, userID.String(), "testuser")
+
+	// После ротации новые токены подписываются EdDSA, а старые по-прежнему принимаются
+	authCfg.ActiveKID = "ed-2"
+	newManager := newJWTManager(t, &config.Config{Auth: authCfg})
+	newToken := generateToken(t, newManager, userID.String(), "testuser")
+
+	for name, token := range map[string]string{"RS256": oldToken, "EdDSA": newToken} {
+		claims, err := newManager.ParseJWT(token)
+		if err != nil {
+			t.Fatalf("Токен %s не прошёл проверку: %v", name, err)
+		}
+		if claims.UserID != userID.String() || claims.Issuer != authCfg.Issuer || claims.NotBefore == nil || claims.IssuedAt == nil {
+			t.Fatalf("Неожиданные claims токена %s: %+v", name, claims)
+		}
+	}
+
+	// JWKS публикует оба ключа
+	jwks := newManager.JWKS()
+	if len(jwks.Keys) != 2 || jwks.Keys[0].KID != "ed-2" || jwks.Keys[0].Crv != "Ed25519" || jwks.Keys[1].Kty != "RSA" {
+		t.Fatalf("Неожиданный JWKS: %+v", jwks)
+	}
+
+	// Токен для другой аудитории отклоняется
+	otherCfg := authCfg
+	otherCfg.Audience = "other-service"
+	if _, err := newJWTManager(t, &config.Config{Auth: otherCfg}).ParseJWT(newToken); err == nil {
+		t.Fatalf("Ожидалась ошибка проверки aud")
+	}
+
+	// Токен, подписанный выведенным из конфигурации ключом, отклоняется
+	authCfg.SigningKeys = authCfg.SigningKeys[1:]
+	if _, err := newJWTManager(t, &config.Config{Auth: authCfg}).ParseJWT(oldToken); err == nil {
+		t.Fatalf("Ожидалась ошибка проверки токена с удалённым ключом")
+	}
+
+	// Активный ключ без приватной части недопустим
+	if _, err := utils.NewJWTManager(&config.Config{Auth: config.AuthConfig{ActiveKID: "missing", SigningKeys: authCfg.SigningKeys}}); err == nil {
+		t.Fatalf("Ожидалась ошибка для неизвестного active_kid")
+	}
+
+	// Без ключей HS256 допускается только явно
+	if _, err := utils.NewJWTManager(&config.Config{Auth: config.AuthConfig{SecretKey: "secret"}}); err == nil {
+		t.Fatalf("Ожидалась ошибка запуска без signing_keys и allow_hs256")
+	}
+	if _, err := utils.NewJWTManager(&config.Config{Auth: config.AuthConfig{SecretKey: "secret", AllowHS256: true}}); err != nil {
+		t.Fatalf("С allow_hs256 ожидался запуск без signing_keys: %v", err)
+	}
+
+	t.Logf("✅ Тест ротации ключей JWT прошел успешно")
+}
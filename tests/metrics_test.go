@@ -0,0 +1,56 @@
+package tests
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/shopspring/decimal"
+
+	"gw-currency-wallet/internal/metrics"
+	"gw-currency-wallet/internal/storage/models"
+)
+
+func TestMetricsEndpoint(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(metrics.HTTPMiddleware())
+	router.GET("/metrics", gin.WrapH(promhttp.Handler()))
+	router.GET("/wallet/:id", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	// Маршрут учитывается по шаблону, а не по конкретному пути
+	for _, path := range []string{"/wallet/1", "/wallet/2", "/missing"} {
+		req, _ := http.NewRequest("GET", path, nil)
+		router.ServeHTTP(httptest.NewRecorder(), req)
+	}
+	metrics.ObserveOperation(models.TransactionDeposit, "usd", decimal.NewFromFloat(12.5))
+	metrics.ObserveCache("exchange_rates", metrics.CacheMiss)
+
+	req, _ := http.NewRequest("GET", "/metrics", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Ожидался статус %d, но получили: %d", http.StatusOK, w.Code)
+	}
+	body, _ := io.ReadAll(w.Body)
+
+	expected := []string{
+		`wallet_http_requests_total{method="GET",route="/wallet/:id",status="200"} 2`,
+		`wallet_http_requests_total{method="GET",route="unmatched",status="404"} 1`,
+		`wallet_http_request_duration_seconds_bucket{method="GET",route="/wallet/:id"`,
+		`wallet_operations_total{currency="USD",operation="deposit"} 1`,
+		`wallet_operation_volume_total{currency="USD",operation="deposit"} 12.5`,
+		`wallet_cache_requests_total{cache="exchange_rates",result="miss"} 1`,
+	}
+	for _, line := range expected {
+		if !strings.Contains(string(body), line) {
+			t.Fatalf("В ответе /metrics нет строки %s", line)
+		}
+	}
+
+	t.Logf("✅ Метрики отдаются в формате Prometheus")
+}
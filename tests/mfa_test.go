@@ -0,0 +1,391 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"encoding/base32"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"github.com/shopspring/decimal"
+	"github.com/sirupsen/logrus"
+
+	config "gw-currency-wallet/internal/config"
+	"gw-currency-wallet/internal/delivery/middleware"
+	"gw-currency-wallet/internal/delivery/rest"
+	"gw-currency-wallet/internal/errs"
+	"gw-currency-wallet/internal/service"
+	"gw-currency-wallet/internal/service/mocks"
+	"gw-currency-wallet/internal/storage"
+	"gw-currency-wallet/internal/storage/models"
+	"gw-currency-wallet/internal/utils"
+)
+
+func TestTOTPCode(t *testing.T) {
+	// Тестовые векторы RFC 6238 для SHA1, последние 6 цифр
+	secret := base32.StdEncoding.EncodeToString([]byte("12345678901234567890"))
+	vectors := map[int64]string{
+		59:         "287082",
+		1111111109: "081804",
+		1234567890: "005924",
+		2000000000: "279037",
+	}
+	for unix, want := range vectors {
+		got, err := utils.TOTPCode(secret, utils.TOTPStep(time.Unix(unix, 0)))
+		if err != nil {
+			t.Fatalf("Ошибка расчёта кода: %v", err)
+		}
+		if got != want {
+			t.Fatalf("Для времени %d ожидался код %s, но получили: %s", unix, want, got)
+		}
+	}
+}
+
+func TestVerifyTOTP(t *testing.T) {
+	secret, err := utils.GenerateTOTPSecret()
+	if err != nil {
+		t.Fatalf("Не удалось создать секрет: %v", err)
+	}
+	now := time.Now()
+	step := utils.TOTPStep(now)
+
+	// Код соседнего интервала принимается из-за расхождения часов
+	previous, _ := utils.TOTPCode(secret, step-1)
+	if got, ok := utils.VerifyTOTP(secret, previous, now); !ok || got != step-1 {
+		t.Fatalf("Ожидалось, что код предыдущего интервала будет принят")
+	}
+
+	stale, _ := utils.TOTPCode(secret, step-3)
+	if _, ok := utils.VerifyTOTP(secret, stale, now); ok {
+		t.Fatal("Устаревший код не должен приниматься")
+	}
+	if _, ok := utils.VerifyTOTP(secret, "12345", now); ok {
+		t.Fatal("Код неверной длины не должен приниматься")
+	}
+}
+
+func TestTOTPURI(t *testing.T) {
+	uri, err := url.Parse(utils.TOTPURI("gw-currency-wallet", "alice", "JBSWY3DPEHPK3PXP"))
+	if err != nil {
+		t.Fatalf("Некорректная ссылка: %v", err)
+	}
+	if uri.Scheme != "otpauth" || uri.Host != "totp" || uri.Path != "/gw-currency-wallet:alice" {
+		t.Fatalf("Неожиданная ссылка: %s", uri)
+	}
+	if uri.Query().Get("secret") != "JBSWY3DPEHPK3PXP" || uri.Query().Get("issuer") != "gw-currency-wallet" {
+		t.Fatalf("Неожиданные параметры ссылки: %s", uri.RawQuery)
+	}
+}
+
+func TestSecretCipher(t *testing.T) {
+	cipher := utils.NewSecretCipher("key")
+
+	encrypted, err := cipher.Encrypt("JBSWY3DPEHPK3PXP")
+	if err != nil {
+		t.Fatalf("Ошибка шифрования: %v", err)
+	}
+	decrypted, err := cipher.Decrypt(encrypted)
+	if err != nil || decrypted != "JBSWY3DPEHPK3PXP" {
+		t.Fatalf("Ожидался исходный секрет, но получили: %q, %v", decrypted, err)
+	}
+
+	// Другим ключом не расшифровывается
+	if _, err := utils.NewSecretCipher("other").Decrypt(encrypted); err == nil {
+		t.Fatal("Секрет не должен расшифровываться другим ключом")
+	}
+}
+
+func TestLoginWithMFA(t *testing.T) {
+	router, mockCtrl, mockSvc, validator, handler, _ := SetupTestEnv(t)
+	defer mockCtrl.Finish()
+	mockAuthService := mockSvc.AuthService.(*mocks.MockAuthService)
+
+	router.POST("/auth/login", middleware.ValidationMiddleware[models.UserLogin](validator), handler.Login)
+	router.POST("/auth/login/mfa", middleware.ValidationMiddleware[models.MFALoginRequest](validator), handler.LoginMFA)
+
+	send := func(path string, body interface{}) *httptest.ResponseRecorder {
+		reqBody, _ := json.Marshal(body)
+		req, _ := http.NewRequest("POST", path, bytes.NewReader(reqBody))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	// Первый шаг: вместо токенов приходит mfa_token
+	mockAuthService.EXPECT().Login(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(models.LoginSuccessResponse{MFARequired: true, MFAToken: "challenge"}, nil)
+
+	w := send("/auth/login", models.UserLogin{Username: "username", Password: "password123"})
+	var body map[string]interface{}
+	json.Unmarshal(w.Body.Bytes(), &body)
+	if w.Code != http.StatusOK || body["mfa_required"] != true || body["mfa_token"] != "challenge" {
+		t.Fatalf("Ожидался mfa_token, но получили: %d %s", w.Code, w.Body.String())
+	}
+	if _, ok := body["token"]; ok {
+		t.Fatalf("Access-токен не должен выдаваться до второго шага: %s", w.Body.String())
+	}
+
+	// Второй шаг: неверный код и успешный вход
+	gomock.InOrder(
+		mockAuthService.EXPECT().
+			LoginMFA(gomock.Any(), &models.MFALoginRequest{MFAToken: "challenge", Code: "000000"}, gomock.Any()).
+			Return(models.LoginSuccessResponse{}, errs.ErrInvalidMFACode),
+		mockAuthService.EXPECT().
+			LoginMFA(gomock.Any(), &models.MFALoginRequest{MFAToken: "challenge", Code: "123456"}, gomock.Any()).
+			Return(models.LoginSuccessResponse{Token: "valid-token", RefreshToken: "refresh-token", ExpiresIn: 900}, nil),
+	)
+
+	w = send("/auth/login/mfa", models.MFALoginRequest{MFAToken: "challenge", Code: "000000"})
+	var errorResponse middleware.ValidationErrorResponse
+	json.Unmarshal(w.Body.Bytes(), &errorResponse)
+	if w.Code != http.StatusUnauthorized || errorResponse.Error.Message != "Invalid two-factor code" {
+		t.Fatalf("Ожидалась ошибка неверного кода, но получили: %d %s", w.Code, w.Body.String())
+	}
+
+	w = send("/auth/login/mfa", models.MFALoginRequest{MFAToken: "challenge", Code: "123456"})
+	var tokens models.LoginSuccessResponse
+	json.Unmarshal(w.Body.Bytes(), &tokens)
+	if w.Code != http.StatusOK || tokens.Token != "valid-token" {
+		t.Fatalf("Ожидались токены, но получили: %d %s", w.Code, w.Body.String())
+	}
+
+	// Без кода запрос не доходит до сервиса
+	if w := send("/auth/login/mfa", models.MFALoginRequest{MFAToken: "challenge"}); w.Code != http.StatusBadRequest {
+		t.Fatalf("Ожидался статус %d, но получили: %d", http.StatusBadRequest, w.Code)
+	}
+}
+
+func TestMFAEnrollment(t *testing.T) {
+	router, mockCtrl, mockSvc, validator, handler, cfg := SetupTestEnv(t)
+	defer mockCtrl.Finish()
+	mockAuthService := mockSvc.AuthService.(*mocks.MockAuthService)
+
+	jwtManager := newJWTManager(t, cfg)
+	allowTokens(mockSvc)
+	mfa := router.Group("/auth/mfa", middleware.AuthMiddleware(jwtManager, mockSvc))
+	mfa.POST("/enroll", handler.EnrollMFA)
+	mfa.POST("/confirm", middleware.ValidationMiddleware[models.MFACodeRequest](validator), handler.ConfirmMFA)
+	mfa.POST("/disable", middleware.ValidationMiddleware[models.MFACodeRequest](validator), handler.DisableMFA)
+
+	userID := uuid.MustParse("11ff6680-c604-4231-9453-6e2fbc2c30dc")
+	token := generateToken(t, jwtManager, userID.String(), "testuser")
+	send := func(path string, body interface{}) *httptest.ResponseRecorder {
+		var reqBody bytes.Buffer
+		if body != nil {
+			json.NewEncoder(&reqBody).Encode(body)
+		}
+		req, _ := http.NewRequest("POST", path, &reqBody)
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	mockAuthService.EXPECT().EnrollMFA(gomock.Any(), userID).
+		Return(models.MFAEnrollResponse{Secret: "JBSWY3DPEHPK3PXP", OTPAuthURI: "otpauth://totp/x"}, nil)
+	w := send("/auth/mfa/enroll", nil)
+	var enrollment models.MFAEnrollResponse
+	json.Unmarshal(w.Body.Bytes(), &enrollment)
+	if w.Code != http.StatusOK || enrollment.Secret != "JBSWY3DPEHPK3PXP" {
+		t.Fatalf("Ожидался секрет, но получили: %d %s", w.Code, w.Body.String())
+	}
+
+	mockAuthService.EXPECT().ConfirmMFA(gomock.Any(), userID, "123456").
+		Return(models.MFARecoveryCodesResponse{RecoveryCodes: []string{"aaaa-bbbb-cccc-dddd"}}, nil)
+	w = send("/auth/mfa/confirm", models.MFACodeRequest{Code: "123456"})
+	var codes models.MFARecoveryCodesResponse
+	json.Unmarshal(w.Body.Bytes(), &codes)
+	if w.Code != http.StatusOK || len(codes.RecoveryCodes) != 1 {
+		t.Fatalf("Ожидались коды восстановления, но получили: %d %s", w.Code, w.Body.String())
+	}
+
+	// Повторное подключение
+	mockAuthService.EXPECT().EnrollMFA(gomock.Any(), userID).Return(models.MFAEnrollResponse{}, errs.ErrMFAAlreadyEnabled)
+	if w := send("/auth/mfa/enroll", nil); w.Code != http.StatusConflict {
+		t.Fatalf("Ожидался статус %d, но получили: %d", http.StatusConflict, w.Code)
+	}
+
+	mockAuthService.EXPECT().DisableMFA(gomock.Any(), userID, "aaaa-bbbb-cccc-dddd").Return(nil)
+	if w := send("/auth/mfa/disable", models.MFACodeRequest{Code: "aaaa-bbbb-cccc-dddd"}); w.Code != http.StatusOK {
+		t.Fatalf("Ожидался статус %d, но получили: %d %s", http.StatusOK, w.Code, w.Body.String())
+	}
+}
+
+func TestWithdrawRequiresMFACode(t *testing.T) {
+	router, mockCtrl, mockSvc, validator, handler, cfg := SetupTestEnv(t)
+	defer mockCtrl.Finish()
+	mockAuthService := mockSvc.AuthService.(*mocks.MockAuthService)
+
+	jwtManager := newJWTManager(t, cfg)
+	allowTokens(mockSvc)
+	router.POST("/wallet/withdraw",
+		middleware.AuthMiddleware(jwtManager, mockSvc),
+		middleware.ValidationMiddleware[models.WalletTransaction](validator),
+		handler.Withdraw,
+	)
+
+	userID := uuid.MustParse("11ff6680-c604-4231-9453-6e2fbc2c30dc")
+	token := generateToken(t, jwtManager, userID.String(), "testuser")
+	send := func(code string) *httptest.ResponseRecorder {
+		reqBody, _ := json.Marshal(models.WalletTransaction{Currency: "USD", Amount: 5000})
+		req, _ := http.NewRequest("POST", "/wallet/withdraw", bytes.NewReader(reqBody))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+token)
+		if code != "" {
+			req.Header.Set(rest.MFACodeHeader, code)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	// Без кода списание не выполняется
+	mockAuthService.EXPECT().VerifyWithdrawal(gomock.Any(), userID, "USD", gomock.Any(), "").
+		Return(errs.ErrMFARequired)
+	w := send("")
+	var errorResponse middleware.ValidationErrorResponse
+	json.Unmarshal(w.Body.Bytes(), &errorResponse)
+	if w.Code != http.StatusForbidden || errorResponse.Error.Message != "Two-factor code required" {
+		t.Fatalf("Ожидалось требование кода 2FA, но получили: %d %s", w.Code, w.Body.String())
+	}
+
+	mockAuthService.EXPECT().VerifyWithdrawal(gomock.Any(), userID, "USD", gomock.Any(), "123456").
+		Return(nil)
+	mockSvc.WalletService.(*mocks.MockWalletService).EXPECT().
+		Withdraw(gomock.Any(), userID, "USD", gomock.Any()).
+		Return(models.WalletResponse{"USD": decimal.NewFromInt(100)}, nil)
+	if w := send("123456"); w.Code != http.StatusOK {
+		t.Fatalf("Ожидался статус %d, но получили: %d %s", http.StatusOK, w.Code, w.Body.String())
+	}
+}
+
+// mfaStub 2FA пользователя без обращения к базе
+type mfaStub struct {
+	storage.AuthStorage
+	secret   string
+	lastStep int64
+}
+
+func (s *mfaStub) GetMFA(context.Context, uuid.UUID) (models.UserMFA, error) {
+	enabledAt := time.Now()
+	return models.UserMFA{Secret: &s.secret, EnabledAt: &enabledAt}, nil
+}
+
+func (s *mfaStub) UseTOTPStep(_ context.Context, _ uuid.UUID, step int64) (bool, error) {
+	if step <= s.lastStep {
+		return false, nil
+	}
+	s.lastStep = step
+	return true, nil
+}
+
+func TestVerifyWithdrawalLocksAfterInvalidCodes(t *testing.T) {
+	authCfg := &config.AuthConfig{
+		Lockout: config.LockoutConfig{FailureWindow: time.Minute},
+		MFA: config.MFAConfig{
+			EncryptionKey:      "test-key",
+			MaxAttempts:        3,
+			WithdrawThresholds: map[string]float64{"USD": 1000},
+		},
+	}
+	const secret = "JBSWY3DPEHPK3PXP"
+	encrypted, err := utils.NewSecretCipher(authCfg.MFA.EncryptionKey).Encrypt(secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mr := miniredis.RunT(t)
+	cache := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel)
+	stor := &storage.Storage{AuthStorage: &mfaStub{secret: encrypted}}
+	svc := service.NewAuthService(stor, logger, nil, cache, authCfg, nil)
+
+	ctx := context.Background()
+	userID := uuid.New()
+	amount := decimal.NewFromInt(5000)
+	valid, _ := utils.TOTPCode(secret, utils.TOTPStep(time.Now()))
+	invalid := fmt.Sprintf("%06d", (mustAtoi(t, valid)+500000)%1000000)
+
+	// Неверный код, затем верный: счётчик сбрасывается
+	if err := svc.VerifyWithdrawal(ctx, userID, "USD", amount, invalid); !errors.Is(err, errs.ErrInvalidMFACode) {
+		t.Fatalf("Ожидалась ошибка неверного кода, но получили: %v", err)
+	}
+	if err := svc.VerifyWithdrawal(ctx, userID, "USD", amount, valid); err != nil {
+		t.Fatalf("Верный код должен приниматься: %v", err)
+	}
+
+	for i := 1; i < authCfg.MFA.MaxAttempts; i++ {
+		if err := svc.VerifyWithdrawal(ctx, userID, "USD", amount, invalid); !errors.Is(err, errs.ErrInvalidMFACode) {
+			t.Fatalf("Попытка %d: ожидалась ошибка неверного кода, но получили: %v", i, err)
+		}
+	}
+	var locked *errs.MFALockedError
+	if err := svc.VerifyWithdrawal(ctx, userID, "USD", amount, invalid); !errors.As(err, &locked) {
+		t.Fatalf("После max_attempts ожидалась блокировка, но получили: %v", err)
+	}
+
+	// Во время блокировки код не проверяется даже верный
+	next, _ := utils.TOTPCode(secret, utils.TOTPStep(time.Now())+1)
+	if err := svc.VerifyWithdrawal(ctx, userID, "USD", amount, next); !errors.Is(err, errs.ErrMFALocked) {
+		t.Fatalf("Ожидалась блокировка, но получили: %v", err)
+	}
+
+	// Блокировка снимается по истечении окна
+	mr.FastForward(time.Minute)
+	if err := svc.VerifyWithdrawal(ctx, userID, "USD", amount, next); err != nil {
+		t.Fatalf("После окна блокировки верный код должен приниматься: %v", err)
+	}
+}
+
+func mustAtoi(t *testing.T, s string) int {
+	t.Helper()
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return n
+}
+
+func TestTransferRequiresMFACode(t *testing.T) {
+	router, mockCtrl, mockSvc, validator, handler, cfg := SetupTestEnv(t)
+	defer mockCtrl.Finish()
+
+	jwtManager := newJWTManager(t, cfg)
+	allowTokens(mockSvc)
+	router.POST("/wallet/transfer",
+		middleware.AuthMiddleware(jwtManager, mockSvc),
+		middleware.ValidationMiddleware[models.TransferRequest](validator),
+		handler.Transfer,
+	)
+
+	userID := uuid.MustParse("11ff6680-c604-4231-9453-6e2fbc2c30dc")
+	mockSvc.AuthService.(*mocks.MockAuthService).EXPECT().
+		VerifyWithdrawal(gomock.Any(), userID, "USD", gomock.Any(), "").
+		Return(errs.ErrMFARequired)
+
+	// Перевод уводит средства так же, как вывод: без кода сервис кошелька не вызывается
+	reqBody, _ := json.Marshal(models.TransferRequest{Recipient: "friend", Currency: "USD", Amount: 5000})
+	req, _ := http.NewRequest("POST", "/wallet/transfer", bytes.NewReader(reqBody))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+generateToken(t, jwtManager, userID.String(), "testuser"))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusForbidden {
+		t.Fatalf("Ожидался статус %d, но получили: %d %s", http.StatusForbidden, w.Code, w.Body.String())
+	}
+}
@@ -0,0 +1,170 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/IBM/sarama"
+	"github.com/IBM/sarama/mocks"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+
+	config "gw-currency-wallet/internal/config"
+	"gw-currency-wallet/internal/infrastructure/kafka"
+	"gw-currency-wallet/internal/outbox"
+	"gw-currency-wallet/internal/storage/models"
+)
+
+// fakeOutbox хранилище outbox в памяти: отдаёт неопубликованные события по порядку
+type fakeOutbox struct {
+	events    []models.OutboxEvent
+	published map[int64]bool
+	attempts  map[int64]int
+}
+
+func newFakeOutbox(events ...models.OutboxEvent) *fakeOutbox {
+	return &fakeOutbox{events: events, published: make(map[int64]bool), attempts: make(map[int64]int)}
+}
+
+func (f *fakeOutbox) ProcessOutbox(_ context.Context, limit int, publish func([]models.OutboxEvent) models.OutboxResult) (int, error) {
+	var batch []models.OutboxEvent
+	for _, e := range f.events {
+		if !f.published[e.ID] && len(batch) < limit {
+			batch = append(batch, e)
+		}
+	}
+	if len(batch) == 0 {
+		return 0, nil
+	}
+
+	result := publish(batch)
+	for _, id := range result.Published {
+		f.published[id] = true
+	}
+	for id := range result.Failed {
+		f.attempts[id]++
+	}
+	return len(result.Published), nil
+}
+
+func newOutboxEvent(id int64, eventType string, key uuid.UUID) models.OutboxEvent {
+	return models.OutboxEvent{
+		ID:          id,
+		EventID:     uuid.New(),
+		Type:        eventType,
+		AggregateID: key,
+		Payload:     json.RawMessage(`{"user_id":"` + key.String() + `"}`),
+		CreatedAt:   time.Now(),
+	}
+}
+
+func TestOutboxRelay(t *testing.T) {
+	cfg := &config.OutboxConfig{Topic: "wallet-events", PollInterval: time.Second, BatchSize: 10, MaxRetries: 5, MaxBackoff: time.Minute}
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel)
+
+	t.Run("Message key and envelope", func(t *testing.T) {
+		userID := uuid.New()
+		event := newOutboxEvent(1, models.EventWalletDeposited, userID)
+		store := newFakeOutbox(event)
+
+		producer := mocks.NewSyncProducer(t, kafka.NewProducerConfig(cfg))
+		producer.ExpectSendMessageWithMessageCheckerFunctionAndSucceed(func(msg *sarama.ProducerMessage) error {
+			if msg.Topic != cfg.Topic {
+				return errors.New("unexpected topic " + msg.Topic)
+			}
+			key, _ := msg.Key.Encode()
+			if string(key) != userID.String() {
+				return errors.New("message key must be user id")
+			}
+			value, _ := msg.Value.Encode()
+			var envelope map[string]interface{}
+			if err := json.Unmarshal(value, &envelope); err != nil {
+				return err
+			}
+			if envelope["event_type"] != models.EventWalletDeposited || envelope["event_id"] != event.EventID.String() {
+				return errors.New("unexpected envelope " + string(value))
+			}
+			if _, ok := envelope["data"].(map[string]interface{}); !ok {
+				return errors.New("envelope without data")
+			}
+			return nil
+		})
+		defer producer.Close()
+
+		relay := outbox.NewRelay(store, kafka.NewPublisher(producer, cfg.Topic), cfg, logger)
+		result, err := relay.Flush(context.Background())
+		if err != nil {
+			t.Fatalf("Не ожидалась ошибка: %v", err)
+		}
+		if len(result.Published) != 1 || !store.published[1] {
+			t.Fatalf("Событие должно быть опубликовано, результат: %+v", result)
+		}
+
+		t.Log("✅ Событие опубликовано с ключом пользователя")
+	})
+
+	t.Run("Failure keeps order per key and is retried", func(t *testing.T) {
+		userA, userB := uuid.New(), uuid.New()
+		store := newFakeOutbox(
+			newOutboxEvent(1, models.EventUserRegistered, userA),
+			newOutboxEvent(2, models.EventUserRegistered, userB),
+			newOutboxEvent(3, models.EventWalletDeposited, userA),
+			newOutboxEvent(4, models.EventWalletDeposited, userB),
+		)
+
+		producer := mocks.NewSyncProducer(t, kafka.NewProducerConfig(cfg))
+		defer producer.Close()
+		relay := outbox.NewRelay(store, kafka.NewPublisher(producer, cfg.Topic), cfg, logger)
+
+		// Первый проход: событие 1 не отправлено, событие 3 того же пользователя пропускается
+		producer.ExpectSendMessageAndFail(sarama.ErrNotEnoughReplicas)
+		producer.ExpectSendMessageAndSucceed()
+		producer.ExpectSendMessageAndSucceed()
+
+		result, err := relay.Flush(context.Background())
+		if err != nil {
+			t.Fatalf("Не ожидалась ошибка: %v", err)
+		}
+		if _, failed := result.Failed[1]; !failed || len(result.Failed) != 1 {
+			t.Fatalf("Ожидалась ошибка только для события 1, получили: %+v", result.Failed)
+		}
+		if store.published[3] {
+			t.Fatal("Событие 3 не должно обгонять неотправленное событие 1")
+		}
+		if !store.published[2] || !store.published[4] {
+			t.Fatal("События другого пользователя должны быть опубликованы")
+		}
+
+		// Второй проход: события пользователя A уходят по порядку
+		producer.ExpectSendMessageWithMessageCheckerFunctionAndSucceed(expectEventType(models.EventUserRegistered))
+		producer.ExpectSendMessageWithMessageCheckerFunctionAndSucceed(expectEventType(models.EventWalletDeposited))
+
+		result, err = relay.Flush(context.Background())
+		if err != nil {
+			t.Fatalf("Не ожидалась ошибка: %v", err)
+		}
+		if len(result.Published) != 2 || !store.published[1] || !store.published[3] {
+			t.Fatalf("Ожидалась публикация событий 1 и 3, результат: %+v", result)
+		}
+		if store.attempts[1] != 1 {
+			t.Fatalf("Ожидалась одна неудачная попытка для события 1, получили: %d", store.attempts[1])
+		}
+
+		t.Log("✅ Порядок событий одного пользователя сохранён")
+	})
+}
+
+func expectEventType(eventType string) mocks.MessageChecker {
+	return func(msg *sarama.ProducerMessage) error {
+		for _, h := range msg.Headers {
+			if string(h.Key) == "event_type" && string(h.Value) == eventType {
+				return nil
+			}
+		}
+		return errors.New("expected event " + eventType)
+	}
+}
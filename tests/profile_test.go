@@ -0,0 +1,242 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+
+	config "gw-currency-wallet/internal/config"
+	"gw-currency-wallet/internal/delivery/middleware"
+	"gw-currency-wallet/internal/errs"
+	"gw-currency-wallet/internal/infrastructure/mail"
+	"gw-currency-wallet/internal/service"
+	"gw-currency-wallet/internal/service/mocks"
+	"gw-currency-wallet/internal/storage"
+	"gw-currency-wallet/internal/storage/models"
+	"gw-currency-wallet/internal/utils"
+)
+
+func TestProfile(t *testing.T) {
+	router, mockCtrl, mockSvc, validator, handler, cfg := SetupTestEnv(t)
+	defer mockCtrl.Finish()
+	mockAuthService := mockSvc.AuthService.(*mocks.MockAuthService)
+
+	jwtManager := newJWTManager(t, cfg)
+	allowTokens(mockSvc)
+	users := router.Group("/users/me", middleware.AuthMiddleware(jwtManager, mockSvc))
+	users.GET("", handler.GetProfile)
+	users.PATCH("", middleware.ValidationMiddleware[models.UpdateProfileRequest](validator), handler.UpdateProfile)
+
+	userID := uuid.MustParse("11ff6680-c604-4231-9453-6e2fbc2c30dc")
+	token := generateToken(t, jwtManager, userID.String(), "testuser")
+	send := func(method string, body interface{}) *httptest.ResponseRecorder {
+		var reqBody bytes.Buffer
+		if body != nil {
+			json.NewEncoder(&reqBody).Encode(body)
+		}
+		req, _ := http.NewRequest(method, "/users/me", &reqBody)
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	profile := models.UserProfile{ID: userID, Username: "testuser", Email: "old@example.com", EmailVerified: true, Role: models.RoleUser}
+	mockAuthService.EXPECT().GetProfile(gomock.Any(), userID).Return(profile, nil)
+	w := send("GET", nil)
+	var body map[string]interface{}
+	json.Unmarshal(w.Body.Bytes(), &body)
+	if w.Code != http.StatusOK || body["email"] != "old@example.com" || body["email_verified"] != true {
+		t.Fatalf("Ожидался профиль, но получили: %d %s", w.Code, w.Body.String())
+	}
+	if _, ok := body["password_hash"]; ok {
+		t.Fatalf("Хэш пароля не должен попадать в ответ: %s", w.Body.String())
+	}
+
+	// Смена email снимает подтверждение
+	email := "new@example.com"
+	update := models.UpdateProfileRequest{Email: &email, CurrentPassword: "password123"}
+	updated := profile
+	updated.Email, updated.EmailVerified = email, false
+	gomock.InOrder(
+		mockAuthService.EXPECT().UpdateProfile(gomock.Any(), userID, update).Return(updated, nil),
+		mockAuthService.EXPECT().UpdateProfile(gomock.Any(), userID, gomock.Any()).Return(models.UserProfile{}, errs.ErrWrongPassword),
+		mockAuthService.EXPECT().UpdateProfile(gomock.Any(), userID, gomock.Any()).Return(models.UserProfile{}, errs.ErrEmailAlreadyUsed),
+	)
+	var result models.UserProfile
+	w = send("PATCH", update)
+	json.Unmarshal(w.Body.Bytes(), &result)
+	if w.Code != http.StatusOK || result.Email != email || result.EmailVerified {
+		t.Fatalf("Ожидался профиль с неподтверждённым email, но получили: %d %s", w.Code, w.Body.String())
+	}
+
+	w = send("PATCH", update)
+	var errorResponse middleware.ValidationErrorResponse
+	json.Unmarshal(w.Body.Bytes(), &errorResponse)
+	if w.Code != http.StatusBadRequest || errorResponse.Error.Fields["current_password"] == "" {
+		t.Fatalf("Ожидалась ошибка текущего пароля, но получили: %d %s", w.Code, w.Body.String())
+	}
+
+	if w := send("PATCH", update); w.Code != http.StatusBadRequest {
+		t.Fatalf("Ожидался статус %d, но получили: %d", http.StatusBadRequest, w.Code)
+	}
+
+	invalid := "not-an-email"
+	if w := send("PATCH", models.UpdateProfileRequest{Email: &invalid}); w.Code != http.StatusBadRequest {
+		t.Fatalf("Ожидался статус %d, но получили: %d", http.StatusBadRequest, w.Code)
+	}
+}
+
+func TestChangePassword(t *testing.T) {
+	router, mockCtrl, mockSvc, validator, handler, cfg := SetupTestEnv(t)
+	defer mockCtrl.Finish()
+	mockAuthService := mockSvc.AuthService.(*mocks.MockAuthService)
+
+	jwtManager := newJWTManager(t, cfg)
+	allowTokens(mockSvc)
+	router.POST("/users/me/password",
+		middleware.AuthMiddleware(jwtManager, mockSvc),
+		middleware.ValidationMiddleware[models.ChangePasswordRequest](validator),
+		handler.ChangePassword,
+	)
+
+	userID := uuid.MustParse("11ff6680-c604-4231-9453-6e2fbc2c30dc")
+	token := generateToken(t, jwtManager, userID.String(), "testuser")
+	send := func(body interface{}) *httptest.ResponseRecorder {
+		reqBody, _ := json.Marshal(body)
+		req, _ := http.NewRequest("POST", "/users/me/password", bytes.NewReader(reqBody))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	input := models.ChangePasswordRequest{CurrentPassword: "password123", NewPassword: "newpassword"}
+	gomock.InOrder(
+		mockAuthService.EXPECT().ChangePassword(gomock.Any(), gomock.Any(), input).
+			Do(func(_ context.Context, claims *models.Claims, _ models.ChangePasswordRequest) {
+				// jti нужен сервису, чтобы отозвать текущий access-токен
+				if claims.UserID != userID.String() || claims.ID == "" {
+					t.Errorf("Ожидались claims текущего токена, но получили: %+v", claims)
+				}
+			}).
+			Return(models.LoginSuccessResponse{Token: "new-token", RefreshToken: "new-refresh", ExpiresIn: 900}, nil),
+		mockAuthService.EXPECT().ChangePassword(gomock.Any(), gomock.Any(), gomock.Any()).
+			Return(models.LoginSuccessResponse{}, errs.ErrWrongPassword),
+		mockAuthService.EXPECT().ChangePassword(gomock.Any(), gomock.Any(), gomock.Any()).
+			Return(models.LoginSuccessResponse{}, &errs.LoginLockedError{Until: time.Now().Add(time.Minute)}),
+	)
+
+	// Взамен завершённых сессий выдаются токены новой
+	w := send(input)
+	var tokens models.LoginSuccessResponse
+	json.Unmarshal(w.Body.Bytes(), &tokens)
+	if w.Code != http.StatusOK || tokens.Token != "new-token" || tokens.RefreshToken != "new-refresh" {
+		t.Fatalf("Ожидались токены новой сессии, но получили: %d %s", w.Code, w.Body.String())
+	}
+
+	if w := send(input); w.Code != http.StatusBadRequest {
+		t.Fatalf("Ожидался статус %d, но получили: %d", http.StatusBadRequest, w.Code)
+	}
+	// Перебор текущего пароля блокируется так же, как вход
+	if w := send(input); w.Code != http.StatusTooManyRequests || w.Header().Get("Retry-After") == "" {
+		t.Fatalf("Ожидался статус %d с Retry-After, но получили: %d", http.StatusTooManyRequests, w.Code)
+	}
+
+	if w := send(models.ChangePasswordRequest{CurrentPassword: "password123", NewPassword: "short"}); w.Code != http.StatusBadRequest {
+		t.Fatalf("Ожидался статус %d, но получили: %d", http.StatusBadRequest, w.Code)
+	}
+}
+
+// passwordStub хранилище пользователя для проверки отзыва токенов при смене и сбросе пароля
+type passwordStub struct {
+	storage.AuthStorage
+	user *models.UserOutput
+}
+
+func (s *passwordStub) GetUserByID(_ context.Context, _ uuid.UUID) (*models.UserOutput, error) {
+	return s.user, nil
+}
+
+func (s *passwordStub) ChangePassword(_ context.Context, _ uuid.UUID, _ string, _ models.RefreshToken) error {
+	return nil
+}
+
+func (s *passwordStub) ResetPassword(_ context.Context, _, _ string) (uuid.UUID, error) {
+	return s.user.ID, nil
+}
+
+func TestPasswordChangeRevokesAllAccessTokens(t *testing.T) {
+	cfg := &config.Config{Auth: config.AuthConfig{SecretKey: "secret", TokenTTl: time.Minute, AllowHS256: true}}
+	jwtManager := newJWTManager(t, cfg)
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel)
+
+	passwordHash, err := utils.HashPassword("password123")
+	if err != nil {
+		t.Fatal(err)
+	}
+	user := &models.UserOutput{ID: uuid.New(), Username: "testuser", Email: "test@example.com", PasswordHash: passwordHash}
+	mr := miniredis.RunT(t)
+	cache := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	mailer := mail.NewLogMailer(&config.MailConfig{}, logger)
+	svc := service.NewAuthService(&storage.Storage{AuthStorage: &passwordStub{user: user}}, logger, jwtManager, cache, &cfg.Auth, mailer)
+
+	ctx := context.Background()
+	// Токен другой сессии, выпущенный до смены пароля
+	otherSession := &models.Claims{
+		UserID:           user.ID.String(),
+		RegisteredClaims: jwt.RegisteredClaims{ID: uuid.NewString(), IssuedAt: jwt.NewNumericDate(time.Now().Add(-time.Minute))},
+	}
+	current := &models.Claims{
+		UserID:           user.ID.String(),
+		RegisteredClaims: jwt.RegisteredClaims{ID: uuid.NewString(), ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute))},
+	}
+
+	// Токен, выпущенный непосредственно перед сменой пароля, в ту же секунду
+	justIssued, err := jwtManager.ParseJWT(generateToken(t, jwtManager, user.ID.String(), user.Username))
+	if err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(2 * time.Millisecond)
+
+	tokens, err := svc.ChangePassword(ctx, current, models.ChangePasswordRequest{CurrentPassword: "password123", NewPassword: "newpassword123"})
+	if err != nil {
+		t.Fatalf("Ошибка смены пароля: %v", err)
+	}
+	if revoked, _ := svc.IsTokenRevoked(ctx, otherSession); !revoked {
+		t.Fatal("Токен другой сессии должен быть отозван после смены пароля")
+	}
+	if revoked, _ := svc.IsTokenRevoked(ctx, justIssued); !revoked {
+		t.Fatal("Токен, выпущенный в ту же секунду до смены пароля, должен быть отозван")
+	}
+	issued, err := jwtManager.ParseJWT(tokens.Token)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if revoked, _ := svc.IsTokenRevoked(ctx, issued); revoked {
+		t.Fatal("Новый токен после смены пароля должен действовать")
+	}
+
+	// Сброс пароля отзывает все токены, выпущенные до него
+	mr.FlushAll()
+	if err := svc.ResetPassword(ctx, models.ResetPasswordRequest{Token: "token", Password: "resetpassword123"}); err != nil {
+		t.Fatalf("Ошибка сброса пароля: %v", err)
+	}
+	if revoked, _ := svc.IsTokenRevoked(ctx, otherSession); !revoked {
+		t.Fatal("Токен должен быть отозван после сброса пароля")
+	}
+}
@@ -0,0 +1,136 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+
+	config "gw-currency-wallet/internal/config"
+	"gw-currency-wallet/internal/delivery/middleware"
+)
+
+func setupRateLimit(t *testing.T, rule config.RateLimitRule, key middleware.RateLimitKey) (*gin.Engine, *miniredis.Miniredis) {
+	t.Helper()
+	router, mockCtrl, _, _, _, _ := SetupTestEnv(t)
+	t.Cleanup(mockCtrl.Finish)
+
+	mr := miniredis.RunT(t)
+	cache := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+
+	router.GET("/limited",
+		func(c *gin.Context) {
+			if userID := c.GetHeader("X-Test-User"); userID != "" {
+				c.Set("user_id", userID)
+			}
+		},
+		middleware.RateLimitMiddleware(cache, logrus.New(), "test", rule, key),
+		func(c *gin.Context) { c.Status(http.StatusOK) },
+	)
+	return router, mr
+}
+
+func sendLimited(router *gin.Engine, remoteAddr, userID string) *httptest.ResponseRecorder {
+	req, _ := http.NewRequest("GET", "/limited", nil)
+	req.RemoteAddr = remoteAddr
+	if userID != "" {
+		req.Header.Set("X-Test-User", userID)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestRateLimitByIP(t *testing.T) {
+	router, _ := setupRateLimit(t, config.RateLimitRule{Limit: 3, Window: time.Minute}, middleware.RateLimitByIP)
+
+	for i := 1; i <= 3; i++ {
+		w := sendLimited(router, "10.0.0.1:1234", "")
+		if w.Code != http.StatusOK {
+			t.Fatalf("Запрос %d: ожидался статус %d, но получили: %d", i, http.StatusOK, w.Code)
+		}
+		if limit := w.Header().Get(middleware.RateLimitLimitHeader); limit != "3" {
+			t.Fatalf("Ожидался RateLimit-Limit 3, но получили: %q", limit)
+		}
+		if remaining := w.Header().Get(middleware.RateLimitRemainingHeader); remaining != strconv.Itoa(3-i) {
+			t.Fatalf("Запрос %d: ожидался RateLimit-Remaining %d, но получили: %q", i, 3-i, remaining)
+		}
+	}
+
+	// Лимит исчерпан
+	w := sendLimited(router, "10.0.0.1:5678", "")
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("Ожидался статус %d, но получили: %d", http.StatusTooManyRequests, w.Code)
+	}
+	retryAfter, err := strconv.Atoi(w.Header().Get(middleware.RetryAfterHeader))
+	if err != nil || retryAfter < 1 || retryAfter > 60 {
+		t.Fatalf("Ожидался Retry-After от 1 до 60 секунд, но получили: %q", w.Header().Get(middleware.RetryAfterHeader))
+	}
+	if remaining := w.Header().Get(middleware.RateLimitRemainingHeader); remaining != "0" {
+		t.Fatalf("Ожидался RateLimit-Remaining 0, но получили: %q", remaining)
+	}
+	var response middleware.ValidationErrorResponse
+	json.Unmarshal(w.Body.Bytes(), &response)
+	if response.Error.Code != http.StatusTooManyRequests {
+		t.Fatalf("Ожидалась ошибка с кодом %d, но получили: %s", http.StatusTooManyRequests, w.Body.String())
+	}
+
+	// Другой IP считается отдельно
+	if w := sendLimited(router, "10.0.0.2:1234", ""); w.Code != http.StatusOK {
+		t.Fatalf("Ожидался статус %d для другого IP, но получили: %d", http.StatusOK, w.Code)
+	}
+}
+
+func TestRateLimitByUser(t *testing.T) {
+	router, _ := setupRateLimit(t, config.RateLimitRule{Limit: 1, Window: time.Minute}, middleware.RateLimitByUser)
+
+	// Пользователи с одного IP не мешают друг другу
+	if w := sendLimited(router, "10.0.0.1:1234", "user-1"); w.Code != http.StatusOK {
+		t.Fatalf("Ожидался статус %d, но получили: %d", http.StatusOK, w.Code)
+	}
+	if w := sendLimited(router, "10.0.0.1:1234", "user-2"); w.Code != http.StatusOK {
+		t.Fatalf("Ожидался статус %d для другого пользователя, но получили: %d", http.StatusOK, w.Code)
+	}
+	if w := sendLimited(router, "10.0.0.2:1234", "user-1"); w.Code != http.StatusTooManyRequests {
+		t.Fatalf("Ожидался статус %d, но получили: %d", http.StatusTooManyRequests, w.Code)
+	}
+}
+
+func TestRateLimitSlidingWindow(t *testing.T) {
+	router, _ := setupRateLimit(t, config.RateLimitRule{Limit: 2, Window: 200 * time.Millisecond}, middleware.RateLimitByIP)
+
+	sendLimited(router, "10.0.0.1:1234", "")
+	time.Sleep(120 * time.Millisecond)
+	sendLimited(router, "10.0.0.1:1234", "")
+	if w := sendLimited(router, "10.0.0.1:1234", ""); w.Code != http.StatusTooManyRequests {
+		t.Fatalf("Ожидался статус %d, но получили: %d", http.StatusTooManyRequests, w.Code)
+	}
+
+	// Первый запрос вышел из окна, второй ещё в нём — освободилось одно место
+	time.Sleep(100 * time.Millisecond)
+	if w := sendLimited(router, "10.0.0.1:1234", ""); w.Code != http.StatusOK {
+		t.Fatalf("Ожидался статус %d после сдвига окна, но получили: %d", http.StatusOK, w.Code)
+	}
+	if w := sendLimited(router, "10.0.0.1:1234", ""); w.Code != http.StatusTooManyRequests {
+		t.Fatalf("Ожидался статус %d, но получили: %d", http.StatusTooManyRequests, w.Code)
+	}
+}
+
+func TestRateLimitRedisUnavailable(t *testing.T) {
+	router, mr := setupRateLimit(t, config.RateLimitRule{Limit: 1, Window: time.Minute}, middleware.RateLimitByIP)
+	mr.Close()
+
+	// Без Redis запросы пропускаются
+	for i := 0; i < 3; i++ {
+		if w := sendLimited(router, "10.0.0.1:1234", ""); w.Code != http.StatusOK {
+			t.Fatalf("Ожидался статус %d при недоступном Redis, но получили: %d", http.StatusOK, w.Code)
+		}
+	}
+}
@@ -0,0 +1,295 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	exchange "github.com/AndrewTarev/proto-repo/gen/exchange"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"github.com/shopspring/decimal"
+	"github.com/sirupsen/logrus"
+	grpclib "google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	config "gw-currency-wallet/internal/config"
+	"gw-currency-wallet/internal/errs"
+	"gw-currency-wallet/internal/infrastructure/grpc"
+	"gw-currency-wallet/internal/service"
+	"gw-currency-wallet/internal/storage"
+	"gw-currency-wallet/internal/storage/models"
+)
+
+// fakeExchanger gw-exchanger, который можно «уронить» и сменить курсы во время теста
+type fakeExchanger struct {
+	exchange.UnimplementedExchangeServiceServer
+
+	mu       sync.Mutex
+	rates    map[string]string
+	down     bool
+	requests []string // Запрошенные пары курсов
+}
+
+func (f *fakeExchanger) set(rates map[string]string, down bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.rates, f.down = rates, down
+}
+
+func (f *fakeExchanger) GetExchangeRates(context.Context, *exchange.Empty) (*exchange.ExchangeRatesResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.down {
+		return nil, status.Error(codes.Unavailable, "exchanger is down")
+	}
+	return &exchange.ExchangeRatesResponse{Rates: f.rates}, nil
+}
+
+func (f *fakeExchanger) GetExchangeRateForCurrency(_ context.Context, req *exchange.CurrencyRequest) (*exchange.ExchangeRateResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.down {
+		return nil, status.Error(codes.Unavailable, "exchanger is down")
+	}
+	f.requests = append(f.requests, req.FromCurrency+"/"+req.ToCurrency)
+	return &exchange.ExchangeRateResponse{Rate: f.rates[req.ToCurrency]}, nil
+}
+
+// tierStub отдаёт уровень пользователя без обращения к базе
+type tierStub struct {
+	storage.AuthStorage
+}
+
+func (tierStub) GetUserTier(context.Context, uuid.UUID) (string, error) {
+	return "standard", nil
+}
+
+// currencyStub реестр валют без обращения к базе
+type currencyStub struct {
+	storage.WalletStorage
+}
+
+func (currencyStub) GetCurrency(_ context.Context, code string) (models.Currency, error) {
+	minorUnits := map[string]int32{"USD": 2, "EUR": 2, "RUB": 2, "JPY": 0}
+	units, ok := minorUnits[strings.ToUpper(code)]
+	if !ok {
+		return models.Currency{}, errs.ErrUnsupportedCurrency
+	}
+	return models.Currency{Code: strings.ToUpper(code), MinorUnits: units, Enabled: true}, nil
+}
+
+func setupRatesService(t *testing.T, cfg *config.ExchangeConfig) (*service.Exchange, *fakeExchanger) {
+	t.Helper()
+
+	fake := &fakeExchanger{rates: map[string]string{"USD": "0.013"}}
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Не удалось открыть порт: %v", err)
+	}
+	server := grpclib.NewServer()
+	exchange.RegisterExchangeServiceServer(server, fake)
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	mr := miniredis.RunT(t)
+	cache := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+
+	logger := logrus.New()
+	exClient := grpc.NewUserServiceClient(&config.ExchangeService{
+		Addr:             listener.Addr().String(),
+		Timeout:          time.Second,
+		BreakerThreshold: 100,
+		BreakerCooldown:  time.Second,
+	}, logger)
+	stor := &storage.Storage{AuthStorage: tierStub{}, WalletStorage: currencyStub{}}
+	return service.NewExchangeService(exClient, cache, logger, stor, cfg, nil), fake
+}
+
+func TestRatesServedStaleWhenExchangerDown(t *testing.T) {
+	svc, fake := setupRatesService(t, &config.ExchangeConfig{
+		RatesTTL:       50 * time.Millisecond,
+		RatesRetention: time.Hour,
+		MaxRateAge:     time.Hour,
+	})
+	ctx := context.Background()
+
+	fresh, err := svc.GetRates(ctx)
+	if err != nil || fresh.Stale || fresh.Rates["USD"] != "0.013" {
+		t.Fatalf("Ожидались свежие курсы, но получили: %+v, %v", fresh, err)
+	}
+
+	// gw-exchanger недоступен, срок свежести истёк — отдаём последние известные курсы
+	fake.set(nil, true)
+	time.Sleep(60 * time.Millisecond)
+
+	stale, err := svc.GetRates(ctx)
+	if err != nil {
+		t.Fatalf("Устаревшие курсы должны отдаваться без ошибки: %v", err)
+	}
+	if !stale.Stale || stale.Rates["USD"] != "0.013" || !stale.AsOf.Equal(fresh.AsOf) {
+		t.Fatalf("Ожидались последние известные курсы с stale=true, но получили: %+v", stale)
+	}
+
+	// После восстановления курсы обновляются в фоне
+	fake.set(map[string]string{"USD": "0.012"}, false)
+	svc.GetRates(ctx)
+
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		rates, err := svc.GetRates(ctx)
+		if err == nil && !rates.Stale && rates.Rates["USD"] == "0.012" {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Курсы не обновились в фоне: %+v, %v", rates, err)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	t.Logf("✅ Устаревшие курсы отдаются с stale=true и обновляются в фоне")
+}
+
+func TestExchangeRefusedWhenRateTooOld(t *testing.T) {
+	svc, fake := setupRatesService(t, &config.ExchangeConfig{
+		RatesTTL:       50 * time.Millisecond,
+		RatesRetention: time.Hour,
+		MaxRateAge:     300 * time.Millisecond,
+	})
+	ctx := context.Background()
+	userID := uuid.New()
+	amount := decimal.NewFromInt(1000)
+
+	if _, err := svc.PriceExchange(ctx, userID, "RUB", "USD", amount); err != nil {
+		t.Fatalf("Ошибка расчёта обмена: %v", err)
+	}
+
+	// Курс устарел, но ещё не старше max_rate_age — обмен проходит по последнему известному курсу
+	fake.set(nil, true)
+	time.Sleep(100 * time.Millisecond)
+
+	quote, err := svc.PriceExchange(ctx, userID, "RUB", "USD", amount)
+	if err != nil {
+		t.Fatalf("Обмен по недавнему курсу должен проходить: %v", err)
+	}
+	if !quote.Rate.Equal(decimal.RequireFromString("0.013")) {
+		t.Fatalf("Ожидался курс 0.013, но получили: %s", quote.Rate)
+	}
+
+	// Курс старше max_rate_age — обмен отклоняется
+	time.Sleep(300 * time.Millisecond)
+	if _, err := svc.PriceExchange(ctx, userID, "RUB", "USD", amount); !errors.Is(err, errs.ErrRateTooOld) {
+		t.Fatalf("Ожидалась ошибка %v, но получили: %v", errs.ErrRateTooOld, err)
+	}
+
+	// Курсы для просмотра по-прежнему доступны
+	rate, err := svc.GetRate(ctx, "RUB", "USD")
+	if err != nil || !rate.Stale {
+		t.Fatalf("Ожидался устаревший курс для просмотра, но получили: %+v, %v", rate, err)
+	}
+
+	t.Logf("✅ Обмен по слишком старому курсу отклоняется")
+}
+
+func TestQuoteReleasedAfterFailedExchange(t *testing.T) {
+	svc, _ := setupRatesService(t, &config.ExchangeConfig{
+		RatesTTL:       time.Minute,
+		RatesRetention: time.Hour,
+		QuoteTTL:       time.Minute,
+	})
+	ctx := context.Background()
+	userID := uuid.New()
+
+	quote, err := svc.CreateQuote(ctx, userID, "RUB", "USD", decimal.NewFromInt(1000))
+	if err != nil {
+		t.Fatalf("Ошибка создания котировки: %v", err)
+	}
+	if _, err := svc.UseQuote(ctx, userID, quote.QuoteID, models.ExchangeRequest{}); err != nil {
+		t.Fatalf("Ошибка использования котировки: %v", err)
+	}
+	if _, err := svc.UseQuote(ctx, userID, quote.QuoteID, models.ExchangeRequest{}); !errors.Is(err, errs.ErrQuoteAlreadyUsed) {
+		t.Fatalf("Ожидалась ошибка %v, но получили: %v", errs.ErrQuoteAlreadyUsed, err)
+	}
+
+	// Обмен не состоялся — котировку можно применить снова
+	svc.ReleaseQuote(ctx, userID, quote.QuoteID)
+	if _, err := svc.UseQuote(ctx, userID, quote.QuoteID, models.ExchangeRequest{}); err != nil {
+		t.Fatalf("После отмены котировка должна быть доступна: %v", err)
+	}
+}
+
+func TestPriceExchangeRoundsOnce(t *testing.T) {
+	svc, fake := setupRatesService(t, &config.ExchangeConfig{
+		RatesTTL:       time.Minute,
+		RatesRetention: time.Hour,
+		Fees:           config.FeeConfig{FeeSchedule: config.FeeSchedule{FeeRule: config.FeeRule{FixedFee: 0.006}}},
+	})
+	fake.set(map[string]string{"USD": "0.253"}, false)
+
+	// Обмен 1.012 USD с комиссией 0.006: по отдельности зачисление 1.006 и комиссия округлились бы вверх до 1.02
+	quote, err := svc.PriceExchange(context.Background(), uuid.New(), "RUB", "USD", decimal.NewFromInt(4))
+	if err != nil {
+		t.Fatalf("Ошибка расчёта обмена: %v", err)
+	}
+	if !quote.Fee.Equal(decimal.RequireFromString("0.01")) || !quote.ToAmount.Equal(decimal.RequireFromString("1")) {
+		t.Fatalf("Ожидались зачисление 1.00 и комиссия 0.01, но получили: %s и %s", quote.ToAmount, quote.Fee)
+	}
+	if gross := decimal.RequireFromString("1.012").Round(2); !quote.ToAmount.Add(quote.Fee).Equal(gross) {
+		t.Fatalf("Зачисление и комиссия должны давать %s, но получили: %s", gross, quote.ToAmount.Add(quote.Fee))
+	}
+}
+
+func TestPriceExchangeUsesCanonicalCodes(t *testing.T) {
+	svc, fake := setupRatesService(t, &config.ExchangeConfig{RatesTTL: time.Minute, RatesRetention: time.Hour})
+	ctx := context.Background()
+
+	// Коды из запроса в любом регистре дают одну пару в кэше и в запросе к gw-exchanger
+	for _, pair := range [][2]string{{"rub", "usd"}, {"RUB", "USD"}} {
+		if _, err := svc.PriceExchange(ctx, uuid.New(), pair[0], pair[1], decimal.NewFromInt(100)); err != nil {
+			t.Fatalf("Ошибка расчёта обмена %s/%s: %v", pair[0], pair[1], err)
+		}
+	}
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+	if len(fake.requests) != 1 || fake.requests[0] != "RUB/USD" {
+		t.Fatalf("Ожидался один запрос курса RUB/USD, но получили: %v", fake.requests)
+	}
+}
+
+func TestExchangeAmountRoundedToSourceCurrency(t *testing.T) {
+	svc, fake := setupRatesService(t, &config.ExchangeConfig{
+		RatesTTL:       time.Minute,
+		RatesRetention: time.Hour,
+		QuoteTTL:       time.Minute,
+	})
+	fake.set(map[string]string{"USD": "0.0067"}, false)
+	ctx := context.Background()
+	userID := uuid.New()
+
+	// У иены нет дробной части
+	quote, err := svc.CreateQuote(ctx, userID, "JPY", "USD", decimal.RequireFromString("1500.4"))
+	if err != nil {
+		t.Fatalf("Ошибка создания котировки: %v", err)
+	}
+	if !quote.FromAmount.Equal(decimal.NewFromInt(1500)) {
+		t.Fatalf("Ожидалась сумма 1500 JPY, но получили: %s", quote.FromAmount)
+	}
+
+	// Сумма запроса сравнивается с котировкой с той же точностью
+	if _, err := svc.UseQuote(ctx, userID, quote.QuoteID, models.ExchangeRequest{Amount: 1501}); !errors.Is(err, errs.ErrQuoteMismatch) {
+		t.Fatalf("Ожидалась ошибка %v, но получили: %v", errs.ErrQuoteMismatch, err)
+	}
+	if _, err := svc.UseQuote(ctx, userID, quote.QuoteID, models.ExchangeRequest{FromCurrency: "jpy", Amount: 1500.2}); err != nil {
+		t.Fatalf("Котировка должна совпадать с запросом: %v", err)
+	}
+
+	if _, err := svc.PriceExchange(ctx, userID, "JPY", "USD", decimal.RequireFromString("0.4")); !errors.Is(err, errs.ErrInvalidAmount) {
+		t.Fatalf("Ожидалась ошибка %v, но получили: %v", errs.ErrInvalidAmount, err)
+	}
+}
@@ -0,0 +1,245 @@
+package tests
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"github.com/shopspring/decimal"
+	"github.com/sirupsen/logrus"
+
+	config "gw-currency-wallet/internal/config"
+	"gw-currency-wallet/internal/delivery/rest"
+	"gw-currency-wallet/internal/service/mocks"
+	"gw-currency-wallet/internal/storage/models"
+	"gw-currency-wallet/internal/stream"
+)
+
+type sseEvent struct {
+	name string
+	data string
+}
+
+// readEvents разбирает поток Server-Sent Events, канал закрывается вместе с потоком
+func readEvents(t *testing.T, resp *http.Response) <-chan sseEvent {
+	t.Helper()
+	events := make(chan sseEvent, 16)
+	go func() {
+		defer close(events)
+		scanner := bufio.NewScanner(resp.Body)
+		var event sseEvent
+		for scanner.Scan() {
+			line := scanner.Text()
+			switch {
+			case strings.HasPrefix(line, "event:"):
+				event.name = strings.TrimPrefix(line, "event:")
+			case strings.HasPrefix(line, "data:"):
+				event.data = strings.TrimPrefix(line, "data:")
+			case line == "":
+				events <- event
+				event = sseEvent{}
+			}
+		}
+	}()
+	return events
+}
+
+// nextEvent ждёт следующее событие с именем name, пропуская heartbeat
+func nextEvent(t *testing.T, events <-chan sseEvent, name string) sseEvent {
+	t.Helper()
+	timeout := time.After(2 * time.Second)
+	for {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				t.Fatalf("Поток закрылся до события %s", name)
+			}
+			if event.name == name {
+				return event
+			}
+			if event.name != models.StreamEventHeartbeat {
+				t.Fatalf("Ожидалось событие %s, но получили: %s %s", name, event.name, event.data)
+			}
+		case <-timeout:
+			t.Fatalf("Не дождались события %s", name)
+		}
+	}
+}
+
+// startHub запускает хаб и ждёт, пока он подпишется на каналы Redis
+func startHub(t *testing.T, mr *miniredis.Miniredis, hub *stream.Hub) {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	patterns := mr.PubSubNumPat()
+	go hub.Run(ctx)
+	for deadline := time.Now().Add(2 * time.Second); mr.PubSubNumPat() == patterns; {
+		if time.Now().After(deadline) {
+			t.Fatal("Хаб не подписался на Redis")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestStreamEndpoint(t *testing.T) {
+	_, mockCtrl, mockSvc, _, _, _ := SetupTestEnv(t)
+	defer mockCtrl.Finish()
+	walletSvc := mockSvc.WalletService.(*mocks.MockWalletService)
+	exchangeSvc := mockSvc.ExchangeService.(*mocks.MockExchangeService)
+
+	mr := miniredis.RunT(t)
+	cache := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	logger := logrus.New()
+	cfg := &config.StreamConfig{HeartbeatInterval: 100 * time.Millisecond, RatesInterval: time.Hour, BufferSize: 4}
+	hub := stream.NewHub(cache, walletSvc, exchangeSvc, cfg, logger)
+	startHub(t, mr, hub)
+
+	userID := uuid.New()
+	router := gin.New()
+	router.GET("/stream",
+		func(c *gin.Context) { c.Set("user_id", userID.String()) },
+		rest.NewStreamHandler(mockSvc, hub, cfg, logger).Stream,
+	)
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	gomock.InOrder(
+		walletSvc.EXPECT().GetBalance(gomock.Any(), userID).
+			Return(models.WalletResponse{"USD": decimal.NewFromInt(10)}, nil),
+		walletSvc.EXPECT().GetBalance(gomock.Any(), userID).
+			Return(models.WalletResponse{"USD": decimal.NewFromInt(25)}, nil),
+	)
+	exchangeSvc.EXPECT().GetRates(gomock.Any()).
+		Return(models.ExchangeRates{Rates: map[string]string{"USD": "0.013"}, AsOf: time.Now()}, nil)
+
+	resp, err := http.Get(server.URL + "/stream")
+	if err != nil {
+		t.Fatalf("Не удалось подключиться к потоку: %v", err)
+	}
+	defer resp.Body.Close()
+	if contentType := resp.Header.Get("Content-Type"); contentType != "text/event-stream" {
+		t.Fatalf("Ожидался Content-Type text/event-stream, но получили: %s", contentType)
+	}
+	events := readEvents(t, resp)
+
+	// Сначала текущее состояние
+	var balance models.GetBalanceResponse
+	json.Unmarshal([]byte(nextEvent(t, events, models.StreamEventBalance).data), &balance)
+	if !balance.Balance["USD"].Equal(decimal.NewFromInt(10)) {
+		t.Fatalf("Ожидался баланс 10 USD, но получили: %v", balance.Balance)
+	}
+	nextEvent(t, events, models.StreamEventRates)
+
+	// Изменения от любой реплики приходят через Redis
+	publisher := stream.NewPublisher(cache, logger)
+	publisher.BalanceChanged(context.Background(), userID)
+	json.Unmarshal([]byte(nextEvent(t, events, models.StreamEventBalance).data), &balance)
+	if !balance.Balance["USD"].Equal(decimal.NewFromInt(25)) {
+		t.Fatalf("Ожидался баланс 25 USD, но получили: %v", balance.Balance)
+	}
+
+	publisher.RatesUpdated(context.Background(), models.ExchangeRates{Rates: map[string]string{"USD": "0.012"}, AsOf: time.Now()})
+	var rates models.ExchangeRatesResponse
+	json.Unmarshal([]byte(nextEvent(t, events, models.StreamEventRates).data), &rates)
+	if rates.Rates["USD"] != "0.012" {
+		t.Fatalf("Ожидался курс 0.012, но получили: %v", rates.Rates)
+	}
+
+	// Простаивающее соединение поддерживается heartbeat
+	nextEvent(t, events, models.StreamEventHeartbeat)
+
+	// При остановке сервера поток завершается
+	hub.Close()
+	for {
+		select {
+		case _, ok := <-events:
+			if !ok {
+				return
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatal("Поток не завершился после остановки хаба")
+		}
+	}
+}
+
+func TestStreamHubFanOut(t *testing.T) {
+	_, mockCtrl, mockSvc, _, _, _ := SetupTestEnv(t)
+	defer mockCtrl.Finish()
+	walletSvc := mockSvc.WalletService.(*mocks.MockWalletService)
+
+	mr := miniredis.RunT(t)
+	logger := logrus.New()
+	cfg := &config.StreamConfig{HeartbeatInterval: time.Minute, RatesInterval: time.Hour, BufferSize: 1}
+
+	// Две реплики с общим Redis
+	replicas := make([]*stream.Hub, 2)
+	for i := range replicas {
+		cache := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+		replicas[i] = stream.NewHub(cache, walletSvc, mockSvc.ExchangeService, cfg, logger)
+		startHub(t, mr, replicas[i])
+	}
+
+	connected, idle := uuid.New(), uuid.New()
+	first := replicas[0].Subscribe(connected)
+	second := replicas[1].Subscribe(connected)
+
+	// Баланс читают только реплики с подключениями пользователя
+	walletSvc.EXPECT().GetBalance(gomock.Any(), connected).
+		Return(models.WalletResponse{"RUB": decimal.NewFromInt(100)}, nil).
+		Times(2)
+
+	publisher := stream.NewPublisher(redis.NewClient(&redis.Options{Addr: mr.Addr()}), logger)
+	publisher.BalanceChanged(context.Background(), connected, idle)
+
+	// Первая реплика: событие ждёт в очереди, не читаем его, чтобы заполнить очередь
+	for deadline := time.Now().Add(2 * time.Second); len(first.Events()) == 0; {
+		if time.Now().After(deadline) {
+			t.Fatal("Реплика 0 не получила изменение баланса")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	select {
+	case event := <-second.Events():
+		if event.Name != models.StreamEventBalance {
+			t.Fatalf("Реплика 1: ожидалось событие balance, но получили: %s", event.Name)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Реплика 1 не получила изменение баланса")
+	}
+
+	// Клиент с заполненной очередью отключается, не задерживая рассылку остальным
+	publisher.RatesUpdated(context.Background(), models.ExchangeRates{Rates: map[string]string{"USD": "0.013"}})
+	select {
+	case event := <-second.Events():
+		if event.Name != models.StreamEventRates {
+			t.Fatalf("Реплика 1: ожидалось событие rates, но получили: %s", event.Name)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Реплика 1 не получила курсы")
+	}
+
+	if event := <-first.Events(); event.Name != models.StreamEventBalance {
+		t.Fatalf("Ожидалось событие balance из очереди, но получили: %s", event.Name)
+	}
+	select {
+	case event, ok := <-first.Events():
+		if ok {
+			t.Fatalf("Отстающий клиент должен быть отключён, но получил: %s", event.Name)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Отстающий клиент не был отключён")
+	}
+	replicas[0].Unsubscribe(first)
+	replicas[1].Unsubscribe(second)
+}
@@ -0,0 +1,106 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+
+	config "gw-currency-wallet/internal/config"
+	"gw-currency-wallet/internal/delivery/middleware"
+	"gw-currency-wallet/internal/tracing"
+	"gw-currency-wallet/pkg/logging"
+)
+
+// setupTracing подменяет глобальный TracerProvider на записывающий span в память
+func setupTracing(t *testing.T) *tracetest.SpanRecorder {
+	t.Helper()
+
+	if _, err := tracing.Setup(context.Background(), &config.TracingConfig{}); err != nil {
+		t.Fatalf("Не удалось настроить трассировку: %v", err)
+	}
+
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(provider)
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+	return recorder
+}
+
+func TestTracingMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	recorder := setupTracing(t)
+
+	// Запрос с traceparent продолжает трассу вызывающей стороны
+	const parentTraceID = "4bf92f3577b34da6a3ce929bf0e4736e"
+	var handlerSpan trace.SpanContext
+	router := gin.New()
+	router.ContextWithFallback = true
+	router.Use(middleware.TracingMiddleware())
+	router.Use(middleware.ErrorHandler(logrus.New()))
+	router.GET("/wallet/:id", func(c *gin.Context) {
+		handlerSpan = trace.SpanContextFromContext(c)
+		c.Status(http.StatusOK)
+	})
+
+	req, _ := http.NewRequest("GET", "/wallet/1", nil)
+	req.Header.Set("traceparent", "00-"+parentTraceID+"-00f067aa0ba902b7-01")
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	spans := recorder.Ended()
+	if len(spans) != 1 {
+		t.Fatalf("Ожидался 1 span, но получили: %d", len(spans))
+	}
+	span := spans[0]
+	if span.Name() != "GET /wallet/:id" {
+		t.Fatalf("Ожидалось имя span 'GET /wallet/:id', но получили: %s", span.Name())
+	}
+	if span.SpanKind() != trace.SpanKindServer {
+		t.Fatalf("Ожидался серверный span, но получили: %s", span.SpanKind())
+	}
+	if span.SpanContext().TraceID().String() != parentTraceID {
+		t.Fatalf("Span не продолжил трассу из traceparent: %s", span.SpanContext().TraceID())
+	}
+	if handlerSpan.SpanID() != span.SpanContext().SpanID() {
+		t.Fatalf("Контекст обработчика не содержит span запроса")
+	}
+
+	t.Logf("✅ Middleware начинает серверный span и продолжает входящую трассу")
+}
+
+func TestTraceHook(t *testing.T) {
+	setupTracing(t)
+
+	var buf bytes.Buffer
+	logger := logrus.New()
+	logger.SetOutput(&buf)
+	logger.SetFormatter(&logrus.JSONFormatter{})
+	logger.AddHook(&logging.TraceHook{})
+
+	ctx, span := otel.Tracer("test").Start(context.Background(), "operation")
+	logger.WithContext(ctx).Info("inside span")
+	span.End()
+
+	var entry map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("Не удалось разобрать запись лога: %v", err)
+	}
+	if entry["trace_id"] != span.SpanContext().TraceID().String() {
+		t.Fatalf("Ожидался trace_id %s, но получили: %v", span.SpanContext().TraceID(), entry["trace_id"])
+	}
+	if entry["span_id"] != span.SpanContext().SpanID().String() {
+		t.Fatalf("Ожидался span_id %s, но получили: %v", span.SpanContext().SpanID(), entry["span_id"])
+	}
+
+	t.Logf("✅ trace_id и span_id добавляются в записи логов")
+}
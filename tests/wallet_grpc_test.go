@@ -0,0 +1,173 @@
+package tests
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"github.com/shopspring/decimal"
+	"github.com/sirupsen/logrus"
+	grpclib "google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	config "gw-currency-wallet/internal/config"
+	"gw-currency-wallet/internal/delivery/rpc"
+	"gw-currency-wallet/internal/errs"
+	"gw-currency-wallet/internal/service/mocks"
+	"gw-currency-wallet/internal/storage/models"
+	walletpb "gw-currency-wallet/pkg/api/wallet"
+)
+
+const (
+	billingToken = "billing-secret"
+	reportsToken = "reports-secret"
+	auditToken   = "audit-secret"
+)
+
+func tokenHash(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// setupWalletGRPC поднимает gRPC API кошелька на моках сервисов и miniredis
+func setupWalletGRPC(t *testing.T) (walletpb.WalletServiceClient, *mocks.MockWalletService) {
+	t.Helper()
+
+	_, mockCtrl, mockSvc, validator, _, _ := SetupTestEnv(t)
+	t.Cleanup(mockCtrl.Finish)
+
+	mr := miniredis.RunT(t)
+	cache := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+
+	server, err := rpc.NewServer(&config.WalletGRPCConfig{
+		Clients: []config.GRPCClientConfig{
+			{Name: "billing", TokenHash: tokenHash(billingToken), Methods: []string{"*"}},
+			{Name: "reports", TokenHash: tokenHash(reportsToken), Methods: []string{"GetBalance"}},
+			{Name: "audit", TokenHash: tokenHash(auditToken)},
+		},
+		IdempotencyTTL: time.Hour,
+	}, mockSvc, validator, cache, logrus.New())
+	if err != nil {
+		t.Fatalf("Не удалось создать gRPC-сервер: %v", err)
+	}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Не удалось открыть порт: %v", err)
+	}
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpclib.NewClient(listener.Addr().String(), grpclib.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("Не удалось подключиться: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	return walletpb.NewWalletServiceClient(conn), mockSvc.WalletService.(*mocks.MockWalletService)
+}
+
+func withToken(token string, pairs ...string) context.Context {
+	return metadata.AppendToOutgoingContext(context.Background(), append([]string{"authorization", "Bearer " + token}, pairs...)...)
+}
+
+func TestWalletGRPCAuth(t *testing.T) {
+	client, walletSvc := setupWalletGRPC(t)
+	userID := uuid.New()
+	req := &walletpb.GetBalanceRequest{UserId: userID.String()}
+
+	// Без токена и с неизвестным токеном
+	if _, err := client.GetBalance(context.Background(), req); status.Code(err) != codes.Unauthenticated {
+		t.Fatalf("Ожидалась ошибка Unauthenticated, но получили: %v", err)
+	}
+	if _, err := client.GetBalance(withToken("unknown"), req); status.Code(err) != codes.Unauthenticated {
+		t.Fatalf("Ожидалась ошибка Unauthenticated, но получили: %v", err)
+	}
+
+	// Клиенту reports разрешён только GetBalance
+	walletSvc.EXPECT().GetBalance(gomock.Any(), userID).
+		Return(models.WalletResponse{"USD": decimal.NewFromInt(10)}, nil)
+	resp, err := client.GetBalance(withToken(reportsToken), req)
+	if err != nil {
+		t.Fatalf("Ожидался успех, но получили: %v", err)
+	}
+	if resp.GetBalance()["USD"] != "10" {
+		t.Fatalf("Ожидался баланс 10 USD, но получили: %v", resp.GetBalance())
+	}
+
+	deposit := &walletpb.DepositRequest{UserId: userID.String(), Currency: "USD", Amount: "5"}
+	if _, err := client.Deposit(withToken(reportsToken), deposit); status.Code(err) != codes.PermissionDenied {
+		t.Fatalf("Ожидалась ошибка PermissionDenied, но получили: %v", err)
+	}
+
+	// Клиенту audit без списка методов запрещено всё
+	if _, err := client.GetBalance(withToken(auditToken), req); status.Code(err) != codes.PermissionDenied {
+		t.Fatalf("Ожидалась ошибка PermissionDenied, но получили: %v", err)
+	}
+}
+
+func TestWalletGRPCErrors(t *testing.T) {
+	client, walletSvc := setupWalletGRPC(t)
+	userID := uuid.New()
+	ctx := withToken(billingToken)
+
+	// Невалидные аргументы отклоняются до вызова сервиса
+	invalid := []*walletpb.WithdrawRequest{
+		{UserId: "not-a-uuid", Currency: "USD", Amount: "5"},
+		{UserId: userID.String(), Currency: "US", Amount: "5"},
+		{UserId: userID.String(), Currency: "USD", Amount: "-5"},
+		{UserId: userID.String(), Currency: "USD", Amount: "abc"},
+	}
+	for _, req := range invalid {
+		if _, err := client.Withdraw(ctx, req); status.Code(err) != codes.InvalidArgument {
+			t.Fatalf("Ожидалась ошибка InvalidArgument для %v, но получили: %v", req, err)
+		}
+	}
+
+	walletSvc.EXPECT().Withdraw(gomock.Any(), userID, "USD", decimal.NewFromInt(5)).
+		Return(nil, errs.ErrInsufficientFunds)
+	_, err := client.Withdraw(ctx, &walletpb.WithdrawRequest{UserId: userID.String(), Currency: "USD", Amount: "5"})
+	if status.Code(err) != codes.FailedPrecondition {
+		t.Fatalf("Ожидалась ошибка FailedPrecondition, но получили: %v", err)
+	}
+}
+
+func TestWalletGRPCIdempotency(t *testing.T) {
+	client, walletSvc := setupWalletGRPC(t)
+	userID := uuid.New()
+	req := &walletpb.DepositRequest{UserId: userID.String(), Currency: "USD", Amount: "100.50"}
+
+	// Сервис вызывается один раз, повтор получает сохранённый ответ
+	walletSvc.EXPECT().Deposit(gomock.Any(), userID, "USD", decimal.RequireFromString("100.50")).
+		Return(models.WalletResponse{"USD": decimal.RequireFromString("100.50")}, nil).
+		Times(1)
+
+	ctx := withToken(billingToken, rpc.IdempotencyKeyMetadata, "key-1")
+	first, err := client.Deposit(ctx, req)
+	if err != nil {
+		t.Fatalf("Ожидался успех, но получили: %v", err)
+	}
+	retry, err := client.Deposit(ctx, req)
+	if err != nil {
+		t.Fatalf("Ожидался сохранённый ответ, но получили: %v", err)
+	}
+	if retry.GetBalance()["USD"] != first.GetBalance()["USD"] {
+		t.Fatalf("Ожидался тот же ответ %v, но получили: %v", first.GetBalance(), retry.GetBalance())
+	}
+
+	// Тот же ключ с другим запросом
+	other := &walletpb.DepositRequest{UserId: userID.String(), Currency: "USD", Amount: "1"}
+	if _, err := client.Deposit(ctx, other); status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("Ожидалась ошибка InvalidArgument, но получили: %v", err)
+	}
+}
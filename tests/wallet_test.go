@@ -1,6 +1,7 @@
 package tests
 
 import (
+	"bytes"
 	"encoding/json"
 	"errors"
 	"net/http"
@@ -11,7 +12,9 @@ import (
 	"github.com/google/uuid"
 	"github.com/shopspring/decimal"
 
+	config "gw-currency-wallet/internal/config"
 	"gw-currency-wallet/internal/delivery/middleware"
+	"gw-currency-wallet/internal/errs"
 	"gw-currency-wallet/internal/service/mocks"
 	"gw-currency-wallet/internal/storage/models"
 	"gw-currency-wallet/internal/utils"
@@ -21,9 +24,10 @@ func TestGetBalance(t *testing.T) {
 	router, mockCtrl, mockSvc, _, handler, cfg := SetupTestEnv(t)
 	defer mockCtrl.Finish()
 
-	jwtManager := utils.NewJWTManager(cfg)
+	jwtManager := newJWTManager(t, cfg)
+	allowTokens(mockSvc)
 	// Настроим роутер с middleware
-	router.GET("/wallet/balance", middleware.AuthMiddleware(jwtManager), handler.GetBalance)
+	router.GET("/wallet/balance", middleware.AuthMiddleware(jwtManager, mockSvc), handler.GetBalance)
 
 	tests := []struct {
 		name              string
@@ -38,17 +42,17 @@ func TestGetBalance(t *testing.T) {
 		{
 			name: "Success - Get Balance",
 			mockBalanceResp: models.WalletResponse{
-				BalanceRub: decimal.NewFromFloat(10000.00),
-				BalanceUsd: decimal.NewFromFloat(150.00),
-				BalanceEur: decimal.NewFromFloat(200.00),
+				"RUB": decimal.NewFromFloat(10000.00),
+				"USD": decimal.NewFromFloat(150.00),
+				"EUR": decimal.NewFromFloat(200.00),
 			},
 			mockServiceErr:  nil,
 			expectedStatus:  http.StatusOK,
 			expectedMessage: "",
 			expectedBalance: models.WalletResponse{
-				BalanceRub: decimal.NewFromFloat(10000.00),
-				BalanceUsd: decimal.NewFromFloat(150.00),
-				BalanceEur: decimal.NewFromFloat(200.00),
+				"RUB": decimal.NewFromFloat(10000.00),
+				"USD": decimal.NewFromFloat(150.00),
+				"EUR": decimal.NewFromFloat(200.00),
 			},
 			token:             generateToken(t, jwtManager, "11ff6680-c604-4231-9453-6e2fbc2c30dc", "testuser"),
 			expectServiceCall: true,
@@ -102,17 +106,17 @@ func TestGetBalance(t *testing.T) {
 				}
 
 				// Проверяем баланс
-				if !successResponse.Balance.BalanceRub.Equal(tt.expectedBalance.BalanceRub) {
+				if !successResponse.Balance["RUB"].Equal(tt.expectedBalance["RUB"]) {
 					t.Fatalf("Ожидался баланс RUB %s, но получили: %s",
-						tt.expectedBalance.BalanceRub.String(), successResponse.Balance.BalanceRub.String())
+						tt.expectedBalance["RUB"].String(), successResponse.Balance["RUB"].String())
 				}
-				if !successResponse.Balance.BalanceUsd.Equal(tt.expectedBalance.BalanceUsd) {
+				if !successResponse.Balance["USD"].Equal(tt.expectedBalance["USD"]) {
 					t.Fatalf("Ожидался баланс USD %s, но получили: %s",
-						tt.expectedBalance.BalanceUsd.String(), successResponse.Balance.BalanceUsd.String())
+						tt.expectedBalance["USD"].String(), successResponse.Balance["USD"].String())
 				}
-				if !successResponse.Balance.BalanceEur.Equal(tt.expectedBalance.BalanceEur) {
+				if !successResponse.Balance["EUR"].Equal(tt.expectedBalance["EUR"]) {
 					t.Fatalf("Ожидался баланс EUR %s, но получили: %s",
-						tt.expectedBalance.BalanceEur.String(), successResponse.Balance.BalanceEur.String())
+						tt.expectedBalance["EUR"].String(), successResponse.Balance["EUR"].String())
 				}
 
 			} else {
@@ -131,9 +135,247 @@ func TestGetBalance(t *testing.T) {
 	}
 }
 
+func TestGetTransactions(t *testing.T) {
+	router, mockCtrl, mockSvc, validator, handler, cfg := SetupTestEnv(t)
+	defer mockCtrl.Finish()
+
+	jwtManager := newJWTManager(t, cfg)
+	allowTokens(mockSvc)
+	router.GET("/wallet/transactions",
+		middleware.AuthMiddleware(jwtManager, mockSvc),
+		middleware.QueryValidationMiddleware[models.TransactionsQuery](validator),
+		handler.GetTransactions,
+	)
+
+	userID := uuid.Must(uuid.Parse("11ff6680-c604-4231-9453-6e2fbc2c30dc"))
+	token := generateToken(t, jwtManager, userID.String(), "testuser")
+
+	tests := []struct {
+		name              string
+		query             string
+		expectedQuery     models.TransactionsQuery
+		mockResp          models.TransactionsResponse
+		mockServiceErr    error
+		expectedStatus    int
+		expectedMessage   string
+		expectServiceCall bool
+	}{
+		{
+			name:          "Success - First page with filters",
+			query:         "?currency=USD&type=deposit&limit=1",
+			expectedQuery: models.TransactionsQuery{Currency: "USD", Type: "deposit", Limit: 1},
+			mockResp: models.TransactionsResponse{
+				Transactions: []models.Transaction{{
+					ID:           uuid.New(),
+					Type:         models.TransactionDeposit,
+					Currency:     "USD",
+					Amount:       decimal.NewFromFloat(100),
+					BalanceAfter: decimal.NewFromFloat(100),
+				}},
+				NextCursor: "next-page",
+			},
+			expectedStatus:    http.StatusOK,
+			expectServiceCall: true,
+		},
+		{
+			name:              "Error - Unknown operation type",
+			query:             "?type=refund",
+			expectedStatus:    http.StatusBadRequest,
+			expectedMessage:   "Validation failed",
+			expectServiceCall: false,
+		},
+		{
+			name:              "Error - Invalid cursor",
+			query:             "?cursor=broken",
+			expectedQuery:     models.TransactionsQuery{Cursor: "broken"},
+			mockServiceErr:    errs.ErrInvalidCursor,
+			expectedStatus:    http.StatusBadRequest,
+			expectedMessage:   "Invalid pagination cursor",
+			expectServiceCall: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockWalletService := mockSvc.WalletService.(*mocks.MockWalletService)
+
+			if tt.expectServiceCall {
+				mockWalletService.EXPECT().
+					GetTransactions(gomock.Any(), userID, tt.expectedQuery).
+					Return(tt.mockResp, tt.mockServiceErr).Times(1)
+			}
+
+			req, _ := http.NewRequest("GET", "/wallet/transactions"+tt.query, nil)
+			req.Header.Set("Authorization", "Bearer "+token)
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			t.Logf("HTTP статус: %d", w.Code)
+			t.Logf("Ответ сервера: %s", w.Body.String())
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("Ожидался статус %d, но получили: %d", tt.expectedStatus, w.Code)
+			}
+
+			if tt.expectedStatus == http.StatusOK {
+				var successResponse models.TransactionsResponse
+				if err := json.NewDecoder(w.Body).Decode(&successResponse); err != nil {
+					t.Fatalf("Ошибка декодирования успешного ответа: %v. Тело ответа: %s", err, w.Body.String())
+				}
+
+				if len(successResponse.Transactions) != len(tt.mockResp.Transactions) {
+					t.Fatalf("Ожидалось %d операций, но получили: %d", len(tt.mockResp.Transactions), len(successResponse.Transactions))
+				}
+				if successResponse.NextCursor != tt.mockResp.NextCursor {
+					t.Fatalf("Ожидался курсор '%s', но получили: '%s'", tt.mockResp.NextCursor, successResponse.NextCursor)
+				}
+			} else {
+				var errorResponse middleware.ValidationErrorResponse
+				if err := json.NewDecoder(w.Body).Decode(&errorResponse); err != nil {
+					t.Fatalf("Ошибка декодирования ошибки: %v. Тело ответа: %s", err, w.Body.String())
+				}
+
+				if errorResponse.Error.Message != tt.expectedMessage {
+					t.Fatalf("Ожидалось сообщение ошибки '%s', но получили: '%s'", tt.expectedMessage, errorResponse.Error.Message)
+				}
+			}
+
+			t.Logf("✅ Тест '%s' прошел успешно", tt.name)
+		})
+	}
+}
+
+func TestTransfer(t *testing.T) {
+	router, mockCtrl, mockSvc, validator, handler, cfg := SetupTestEnv(t)
+	defer mockCtrl.Finish()
+
+	jwtManager := newJWTManager(t, cfg)
+	allowTokens(mockSvc)
+	router.POST("/wallet/transfer",
+		middleware.AuthMiddleware(jwtManager, mockSvc),
+		middleware.ValidationMiddleware[models.TransferRequest](validator),
+		handler.Transfer,
+	)
+
+	userID := uuid.Must(uuid.Parse("11ff6680-c604-4231-9453-6e2fbc2c30dc"))
+	token := generateToken(t, jwtManager, userID.String(), "testuser")
+	mockSvc.AuthService.(*mocks.MockAuthService).EXPECT().
+		VerifyWithdrawal(gomock.Any(), userID, "USD", gomock.Any(), "").
+		Return(nil).AnyTimes()
+
+	tests := []struct {
+		name              string
+		input             models.TransferRequest
+		mockResp          models.WalletResponse
+		mockServiceErr    error
+		expectedStatus    int
+		expectedMessage   string
+		expectServiceCall bool
+	}{
+		{
+			name:              "Success - Transfer to another user",
+			input:             models.TransferRequest{Recipient: "friend", Currency: "USD", Amount: 50},
+			mockResp:          models.WalletResponse{"USD": decimal.NewFromFloat(100)},
+			expectedStatus:    http.StatusOK,
+			expectedMessage:   "Transfer successful",
+			expectServiceCall: true,
+		},
+		{
+			name:              "Error - Self transfer",
+			input:             models.TransferRequest{Recipient: "testuser", Currency: "USD", Amount: 50},
+			mockServiceErr:    errs.ErrSelfTransfer,
+			expectedStatus:    http.StatusBadRequest,
+			expectedMessage:   "Cannot transfer to yourself",
+			expectServiceCall: true,
+		},
+		{
+			name:              "Error - Recipient not found",
+			input:             models.TransferRequest{Recipient: "nobody@example.com", Currency: "USD", Amount: 50},
+			mockServiceErr:    errs.ErrRecipientNotFound,
+			expectedStatus:    http.StatusNotFound,
+			expectedMessage:   "Recipient not found",
+			expectServiceCall: true,
+		},
+		{
+			name:              "Error - Insufficient funds",
+			input:             models.TransferRequest{Recipient: "friend", Currency: "USD", Amount: 5000},
+			mockServiceErr:    errs.ErrInsufficientFunds,
+			expectedStatus:    http.StatusBadRequest,
+			expectedMessage:   "Insufficient funds",
+			expectServiceCall: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockWalletService := mockSvc.WalletService.(*mocks.MockWalletService)
+
+			if tt.expectServiceCall {
+				mockWalletService.EXPECT().
+					Transfer(gomock.Any(), userID, tt.input.Recipient, tt.input.Currency, gomock.Any()).
+					Return(tt.mockResp, tt.mockServiceErr).Times(1)
+			}
+
+			reqBody, _ := json.Marshal(tt.input)
+			req, _ := http.NewRequest("POST", "/wallet/transfer", bytes.NewReader(reqBody))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("Authorization", "Bearer "+token)
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			t.Logf("HTTP статус: %d", w.Code)
+			t.Logf("Ответ сервера: %s", w.Body.String())
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("Ожидался статус %d, но получили: %d", tt.expectedStatus, w.Code)
+			}
+
+			if tt.expectedStatus == http.StatusOK {
+				var successResponse models.WalletOperationsResponse
+				if err := json.NewDecoder(w.Body).Decode(&successResponse); err != nil {
+					t.Fatalf("Ошибка декодирования успешного ответа: %v. Тело ответа: %s", err, w.Body.String())
+				}
+
+				if successResponse.Message != tt.expectedMessage {
+					t.Fatalf("Ожидалось сообщение '%s', но получили: '%s'", tt.expectedMessage, successResponse.Message)
+				}
+				if !successResponse.Balance["USD"].Equal(tt.mockResp["USD"]) {
+					t.Fatalf("Ожидался баланс USD %s, но получили: %s",
+						tt.mockResp["USD"].String(), successResponse.Balance["USD"].String())
+				}
+			} else {
+				var errorResponse middleware.ValidationErrorResponse
+				if err := json.NewDecoder(w.Body).Decode(&errorResponse); err != nil {
+					t.Fatalf("Ошибка декодирования ошибки: %v. Тело ответа: %s", err, w.Body.String())
+				}
+
+				if errorResponse.Error.Message != tt.expectedMessage {
+					t.Fatalf("Ожидалось сообщение ошибки '%s', но получили: '%s'", tt.expectedMessage, errorResponse.Error.Message)
+				}
+			}
+
+			t.Logf("✅ Тест '%s' прошел успешно", tt.name)
+		})
+	}
+}
+
+func newJWTManager(t *testing.T, cfg *config.Config) *utils.JWTManager {
+	jwtManager, err := utils.NewJWTManager(cfg)
+	if err != nil {
+		t.Fatalf("Ошибка создания JWTManager: %v", err)
+	}
+	return jwtManager
+}
+
 func generateToken(t *testing.T, jwtManager *utils.JWTManager, userID, username string) string {
+	return generateTokenWithRole(t, jwtManager, userID, username, models.RoleUser)
+}
+
+func generateTokenWithRole(t *testing.T, jwtManager *utils.JWTManager, userID, username, role string) string {
 	uuidUserID, _ := uuid.Parse(userID)
-	token, err := jwtManager.GenerateToken(uuidUserID, username)
+	token, err := jwtManager.GenerateToken(uuidUserID, username, role)
 	if err != nil {
 		t.Fatalf("Ошибка генерации токена: %v", err)
 	}
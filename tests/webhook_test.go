@@ -0,0 +1,362 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+
+	config "gw-currency-wallet/internal/config"
+	"gw-currency-wallet/internal/delivery/middleware"
+	"gw-currency-wallet/internal/errs"
+	"gw-currency-wallet/internal/service"
+	"gw-currency-wallet/internal/service/mocks"
+	"gw-currency-wallet/internal/storage"
+	"gw-currency-wallet/internal/storage/models"
+	"gw-currency-wallet/internal/webhook"
+)
+
+func TestWebhookAPI(t *testing.T) {
+	router, mockCtrl, mockSvc, validator, handler, cfg := SetupTestEnv(t)
+	defer mockCtrl.Finish()
+
+	jwtManager := newJWTManager(t, cfg)
+	allowTokens(mockSvc)
+	mockWebhookService := mockSvc.WebhookService.(*mocks.MockWebhookService)
+
+	// Маршруты повторяют группу /webhooks из InitRoutes
+	webhooks := router.Group("/webhooks", middleware.AuthMiddleware(jwtManager, mockSvc))
+	webhooks.POST("", middleware.ValidationMiddleware[models.WebhookRequest](validator), handler.WebhookHandler.CreateWebhook)
+	webhooks.DELETE("/:id", handler.WebhookHandler.DeleteWebhook)
+	webhooks.POST("/:id/deliveries/:delivery_id/replay", handler.WebhookHandler.ReplayDelivery)
+
+	userID := uuid.MustParse("11ff6680-c604-4231-9453-6e2fbc2c30dc")
+	webhookID := uuid.New()
+	deliveryID := uuid.New()
+	request := models.WebhookRequest{
+		URL:        "https://partner.example.com/hooks",
+		EventTypes: []string{models.EventWalletDeposited, models.EventWalletExchanged},
+	}
+
+	tests := []struct {
+		name            string
+		method          string
+		path            string
+		body            interface{}
+		setupMock       func()
+		expectedStatus  int
+		expectedMessage string
+	}{
+		{
+			name:   "Success - Create webhook",
+			method: "POST",
+			path:   "/webhooks",
+			body:   request,
+			setupMock: func() {
+				mockWebhookService.EXPECT().CreateWebhook(gomock.Any(), userID, request).
+					Return(models.WebhookSubscription{ID: webhookID, URL: request.URL, EventTypes: request.EventTypes, Secret: "whsec_test"}, nil).
+					Times(1)
+			},
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name:            "Error - Unknown event type",
+			method:          "POST",
+			path:            "/webhooks",
+			body:            models.WebhookRequest{URL: request.URL, EventTypes: []string{"user.deleted"}},
+			expectedStatus:  http.StatusBadRequest,
+			expectedMessage: "Validation failed",
+		},
+		{
+			name:            "Error - Invalid URL",
+			method:          "POST",
+			path:            "/webhooks",
+			body:            models.WebhookRequest{URL: "ftp://partner", EventTypes: request.EventTypes},
+			expectedStatus:  http.StatusBadRequest,
+			expectedMessage: "Validation failed",
+		},
+		{
+			name:   "Error - Delete foreign webhook",
+			method: "DELETE",
+			path:   "/webhooks/" + webhookID.String(),
+			setupMock: func() {
+				mockWebhookService.EXPECT().DeleteWebhook(gomock.Any(), userID, webhookID).Return(errs.ErrWebhookNotFound).Times(1)
+			},
+			expectedStatus:  http.StatusNotFound,
+			expectedMessage: "Webhook not found",
+		},
+		{
+			name:   "Success - Replay dead delivery",
+			method: "POST",
+			path:   "/webhooks/" + webhookID.String() + "/deliveries/" + deliveryID.String() + "/replay",
+			setupMock: func() {
+				mockWebhookService.EXPECT().ReplayDelivery(gomock.Any(), userID, webhookID, deliveryID).
+					Return(models.WebhookDelivery{ID: deliveryID, Status: models.WebhookDeliveryPending}, nil).Times(1)
+			},
+			expectedStatus: http.StatusAccepted,
+		},
+		{
+			name:            "Error - Invalid webhook id",
+			method:          "POST",
+			path:            "/webhooks/not-a-uuid/deliveries/" + deliveryID.String() + "/replay",
+			expectedStatus:  http.StatusBadRequest,
+			expectedMessage: "Invalid webhook ID",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.setupMock != nil {
+				tt.setupMock()
+			}
+
+			var body bytes.Buffer
+			if tt.body != nil {
+				json.NewEncoder(&body).Encode(tt.body)
+			}
+			req, _ := http.NewRequest(tt.method, tt.path, &body)
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("Authorization", "Bearer "+generateToken(t, jwtManager, userID.String(), "testuser"))
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			t.Logf("HTTP статус: %d", w.Code)
+			t.Logf("Ответ сервера: %s", w.Body.String())
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("Ожидался статус %d, но получили: %d", tt.expectedStatus, w.Code)
+			}
+
+			if tt.expectedMessage != "" {
+				var errorResponse middleware.ValidationErrorResponse
+				if err := json.NewDecoder(w.Body).Decode(&errorResponse); err != nil {
+					t.Fatalf("Ошибка декодирования ответа с ошибкой: %v. Тело ответа: %s", err, w.Body.String())
+				}
+
+				if errorResponse.Error.Message != tt.expectedMessage {
+					t.Fatalf("Ожидалось сообщение ошибки '%s', но получили: '%s'", tt.expectedMessage, errorResponse.Error.Message)
+				}
+			}
+
+			t.Logf("✅ Тест '%s' прошел успешно", tt.name)
+		})
+	}
+}
+
+// fakeWebhookStorage очередь доставок в памяти для проверки Dispatcher
+type fakeWebhookStorage struct {
+	deliveries map[uuid.UUID]*models.WebhookDelivery
+}
+
+func (f *fakeWebhookStorage) CreateWebhook(context.Context, models.WebhookSubscription) (models.WebhookSubscription, error) {
+	return models.WebhookSubscription{}, nil
+}
+
+func (f *fakeWebhookStorage) GetWebhooks(context.Context, uuid.UUID) ([]models.WebhookSubscription, error) {
+	return nil, nil
+}
+
+func (f *fakeWebhookStorage) DeleteWebhook(context.Context, uuid.UUID, uuid.UUID) error {
+	return nil
+}
+
+func (f *fakeWebhookStorage) ClaimWebhookDeliveries(_ context.Context, limit int, _ time.Duration) ([]models.WebhookDelivery, error) {
+	var due []models.WebhookDelivery
+	for _, d := range f.deliveries {
+		if d.Status == models.WebhookDeliveryPending && len(due) < limit {
+			due = append(due, *d)
+		}
+	}
+	return due, nil
+}
+
+func (f *fakeWebhookStorage) CompleteWebhookDelivery(_ context.Context, id uuid.UUID, statusCode int) error {
+	d := f.deliveries[id]
+	d.Status = models.WebhookDeliveryDelivered
+	d.Attempts++
+	d.LastStatusCode = &statusCode
+	return nil
+}
+
+func (f *fakeWebhookStorage) FailWebhookDelivery(_ context.Context, id uuid.UUID, statusCode *int, reason string, nextAttemptAt *time.Time) error {
+	d := f.deliveries[id]
+	d.Attempts++
+	d.LastStatusCode = statusCode
+	d.LastError = &reason
+	if nextAttemptAt == nil {
+		d.Status = models.WebhookDeliveryDead
+	} else {
+		d.NextAttemptAt = *nextAttemptAt
+	}
+	return nil
+}
+
+func (f *fakeWebhookStorage) GetWebhookDeliveries(context.Context, uuid.UUID, uuid.UUID, string, int) ([]models.WebhookDelivery, error) {
+	return nil, nil
+}
+
+func (f *fakeWebhookStorage) ReplayWebhookDelivery(context.Context, uuid.UUID, uuid.UUID, uuid.UUID) (models.WebhookDelivery, error) {
+	return models.WebhookDelivery{}, nil
+}
+
+func TestWebhookDispatcher(t *testing.T) {
+	// Тестовый сервер слушает loopback
+	cfg := &config.WebhookConfig{Timeout: time.Second, BatchSize: 10, MaxAttempts: 3, BaseBackoff: time.Second, MaxBackoff: time.Minute, AllowPrivateNetworks: true}
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel)
+
+	const secret = "whsec_test"
+	payload := []byte(`{"event_id":"e1","event_type":"wallet.deposited","data":{}}`)
+
+	newDelivery := func(url string, attempts int) *models.WebhookDelivery {
+		return &models.WebhookDelivery{
+			ID:        uuid.New(),
+			EventType: models.EventWalletDeposited,
+			Payload:   payload,
+			Status:    models.WebhookDeliveryPending,
+			Attempts:  attempts,
+			URL:       url,
+			Secret:    secret,
+		}
+	}
+
+	t.Run("Signed delivery", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, _ := io.ReadAll(r.Body)
+			timestamp, _ := strconv.ParseInt(r.Header.Get(webhook.HeaderTimestamp), 10, 64)
+			if r.Header.Get(webhook.HeaderSignature) != webhook.Sign(secret, timestamp, body) {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			if r.Header.Get(webhook.HeaderEvent) != models.EventWalletDeposited || !bytes.Equal(body, payload) {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			w.WriteHeader(http.StatusNoContent)
+		}))
+		defer server.Close()
+
+		delivery := newDelivery(server.URL, 0)
+		stor := &fakeWebhookStorage{deliveries: map[uuid.UUID]*models.WebhookDelivery{delivery.ID: delivery}}
+
+		if _, err := webhook.NewDispatcher(stor, cfg, logger).Flush(context.Background()); err != nil {
+			t.Fatalf("Не ожидалась ошибка: %v", err)
+		}
+		if delivery.Status != models.WebhookDeliveryDelivered {
+			t.Fatalf("Ожидался статус delivered, получили: %s (%v)", delivery.Status, delivery.LastError)
+		}
+
+		t.Log("✅ Доставка подписана и принята")
+	})
+
+	t.Run("Retry with backoff and dead-letter", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}))
+		defer server.Close()
+
+		delivery := newDelivery(server.URL, 0)
+		stor := &fakeWebhookStorage{deliveries: map[uuid.UUID]*models.WebhookDelivery{delivery.ID: delivery}}
+		dispatcher := webhook.NewDispatcher(stor, cfg, logger)
+
+		before := time.Now()
+		if _, err := dispatcher.Flush(context.Background()); err != nil {
+			t.Fatalf("Не ожидалась ошибка: %v", err)
+		}
+		if delivery.Status != models.WebhookDeliveryPending || delivery.Attempts != 1 {
+			t.Fatalf("Ожидался повтор, получили статус %s, попыток %d", delivery.Status, delivery.Attempts)
+		}
+		if delivery.LastStatusCode == nil || *delivery.LastStatusCode != http.StatusServiceUnavailable {
+			t.Fatal("Ожидался сохранённый код ответа 503")
+		}
+		if delivery.NextAttemptAt.Before(before.Add(cfg.BaseBackoff)) {
+			t.Fatalf("Повтор назначен слишком рано: %s", delivery.NextAttemptAt)
+		}
+
+		// Исчерпываем попытки
+		for i := 0; i < cfg.MaxAttempts-1; i++ {
+			dispatcher.Flush(context.Background())
+		}
+		if delivery.Status != models.WebhookDeliveryDead || delivery.Attempts != cfg.MaxAttempts {
+			t.Fatalf("Ожидался статус dead после %d попыток, получили %s после %d", cfg.MaxAttempts, delivery.Status, delivery.Attempts)
+		}
+
+		t.Log("✅ Доставка переведена в dead-letter")
+	})
+}
+
+func TestWebhookPrivateNetworks(t *testing.T) {
+	ctx := context.Background()
+	for _, url := range []string{
+		"http://localhost/hooks",
+		"http://127.0.0.1:8080/hooks",
+		"http://10.0.0.5/hooks",
+		"http://192.168.1.1/hooks",
+		"http://169.254.169.254/latest/meta-data",
+		"http://[::1]/hooks",
+		"http://0.0.0.0/hooks",
+	} {
+		if err := webhook.CheckURL(ctx, url); !errors.Is(err, errs.ErrWebhookURLNotAllowed) {
+			t.Fatalf("Адрес %s должен отклоняться, но получили: %v", url, err)
+		}
+	}
+	if err := webhook.CheckURL(ctx, "https://93.184.216.34/hooks"); err != nil {
+		t.Fatalf("Публичный адрес должен приниматься: %v", err)
+	}
+
+	// Подписка на внутренний адрес не создаётся
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel)
+	svc := service.NewWebhookService(&storage.Storage{}, logger, &config.WebhookConfig{})
+	_, err := svc.CreateWebhook(ctx, uuid.New(), models.WebhookRequest{URL: "http://127.0.0.1/hooks", EventTypes: []string{models.EventWalletDeposited}})
+	if !errors.Is(err, errs.ErrWebhookURLNotAllowed) {
+		t.Fatalf("Ожидалась ошибка %v, но получили: %v", errs.ErrWebhookURLNotAllowed, err)
+	}
+
+	// Доставка проверяет адрес при подключении: DNS подписки мог смениться после регистрации
+	var called bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	delivery := &models.WebhookDelivery{
+		ID:        uuid.New(),
+		EventType: models.EventWalletDeposited,
+		Payload:   []byte(`{}`),
+		Status:    models.WebhookDeliveryPending,
+		URL:       server.URL,
+		Secret:    "whsec_test",
+	}
+	stor := &fakeWebhookStorage{deliveries: map[uuid.UUID]*models.WebhookDelivery{delivery.ID: delivery}}
+	cfg := &config.WebhookConfig{Timeout: time.Second, BatchSize: 10, MaxAttempts: 3, BaseBackoff: time.Second, MaxBackoff: time.Minute}
+	if _, err := webhook.NewDispatcher(stor, cfg, logger).Flush(ctx); err != nil {
+		t.Fatalf("Не ожидалась ошибка: %v", err)
+	}
+	if called || delivery.Status == models.WebhookDeliveryDelivered || delivery.LastError == nil {
+		t.Fatalf("Доставка на loopback должна отклоняться, получили статус %s", delivery.Status)
+	}
+}
+
+func TestWebhookBackoff(t *testing.T) {
+	base, max := 10*time.Second, time.Minute
+	expected := []time.Duration{10 * time.Second, 20 * time.Second, 40 * time.Second, time.Minute, time.Minute}
+
+	for i, want := range expected {
+		if got := webhook.Backoff(i+1, base, max); got != want {
+			t.Fatalf("Попытка %d: ожидалась пауза %s, получили %s", i+1, want, got)
+		}
+	}
+}